@@ -2,18 +2,128 @@ package main
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"html/template"
+	"net/url"
+	"regexp"
 	"slices"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/sivukhin/gobughunt/lib"
+	"github.com/sivukhin/gobughunt/lib/utils"
 	"github.com/sivukhin/gobughunt/storage"
 	"github.com/sivukhin/gobughunt/storage/db"
 )
 
+var (
+	ErrAccessDenied    = errors.New("access denied")
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+func invalidArgumentf(format string, args ...any) error {
+	return fmt.Errorf("%w: %v", ErrInvalidArgument, fmt.Sprintf(format, args...))
+}
+
 type ApiController struct {
-	Storage         *db.Queries
-	ModeratorLogins []string
+	Storage storage.Storage
+	Quorum  int // number of agreeing moderators required to settle the verdict
+}
+
+// authorize returns login of the current user if one of the roles was granted to the user (admin is allowed to do anything)
+func (c ApiController) authorize(ctx context.Context, roles ...db.AccessRole) (string, error) {
+	user, granted, err := c.userRoles(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, role := range granted {
+		if role == db.AccessRoleAdmin || slices.Contains(roles, role) {
+			return user, nil
+		}
+	}
+	return "", ErrAccessDenied
+}
+
+// reviewAccess tells whose unmoderated results (pending highlights, raw logs and errors of the lint attempts) the user can see:
+// moderators see results of all linters, while linter owners - only of their own ones
+type reviewAccess struct {
+	all   bool
+	owner string
+}
+
+func (a reviewAccess) allows(linterOwner pgtype.Text) bool {
+	return a.all || a.owner != "" && linterOwner.Valid && linterOwner.String == a.owner
+}
+
+func (c ApiController) reviewAccess(ctx context.Context) (reviewAccess, error) {
+	user, granted, err := c.userRoles(ctx)
+	if err != nil {
+		return reviewAccess{}, err
+	}
+	if len(granted) == 0 {
+		return reviewAccess{}, ErrAccessDenied
+	}
+	access := reviewAccess{all: slices.Contains(granted, db.AccessRoleAdmin) || slices.Contains(granted, db.AccessRoleModerator)}
+	if slices.Contains(granted, db.AccessRoleLinterOwner) {
+		access.owner = user
+	}
+	return access, nil
+}
+
+// allowScope checks that API token used for the request has the scope - requests authenticated by session cookie are not limited
+func allowScope(ctx context.Context, scope db.TokenScope) error {
+	tokenScope, ok := ctx.Value("scope").(db.TokenScope)
+	if !ok || tokenScope == scope {
+		return nil
+	}
+	return fmt.Errorf("%w: API token with %v scope can't be used for %v", ErrAccessDenied, tokenScope, scope)
+}
+
+// requireSession forbids the action for API tokens: tokens must not be able to escalate privileges or mint new tokens
+func requireSession(ctx context.Context) error {
+	if _, ok := ctx.Value("scope").(db.TokenScope); ok {
+		return fmt.Errorf("%w: action is not available for API tokens", ErrAccessDenied)
+	}
+	return nil
+}
+
+// userName returns display name of the current user - API tokens and old sessions carry only the login
+func userName(ctx context.Context) string {
+	if name, _ := ctx.Value("name").(string); name != "" {
+		return name
+	}
+	user, _ := ctx.Value("user").(string)
+	return user
+}
+
+// csrfToken returns CSRF token of the session which must be sent with every state-changing request
+func csrfToken(ctx context.Context) string {
+	token, _ := ctx.Value("csrf").(string)
+	return token
+}
+
+func (c ApiController) userRoles(ctx context.Context) (string, []db.AccessRole, error) {
+	user, _ := ctx.Value("user").(string)
+	if user == "" {
+		return "", nil, ErrAccessDenied
+	}
+	granted, err := c.Storage.ListUserRoles(ctx, user)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get roles of the user: %w", err)
+	}
+	return user, granted, nil
 }
 
 func RenderTemplate(t *template.Template, data any) (string, error) {
@@ -25,35 +135,227 @@ func RenderTemplate(t *template.Template, data any) (string, error) {
 	return buffer.String(), nil
 }
 
-func (c ApiController) LintHighlightModerate(ctx context.Context, lintId string, path string, startLine, endLine int, status string) error {
-	user, _ := ctx.Value("user").(string)
-	if !slices.Contains(c.ModeratorLogins, user) {
-		return fmt.Errorf("access denied")
+// LintHighlightModerate records the vote of the moderator and returns the resulting status of the highlight
+func (c ApiController) LintHighlightModerate(ctx context.Context, lintId, fingerprint, vote, comment string) (string, error) {
+	if err := allowScope(ctx, db.TokenScopeModerate); err != nil {
+		return "", err
 	}
-	arg := db.ModerateBugHuntHighlightParams{
-		LintID:           lintId,
-		Path:             path,
-		StartLine:        int32(startLine),
-		EndLine:          int32(endLine),
-		ModerationStatus: db.HighlightStatus(status),
+	user, err := c.authorize(ctx, db.AccessRoleModerator)
+	if err != nil {
+		return "", err
 	}
-	return c.Storage.ModerateBugHuntHighlight(ctx, arg)
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	var status db.HighlightStatus
+	err = c.Storage.InTx(ctx, func(queries storage.Queries) error {
+		// lock the highlight in order to serialize concurrent votes
+		_, err := queries.GetModerationStatus(ctx, db.GetModerationStatusParams{LintID: lintId, Fingerprint: fingerprint})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("highlight %w: lint=%v, fingerprint=%v", ErrNotFound, lintId, fingerprint)
+		} else if err != nil {
+			return err
+		}
+		_, err = queries.UpsertModerationVote(ctx, db.UpsertModerationVoteParams{
+			LintID:         lintId,
+			Fingerprint:    fingerprint,
+			ModeratorLogin: user,
+			Vote:           db.HighlightStatus(vote),
+			Comment:        comment,
+			VotedAt:        now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save vote: %w", err)
+		}
+		status, err = c.consensus(ctx, queries, lintId, fingerprint)
+		if err != nil {
+			return err
+		}
+		return moderate(ctx, queries, db.AddModerationEventParams{
+			LintID:           lintId,
+			Fingerprint:      fingerprint,
+			ModerationStatus: status,
+			Vote:             db.NullHighlightStatus{HighlightStatus: db.HighlightStatus(vote), Valid: true},
+			ModeratorLogin:   user,
+			Comment:          comment,
+			CreatedAt:        now,
+		})
+	})
+	return string(status), err
 }
 
-func (c ApiController) LintHighlights(ctx context.Context, lintId, repoId, linterId string) (LintHighlightsDto, error) {
-	user, _ := ctx.Value("user").(string)
-	if !slices.Contains(c.ModeratorLogins, user) {
-		return LintHighlightsDto{}, fmt.Errorf("access denied")
+// LintHighlightRevert withdraws the vote which was cast in the moderation event and returns the new status of the highlight.
+// Only the latest decision can be reverted: if status was changed since then, revert will fail.
+// Moderators can withdraw only their own votes, while admin can withdraw vote of anyone
+func (c ApiController) LintHighlightRevert(ctx context.Context, eventId int64, comment string) (string, error) {
+	if err := allowScope(ctx, db.TokenScopeModerate); err != nil {
+		return "", err
+	}
+	user, roles, err := c.userRoles(ctx)
+	if err != nil {
+		return "", err
+	}
+	admin := slices.Contains(roles, db.AccessRoleAdmin)
+	if !admin && !slices.Contains(roles, db.AccessRoleModerator) {
+		return "", ErrAccessDenied
+	}
+	var status db.HighlightStatus
+	err = c.Storage.InTx(ctx, func(queries storage.Queries) error {
+		event, err := queries.GetModerationEvent(ctx, eventId)
+		if err != nil {
+			return fmt.Errorf("unable to find moderation event %v: %w", eventId, err)
+		}
+		if !event.Vote.Valid || event.RevertedEventID.Valid {
+			return invalidArgumentf("moderation event %v has no vote to withdraw", eventId)
+		}
+		if event.ModeratorLogin != user && !admin {
+			return fmt.Errorf("%w: only admin can withdraw vote of other moderator", ErrAccessDenied)
+		}
+		current, err := queries.GetModerationStatus(ctx, db.GetModerationStatusParams{LintID: event.LintID, Fingerprint: event.Fingerprint})
+		if err != nil {
+			return fmt.Errorf("unable to find highlight of moderation event %v: %w", eventId, err)
+		}
+		if current != event.ModerationStatus {
+			return fmt.Errorf("%w: moderation event %v was superseded, highlight status is %v now", ErrConflict, eventId, current)
+		}
+		err = queries.DeleteModerationVote(ctx, db.DeleteModerationVoteParams{
+			LintID:         event.LintID,
+			Fingerprint:    event.Fingerprint,
+			ModeratorLogin: event.ModeratorLogin,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to withdraw vote: %w", err)
+		}
+		status, err = c.consensus(ctx, queries, event.LintID, event.Fingerprint)
+		if err != nil {
+			return err
+		}
+		return moderate(ctx, queries, db.AddModerationEventParams{
+			LintID:           event.LintID,
+			Fingerprint:      event.Fingerprint,
+			ModerationStatus: status,
+			ModeratorLogin:   user,
+			Comment:          comment,
+			RevertedEventID:  pgtype.Int8{Int64: event.EventID, Valid: true},
+			CreatedAt:        pgtype.Timestamp{Time: time.Now(), Valid: true},
+		})
+	})
+	return string(status), err
+}
+
+func (c ApiController) consensus(ctx context.Context, queries storage.Queries, lintId, fingerprint string) (db.HighlightStatus, error) {
+	votes, err := queries.CountModerationVotes(ctx, db.CountModerationVotesParams{LintID: lintId, Fingerprint: fingerprint})
+	if err != nil {
+		return "", fmt.Errorf("failed to count votes: %w", err)
+	}
+	return Consensus(votes.AcceptedVotes, votes.RejectedVotes, c.Quorum), nil
+}
+
+// Consensus settles the verdict only when quorum of moderators agreed and nobody objected - otherwise highlight is disputed
+func Consensus(acceptedVotes, rejectedVotes int64, quorum int) db.HighlightStatus {
+	quorum = max(quorum, 1)
+	switch {
+	case acceptedVotes > 0 && rejectedVotes > 0:
+		return db.HighlightStatusDisputed
+	case acceptedVotes >= int64(quorum):
+		return db.HighlightStatusAccepted
+	case rejectedVotes >= int64(quorum):
+		return db.HighlightStatusRejected
+	default:
+		return db.HighlightStatusPending
+	}
+}
+
+// moderate appends event to the audit trail and only then changes the highlight status - so event captures the previous one
+func moderate(ctx context.Context, queries storage.Queries, event db.AddModerationEventParams) error {
+	_, err := queries.AddModerationEvent(ctx, event)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("highlight %w: lint=%v, fingerprint=%v", ErrNotFound, event.LintID, event.Fingerprint)
+	} else if err != nil {
+		return fmt.Errorf("failed to add moderation event: %w", err)
+	}
+	return queries.ModerateBugHuntHighlight(ctx, db.ModerateBugHuntHighlightParams{
+		LintID:            event.LintID,
+		Fingerprint:       event.Fingerprint,
+		ModerationStatus:  event.ModerationStatus,
+		ModerationComment: pgtype.Text{String: event.Comment, Valid: event.Comment != ""},
+		ModeratedAt:       event.CreatedAt,
+		ModeratedBy:       pgtype.Text{String: event.ModeratorLogin, Valid: true},
+	})
+}
+
+// moderationKey identifies the highlight across all lints of the same repo and linter
+func moderationKey(repoId, linterId, lintId, fingerprint, path string, startLine, endLine int32) string {
+	if fingerprint != "" {
+		return fmt.Sprintf("%v/%v/%v", repoId, linterId, fingerprint)
+	}
+	return fmt.Sprintf("%v/%v:%v-%v", lintId, path, startLine, endLine)
+}
+
+// LintHighlights lists deduplicated highlights - pending ones are visible only to those who can review them (see reviewAccess)
+func (c ApiController) LintHighlights(ctx context.Context, lintId, repoId, linterId, ruleId, severity, status string, after pageCursor, limit int) (LintHighlightsDto, error) {
+	access, err := c.reviewAccess(ctx)
+	if err != nil {
+		return LintHighlightsDto{}, err
+	}
+	args := db.ListBugHuntHighlightsParams{
+		LintID:           lintId,
+		RepoID:           repoId,
+		LinterID:         linterId,
+		RuleID:           ruleId,
+		Severity:         severity,
+		ModerationStatus: status,
+		AllPending:       access.all,
+		PendingOwner:     access.owner,
+		AfterLintID:      after.Id,
+		AfterCreatedAt:   after.createdAt(),
+		AfterPath:        after.Path,
+		AfterStartLine:   after.StartLine,
+		AfterEndLine:     after.EndLine,
+		AfterFingerprint: after.Fingerprint,
+		PageLimit:        int32(limit + 1),
 	}
-	args := db.ListBugHuntHighlightsParams{LintID: lintId, RepoID: repoId, LinterID: linterId}
 	highlights, err := c.Storage.ListBugHuntHighlights(ctx, args)
 	if err != nil {
 		return LintHighlightsDto{}, err
 	}
+	highlights, nextCursor := cutPage(highlights, limit, func(highlight db.ListBugHuntHighlightsRow) pageCursor {
+		return pageCursor{
+			CreatedAt:   highlight.CreatedAt.Time,
+			Id:          highlight.LintID,
+			Path:        highlight.Path,
+			StartLine:   highlight.StartLine,
+			EndLine:     highlight.EndLine,
+			Fingerprint: highlight.Fingerprint,
+		}
+	})
+	// history is loaded only for the highlights of the page
+	eventsArgs := db.ListModerationEventsParams{}
+	for _, highlight := range highlights {
+		eventsArgs.Fingerprints = append(eventsArgs.Fingerprints, highlight.Fingerprint)
+		eventsArgs.LintIds = append(eventsArgs.LintIds, highlight.LintID)
+	}
+	events, err := c.Storage.ListModerationEvents(ctx, eventsArgs)
+	if err != nil {
+		return LintHighlightsDto{}, err
+	}
+	history := make(map[string][]ModerationEventDto)
+	for _, event := range events {
+		key := moderationKey(event.RepoID, event.LinterID, event.LintID, event.Fingerprint, event.Path, event.StartLine, event.EndLine)
+		history[key] = append(history[key], ModerationEventDto{
+			Id:              event.EventID,
+			Moderator:       event.ModeratorLogin,
+			PreviousStatus:  string(event.PreviousStatus),
+			Status:          string(event.ModerationStatus),
+			Vote:            string(event.Vote.HighlightStatus),
+			Comment:         event.Comment,
+			RevertedEventId: storage.TryGetInt8(event.RevertedEventID),
+			CreatedAt:       event.CreatedAt.Time.Format(time.DateTime),
+		})
+	}
 	dtoHighlights := make([]LintHighlightDto, 0, len(highlights))
 	for _, highlight := range highlights {
 		dtoHighlights = append(dtoHighlights, LintHighlightDto{
-			LintId: highlight.LintID,
+			LintId:      highlight.LintID,
+			Fingerprint: highlight.Fingerprint,
 			Linter: LinterDto{
 				Id:                 highlight.LinterID,
 				GitUrl:             highlight.LinterGitUrl,
@@ -71,39 +373,123 @@ func (c ApiController) LintHighlights(ctx context.Context, lintId, repoId, linte
 			Path:        highlight.Path,
 			StartLine:   int(highlight.StartLine),
 			EndLine:     int(highlight.EndLine),
+			StartColumn: int(highlight.StartColumn),
+			EndColumn:   int(highlight.EndColumn),
+			Severity:    string(highlight.Severity),
+			RuleId:      highlight.RuleID,
 			Explanation: highlight.Explanation,
 			Snippet: HighlightSnippetDto{
 				StartLine: int(highlight.SnippetStartLine),
 				EndLine:   int(highlight.SnippetEndLine),
 				Code:      highlight.SnippetCode,
 			},
+			FirstSeenCommitHash: storage.TryGetText(highlight.FirstSeenCommitHash),
+			LastSeenCommitHash:  storage.TryGetText(highlight.LastSeenCommitHash),
+			FixedCommitHash:     storage.TryGetText(highlight.FixedCommitHash),
+			History: history[moderationKey(
+				highlight.RepoID,
+				highlight.LinterID,
+				highlight.LintID,
+				highlight.Fingerprint,
+				highlight.Path,
+				highlight.StartLine,
+				highlight.EndLine,
+			)],
 		})
 	}
-	return LintHighlightsDto{Login: user, LintId: lintId, RepoId: repoId, LinterId: linterId, Highlights: dtoHighlights}, nil
+	return LintHighlightsDto{
+		Login:      userName(ctx),
+		CsrfToken:  csrfToken(ctx),
+		LintId:     lintId,
+		RepoId:     repoId,
+		LinterId:   linterId,
+		RuleId:     ruleId,
+		Severity:   severity,
+		Status:     status,
+		Highlights: dtoHighlights,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// sortForReview puts highlights waiting for the moderators first and groups them by location
+func sortForReview(highlights []LintHighlightDto) {
+	order := []db.HighlightStatus{db.HighlightStatusPending, db.HighlightStatusDisputed, db.HighlightStatusAccepted, db.HighlightStatusRejected}
+	slices.SortStableFunc(highlights, func(a, b LintHighlightDto) int {
+		if result := cmp.Compare(slices.Index(order, db.HighlightStatus(a.Status)), slices.Index(order, db.HighlightStatus(b.Status))); result != 0 {
+			return result
+		}
+		if result := cmp.Compare(a.Repo.Id, b.Repo.Id); result != 0 {
+			return result
+		}
+		if result := cmp.Compare(a.Path, b.Path); result != 0 {
+			return result
+		}
+		return cmp.Compare(a.StartLine, b.StartLine)
+	})
 }
 
 type LintTasksDto struct {
-	Login string
-	Tasks []LintTaskDto
+	Login      string
+	LinterId   string
+	Tasks      []LintTaskDto
+	NextCursor string
 }
 
-func (c ApiController) LintTasks(ctx context.Context, skip, take int) (LintTasksDto, error) {
-	user, _ := ctx.Value("user").(string)
-	if !slices.Contains(c.ModeratorLogins, user) {
-		return LintTasksDto{}, fmt.Errorf("access denied")
+// LintTasks lists tasks from the newest to the oldest one: cursor points to the last task of the previous page.
+// Attempts and logs are shown only for the tasks which user can review (see reviewAccess)
+func (c ApiController) LintTasks(ctx context.Context, linterId string, before pageCursor, limit int) (LintTasksDto, error) {
+	access, err := c.reviewAccess(ctx)
+	if err != nil {
+		return LintTasksDto{}, err
+	}
+	args := db.ListBugHuntLintTasksParams{
+		LinterID:        linterId,
+		BeforeLintID:    before.Id,
+		BeforeCreatedAt: before.createdAt(),
+		PageLimit:       int32(limit + 1),
 	}
-	args := db.ListBugHuntLintTasksParams{Offset: int32(skip), Limit: int32(take)}
 	tasks, err := c.Storage.ListBugHuntLintTasks(ctx, args)
 	if err != nil {
 		return LintTasksDto{}, err
 	}
+	tasks, nextCursor := cutPage(tasks, limit, func(task db.ListBugHuntLintTasksRow) pageCursor {
+		return pageCursor{CreatedAt: task.CreatedAt.Time, Id: task.LintID}
+	})
+	lintIds := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		if access.allows(task.LinterOwner) {
+			lintIds = append(lintIds, task.LintID)
+		}
+	}
+	attempts, err := c.Storage.ListLintAttempts(ctx, lintIds)
+	if err != nil {
+		return LintTasksDto{}, err
+	}
+	history := make(map[string][]LintAttemptDto)
+	for _, attempt := range attempts {
+		dtoAttempt := LintAttemptDto{
+			Attempt:   int(attempt.Attempt),
+			Worker:    attempt.WorkerID,
+			Status:    string(attempt.LintStatus.LintStatus),
+			StartedAt: attempt.StartedAt.Time.Format(time.DateTime),
+			ExitCode:  storage.TryGetInt4(attempt.ExitCode),
+			Error:     storage.TryGetText(attempt.Error),
+		}
+		if attempt.FinishedAt.Valid {
+			dtoAttempt.FinishedAt = attempt.FinishedAt.Time.Format(time.DateTime)
+		}
+		history[attempt.LintID] = append(history[attempt.LintID], dtoAttempt)
+	}
 	dtoTasks := make([]LintTaskDto, 0, len(tasks))
 	for _, task := range tasks {
-		dtoTasks = append(dtoTasks, LintTaskDto{
+		dtoTask := LintTaskDto{
 			Id:              task.LintID,
 			Status:          string(task.LintStatus),
 			StatusComment:   storage.TryGetText(task.LintStatusComment),
 			LintDurationSec: storage.TryGetDurationSec(task.LintDuration),
+			Attempts:        int(task.LintAttempts),
+			HasLogs:         task.HasLogs && access.allows(task.LinterOwner),
+			History:         history[task.LintID],
 			Linter: LinterDto{
 				Id:                 task.LinterID,
 				GitUrl:             task.LinterGitUrl,
@@ -117,16 +503,216 @@ func (c ApiController) LintTasks(ctx context.Context, skip, take int) (LintTasks
 				GitBranch:     task.RepoGitBranch,
 				GitCommitHash: &task.RepoGitCommitHash,
 			},
-		})
+		}
+		if task.NextAttemptAt.Valid {
+			dtoTask.NextAttemptAt = task.NextAttemptAt.Time.Format(time.DateTime)
+		}
+		if task.CpuSeconds.Valid {
+			dtoTask.Usage = &TaskUsageDto{
+				CpuSeconds:  task.CpuSeconds.Float64,
+				MaxRssBytes: task.MaxRssBytes.Int64,
+				IoBytes:     task.IoBytes.Int64,
+				MaxPids:     task.MaxPids.Int64,
+			}
+		}
+		dtoTasks = append(dtoTasks, dtoTask)
+	}
+	return LintTasksDto{Login: userName(ctx), LinterId: linterId, Tasks: dtoTasks, NextCursor: nextCursor}, nil
+}
+
+// sortByStatus groups tasks by their status keeping the order of the tasks within the group
+func sortByStatus(tasks []LintTaskDto) {
+	order := []db.LintStatus{
+		db.LintStatusPending,
+		db.LintStatusRunning,
+		db.LintStatusSucceeded,
+		db.LintStatusFailed,
+		db.LintStatusSkipped,
+		db.LintStatusTimedOut,
+		db.LintStatusCancelled,
+		db.LintStatusOomKilled,
+		db.LintStatusCrashed,
+		db.LintStatusTooNoisy,
 	}
-	return LintTasksDto{Login: user, Tasks: dtoTasks}, nil
+	slices.SortStableFunc(tasks, func(a, b LintTaskDto) int {
+		return cmp.Compare(slices.Index(order, db.LintStatus(a.Status)), slices.Index(order, db.LintStatus(b.Status)))
+	})
+}
+
+type LintLogsDto struct {
+	Login     string
+	LintId    string
+	Attempt   int
+	CreatedAt string
+	Stdout    LintLogStreamDto
+	Stderr    LintLogStreamDto
+}
+
+type LintLogStreamDto struct {
+	Content    string
+	TotalBytes int64
+	Truncated  bool // only the head of the stream was stored by the worker
+}
+
+// LintLogs returns raw output of the last attempt of the task which produced any output
+func (c ApiController) LintLogs(ctx context.Context, lintId string) (LintLogsDto, error) {
+	access, err := c.reviewAccess(ctx)
+	if err != nil {
+		return LintLogsDto{}, err
+	}
+	logs, err := c.Storage.GetLintLogs(ctx, lintId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return LintLogsDto{}, fmt.Errorf("logs %w: lint=%v", ErrNotFound, lintId)
+	} else if err != nil {
+		return LintLogsDto{}, err
+	}
+	if !access.allows(logs.LinterOwner) {
+		return LintLogsDto{}, ErrAccessDenied
+	}
+	stdout, err := utils.Gunzip(logs.Stdout)
+	if err != nil {
+		return LintLogsDto{}, fmt.Errorf("failed to decompress stdout of the task %v: %w", lintId, err)
+	}
+	stderr, err := utils.Gunzip(logs.Stderr)
+	if err != nil {
+		return LintLogsDto{}, fmt.Errorf("failed to decompress stderr of the task %v: %w", lintId, err)
+	}
+	return LintLogsDto{
+		Login:     userName(ctx),
+		LintId:    lintId,
+		Attempt:   int(logs.Attempt),
+		CreatedAt: logs.CreatedAt.Time.Format(time.DateTime),
+		Stdout:    LintLogStreamDto{Content: string(stdout), TotalBytes: logs.StdoutBytes, Truncated: int64(len(stdout)) < logs.StdoutBytes},
+		Stderr:    LintLogStreamDto{Content: string(stderr), TotalBytes: logs.StderrBytes, Truncated: int64(len(stderr)) < logs.StderrBytes},
+	}, nil
+}
+
+// dashboardRank orders the dashboard: items with more accepted and then pending highlights go first
+type dashboardRank struct {
+	accepted, pending, rejected int64
+	updatedAt                   time.Time
+}
+
+func (a dashboardRank) compare(b dashboardRank) int {
+	if a.accepted != b.accepted {
+		return cmp.Compare(b.accepted, a.accepted)
+	}
+	if a.pending != b.pending {
+		return cmp.Compare(b.pending, a.pending)
+	}
+	if a.rejected != b.rejected {
+		return cmp.Compare(a.rejected, b.rejected)
+	}
+	return b.updatedAt.Compare(a.updatedAt)
 }
 
 func (c ApiController) Dashboard(ctx context.Context) (DashboardDto, error) {
-	linters, err := c.Storage.ListBugHuntLinters(ctx)
+	linters, err := c.Storage.ListBugHuntLinters(ctx, db.ListBugHuntLintersParams{PageLimit: unlimitedPage})
 	if err != nil {
 		return DashboardDto{}, err
 	}
+	linterRank := func(linter db.ListBugHuntLintersRow) dashboardRank {
+		return dashboardRank{linter.AcceptedHighlight, linter.PendingHighlight, linter.RejectedHighlight, linter.UpdatedAt.Time}
+	}
+	slices.SortStableFunc(linters, func(a, b db.ListBugHuntLintersRow) int { return linterRank(a).compare(linterRank(b)) })
+	dtoLinters, err := c.linterDtos(ctx, linters)
+	if err != nil {
+		return DashboardDto{}, err
+	}
+	repos, err := c.Storage.ListBugHuntRepos(ctx, db.ListBugHuntReposParams{PageLimit: unlimitedPage})
+	if err != nil {
+		return DashboardDto{}, err
+	}
+	repoRank := func(repo db.ListBugHuntReposRow) dashboardRank {
+		return dashboardRank{repo.AcceptedHighlight, repo.PendingHighlight, repo.RejectedHighlight, repo.UpdatedAt.Time}
+	}
+	slices.SortStableFunc(repos, func(a, b db.ListBugHuntReposRow) int { return repoRank(a).compare(repoRank(b)) })
+	dtoRepos := make([]RepoDto, 0, len(repos))
+	for _, repo := range repos {
+		dtoRepos = append(dtoRepos, repoDto(repo))
+	}
+	return DashboardDto{
+		Login:   userName(ctx),
+		Linters: dtoLinters,
+		Repos:   dtoRepos,
+	}, nil
+}
+
+// DashboardLinters lists linters of the dashboard in the order of their creation: cursor points to the last linter of the previous page
+func (c ApiController) DashboardLinters(ctx context.Context, after pageCursor, limit int) (PageDto[LinterDto], error) {
+	linters, err := c.Storage.ListBugHuntLinters(ctx, db.ListBugHuntLintersParams{
+		AfterLinterID:  after.Id,
+		AfterCreatedAt: after.createdAt(),
+		PageLimit:      int32(limit + 1),
+	})
+	if err != nil {
+		return PageDto[LinterDto]{}, err
+	}
+	linters, nextCursor := cutPage(linters, limit, func(linter db.ListBugHuntLintersRow) pageCursor {
+		return pageCursor{CreatedAt: linter.CreatedAt.Time, Id: linter.LinterID}
+	})
+	dtoLinters, err := c.linterDtos(ctx, linters)
+	if err != nil {
+		return PageDto[LinterDto]{}, err
+	}
+	return PageDto[LinterDto]{Items: dtoLinters, NextCursor: nextCursor}, nil
+}
+
+// DashboardRepos lists repos of the dashboard in the order of their creation: cursor points to the last repo of the previous page
+func (c ApiController) DashboardRepos(ctx context.Context, after pageCursor, limit int) (PageDto[RepoDto], error) {
+	repos, err := c.Storage.ListBugHuntRepos(ctx, db.ListBugHuntReposParams{
+		AfterRepoID:    after.Id,
+		AfterCreatedAt: after.createdAt(),
+		PageLimit:      int32(limit + 1),
+	})
+	if err != nil {
+		return PageDto[RepoDto]{}, err
+	}
+	repos, nextCursor := cutPage(repos, limit, func(repo db.ListBugHuntReposRow) pageCursor {
+		return pageCursor{CreatedAt: repo.CreatedAt.Time, Id: repo.RepoID}
+	})
+	dtoRepos := make([]RepoDto, 0, len(repos))
+	for _, repo := range repos {
+		dtoRepos = append(dtoRepos, repoDto(repo))
+	}
+	return PageDto[RepoDto]{Items: dtoRepos, NextCursor: nextCursor}, nil
+}
+
+// linterDtos attaches rules and resource usage to the linters
+func (c ApiController) linterDtos(ctx context.Context, linters []db.ListBugHuntLintersRow) ([]LinterDto, error) {
+	rules, err := c.Storage.ListBugHuntLinterRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	linterRules := make(map[string][]RuleDto)
+	for _, rule := range rules {
+		linterRules[rule.LinterID] = append(linterRules[rule.LinterID], RuleDto{
+			Id: rule.RuleID,
+			StatDto: &StatDto{
+				TotalHighlight:    int(rule.TotalHighlight),
+				PendingHighlight:  int(rule.PendingHighlight),
+				RejectedHighlight: int(rule.RejectedHighlight),
+				AcceptedHighlight: int(rule.AcceptedHighlight),
+				FixedHighlight:    int(rule.FixedHighlight),
+			},
+		})
+	}
+	usages, err := c.Storage.ListBugHuntLinterUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	linterUsage := make(map[string]*UsageDto)
+	for _, usage := range usages {
+		linterUsage[usage.LinterID] = &UsageDto{
+			TotalTasks:      int(usage.TotalTasks),
+			TotalCpuSeconds: usage.TotalCpuSeconds,
+			AvgCpuSeconds:   usage.AvgCpuSeconds,
+			MaxRssBytes:     usage.MaxRssBytes,
+			AvgRssBytes:     usage.AvgRssBytes,
+			AvgIoBytes:      usage.AvgIoBytes,
+			MaxPids:         usage.MaxPids,
+		}
+	}
 	dtoLinters := make([]LinterDto, 0, len(linters))
 	for _, linter := range linters {
 		dtoLinters = append(dtoLinters, LinterDto{
@@ -135,37 +721,445 @@ func (c ApiController) Dashboard(ctx context.Context) (DashboardDto, error) {
 			GitBranch:          linter.LinterGitBranch,
 			DockerImage:        storage.TryGetText(linter.LinterLastDockerImage),
 			DockerImageShaHash: storage.TryGetText(linter.LinterLastDockerShaHash),
+			Rules:              linterRules[linter.LinterID],
+			Usage:              linterUsage[linter.LinterID],
 			StatDto: &StatDto{
 				TotalHighlight:    int(linter.TotalHighlight),
 				PendingHighlight:  int(linter.PendingHighlight),
 				RejectedHighlight: int(linter.RejectedHighlight),
 				AcceptedHighlight: int(linter.AcceptedHighlight),
+				FixedHighlight:    int(linter.FixedHighlight),
 			},
 		})
 	}
-	repos, err := c.Storage.ListBugHuntRepos(ctx)
+	return dtoLinters, nil
+}
+
+func repoDto(repo db.ListBugHuntReposRow) RepoDto {
+	return RepoDto{
+		Id:            repo.RepoID,
+		GitUrl:        repo.RepoGitUrl,
+		GitBranch:     repo.RepoGitBranch,
+		GitCommitHash: storage.TryGetText(repo.RepoLastGitCommitHash),
+		StatDto: &StatDto{
+			TotalHighlight:    int(repo.TotalHighlight),
+			PendingHighlight:  int(repo.PendingHighlight),
+			RejectedHighlight: int(repo.RejectedHighlight),
+			AcceptedHighlight: int(repo.AcceptedHighlight),
+			FixedHighlight:    int(repo.FixedHighlight),
+		},
+	}
+}
+
+// Login registers the user on the first login: new users can only view highlights until admin grants them more roles
+func (c ApiController) Login(ctx context.Context, identity lib.Identity) error {
+	login := identity.Login
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	return c.Storage.InTx(ctx, func(queries storage.Queries) error {
+		err := queries.UpsertUser(ctx, db.UpsertUserParams{UserLogin: login, UserName: identity.Name, CreatedAt: now})
+		if err != nil {
+			return fmt.Errorf("failed to upsert user: %w", err)
+		}
+		roles, err := queries.ListUserRoles(ctx, login)
+		if err != nil {
+			return fmt.Errorf("failed to get roles of the user: %w", err)
+		}
+		if len(roles) > 0 {
+			return nil
+		}
+		return queries.GrantUserRole(ctx, db.GrantUserRoleParams{
+			UserLogin: login,
+			Role:      db.AccessRoleViewer,
+			GrantedBy: login,
+			GrantedAt: now,
+		})
+	})
+}
+
+// BootstrapRole grants the role to the given logins - so fresh installation has someone who can manage roles.
+// Existing users are left untouched - so restart of the server doesn't bump their last login time
+func (c ApiController) BootstrapRole(ctx context.Context, role db.AccessRole, logins []string) error {
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	return c.Storage.InTx(ctx, func(queries storage.Queries) error {
+		for _, login := range logins {
+			err := queries.AddUser(ctx, db.AddUserParams{UserLogin: login, CreatedAt: now})
+			if err != nil {
+				return fmt.Errorf("failed to add user %v: %w", login, err)
+			}
+			err = queries.GrantUserRole(ctx, db.GrantUserRoleParams{
+				UserLogin: login,
+				Role:      role,
+				GrantedBy: "bootstrap",
+				GrantedAt: now,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to grant %v role to %v: %w", role, login, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (c ApiController) Users(ctx context.Context) (UsersDto, error) {
+	_, err := c.authorize(ctx)
+	if err != nil {
+		return UsersDto{}, err
+	}
+	users, err := c.Storage.ListUsers(ctx)
 	if err != nil {
-		return DashboardDto{}, err
+		return UsersDto{}, err
 	}
-	dtoRepos := make([]RepoDto, 0, len(repos))
-	for _, repo := range repos {
-		dtoRepos = append(dtoRepos, RepoDto{
-			Id:            repo.RepoID,
-			GitUrl:        repo.RepoGitUrl,
-			GitBranch:     repo.RepoGitBranch,
-			GitCommitHash: storage.TryGetText(repo.RepoLastGitCommitHash),
-			StatDto: &StatDto{
-				TotalHighlight:    int(repo.TotalHighlight),
-				PendingHighlight:  int(repo.PendingHighlight),
-				RejectedHighlight: int(repo.RejectedHighlight),
-				AcceptedHighlight: int(repo.AcceptedHighlight),
-			},
+	dtoUsers := make([]UserDto, 0, len(users))
+	for _, u := range users {
+		var roles []string
+		if u.Roles != "" {
+			roles = strings.Split(u.Roles, ",")
+		}
+		dtoUsers = append(dtoUsers, UserDto{
+			Login:       u.UserLogin,
+			Name:        u.UserName,
+			Roles:       roles,
+			CreatedAt:   u.CreatedAt.Time.Format(time.DateTime),
+			LastLoginAt: u.LastLoginAt.Time.Format(time.DateTime),
 		})
 	}
-	user, _ := ctx.Value("user").(string)
-	return DashboardDto{
-		Login:   user,
-		Linters: dtoLinters,
-		Repos:   dtoRepos,
-	}, nil
+	return UsersDto{Login: userName(ctx), CsrfToken: csrfToken(ctx), AllRoles: AllRoles, Users: dtoUsers}, nil
+}
+
+var AllRoles = []string{
+	string(db.AccessRoleViewer),
+	string(db.AccessRoleLinterOwner),
+	string(db.AccessRoleModerator),
+	string(db.AccessRoleAdmin),
+}
+
+func (c ApiController) UserRoleChange(ctx context.Context, login, role string, grant bool) error {
+	if err := requireSession(ctx); err != nil {
+		return err
+	}
+	user, err := c.authorize(ctx)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(AllRoles, role) {
+		return invalidArgumentf("unexpected role: %v", role)
+	}
+	if grant {
+		return c.Storage.GrantUserRole(ctx, db.GrantUserRoleParams{
+			UserLogin: login,
+			Role:      db.AccessRole(role),
+			GrantedBy: user,
+			GrantedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+		})
+	}
+	if login == user && role == string(db.AccessRoleAdmin) {
+		return fmt.Errorf("%w: admin can't revoke own admin role", ErrAccessDenied)
+	}
+	return c.Storage.RevokeUserRole(ctx, db.RevokeUserRoleParams{UserLogin: login, Role: db.AccessRole(role)})
+}
+
+var linterIdPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,63}$`)
+
+// linterSource validates source of the linter: either git repo (image will be built from it) or docker image pinned by digest
+func linterSource(gitUrl, gitBranch, imageReference string) (pgtype.Text, pgtype.Text, error) {
+	if gitUrl == "" && imageReference == "" {
+		return pgtype.Text{}, pgtype.Text{}, invalidArgumentf("either git url or docker image must be set")
+	}
+	if gitUrl != "" {
+		parsed, err := url.Parse(gitUrl)
+		if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return pgtype.Text{}, pgtype.Text{}, invalidArgumentf("git url must be https url: '%v'", gitUrl)
+		}
+		if gitBranch == "" {
+			return pgtype.Text{}, pgtype.Text{}, invalidArgumentf("git branch must be set for git url")
+		}
+	}
+	if imageReference == "" {
+		return pgtype.Text{}, pgtype.Text{}, nil
+	}
+	dockerImage, shaHash, err := lib.ParseDockerImageDigest(imageReference)
+	if err != nil {
+		return pgtype.Text{}, pgtype.Text{}, fmt.Errorf("%w: %w", ErrInvalidArgument, err)
+	}
+	return pgtype.Text{String: dockerImage, Valid: true}, pgtype.Text{String: shaHash, Valid: true}, nil
+}
+
+// SubmitLinter registers linter of the current user in pending state - it will not be scheduled until admin approves it
+func (c ApiController) SubmitLinter(ctx context.Context, linterId, gitUrl, gitBranch, imageReference string) error {
+	if err := allowScope(ctx, db.TokenScopeSubmitLinter); err != nil {
+		return err
+	}
+	user, err := c.authorize(ctx, db.AccessRoleViewer, db.AccessRoleLinterOwner, db.AccessRoleModerator)
+	if err != nil {
+		return err
+	}
+	if !linterIdPattern.MatchString(linterId) {
+		return invalidArgumentf("unexpected linter id: '%v'", linterId)
+	}
+	dockerImage, shaHash, err := linterSource(gitUrl, gitBranch, imageReference)
+	if err != nil {
+		return err
+	}
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	return c.Storage.InTx(ctx, func(queries storage.Queries) error {
+		err := queries.SubmitLinter(ctx, db.SubmitLinterParams{
+			LinterID:                linterId,
+			LinterGitUrl:            gitUrl,
+			LinterGitBranch:         gitBranch,
+			LinterLastDockerImage:   dockerImage,
+			LinterLastDockerShaHash: shaHash,
+			LinterOwner:             pgtype.Text{String: user, Valid: true},
+			CreatedAt:               now,
+		})
+		if storage.ViolatesUniqueConstraint(err) {
+			return fmt.Errorf("%w: linter %v already exists", ErrConflict, linterId)
+		} else if err != nil {
+			return fmt.Errorf("failed to submit linter: %w", err)
+		}
+		return queries.GrantUserRole(ctx, db.GrantUserRoleParams{
+			UserLogin: user,
+			Role:      db.AccessRoleLinterOwner,
+			GrantedBy: user,
+			GrantedAt: now,
+		})
+	})
+}
+
+// Linters returns linters owned by the current user (admin sees all linters in order to review submissions)
+func (c ApiController) Linters(ctx context.Context) (LintersDto, error) {
+	user, roles, err := c.userRoles(ctx)
+	if err != nil {
+		return LintersDto{}, err
+	}
+	admin := slices.Contains(roles, db.AccessRoleAdmin)
+	linters, err := c.Storage.ListLinters(ctx)
+	if err != nil {
+		return LintersDto{}, err
+	}
+	dtoLinters := make([]LinterDto, 0)
+	for _, linter := range linters {
+		if !admin && linter.LinterOwner.String != user {
+			continue
+		}
+		dtoLinters = append(dtoLinters, LinterDto{
+			Id:                 linter.LinterID,
+			GitUrl:             linter.LinterGitUrl,
+			GitBranch:          linter.LinterGitBranch,
+			DockerImage:        storage.TryGetText(linter.LinterLastDockerImage),
+			DockerImageShaHash: storage.TryGetText(linter.LinterLastDockerShaHash),
+			Status:             string(linter.LinterStatus),
+			Owner:              storage.TryGetText(linter.LinterOwner),
+			BuildError:         storage.TryGetText(linter.LinterBuildError),
+		})
+	}
+	return LintersDto{Login: userName(ctx), CsrfToken: csrfToken(ctx), Admin: admin, Linters: dtoLinters}, nil
+}
+
+// ownedLinter returns the linter if current user owns it or is admin
+func (c ApiController) ownedLinter(ctx context.Context, linterId string) (string, bool, db.GetLinterRow, error) {
+	user, roles, err := c.userRoles(ctx)
+	if err != nil {
+		return "", false, db.GetLinterRow{}, err
+	}
+	linter, err := c.Storage.GetLinter(ctx, linterId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, db.GetLinterRow{}, fmt.Errorf("linter %w: %v", ErrNotFound, linterId)
+	} else if err != nil {
+		return "", false, db.GetLinterRow{}, err
+	}
+	admin := slices.Contains(roles, db.AccessRoleAdmin)
+	if !admin && (linter.LinterOwner.String != user || !slices.Contains(roles, db.AccessRoleLinterOwner)) {
+		return "", false, db.GetLinterRow{}, ErrAccessDenied
+	}
+	return user, admin, linter, nil
+}
+
+// LinterStatusChange lets admin approve, reject or pause any linter, while owner can only pause and resume already approved one
+func (c ApiController) LinterStatusChange(ctx context.Context, linterId, status string) error {
+	if err := allowScope(ctx, db.TokenScopeSubmitLinter); err != nil {
+		return err
+	}
+	_, admin, linter, err := c.ownedLinter(ctx, linterId)
+	if err != nil {
+		return err
+	}
+	next := db.LinterStatus(status)
+	switch {
+	case admin && (next == db.LinterStatusActive || next == db.LinterStatusPaused || next == db.LinterStatusRejected):
+	case next == db.LinterStatusPaused && linter.LinterStatus == db.LinterStatusActive:
+	case next == db.LinterStatusActive && linter.LinterStatus == db.LinterStatusPaused:
+	default:
+		return fmt.Errorf("%w: unable to change linter status from %v to %v", ErrConflict, linter.LinterStatus, status)
+	}
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	return c.Storage.InTx(ctx, func(queries storage.Queries) error {
+		err := queries.SetLinterStatus(ctx, db.SetLinterStatusParams{
+			LinterID:     linterId,
+			LinterStatus: next,
+			UpdatedAt:    now,
+		})
+		if err != nil {
+			return err
+		}
+		// tasks of the inactive linter are cancelled (running attempts lose their lock) and return to the queue on resume
+		if next == db.LinterStatusActive {
+			_, err = queries.ResumeLinterLintTasks(ctx, linterId)
+		} else {
+			_, err = queries.CancelLinterLintTasks(ctx, db.CancelLinterLintTasksParams{
+				LinterID:    linterId,
+				Comment:     pgtype.Text{String: fmt.Sprintf("linter is %v", next), Valid: true},
+				CancelledAt: now,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update tasks of the linter %v: %w", linterId, err)
+		}
+		return nil
+	})
+}
+
+// UpdateLinterSource pushes new version of the linter: git repo will be rebuilt by the manager on the next iteration.
+// Changed source returns the linter to the pending state - admin must approve it again before it is scheduled
+func (c ApiController) UpdateLinterSource(ctx context.Context, linterId, gitUrl, gitBranch, imageReference string) error {
+	if err := allowScope(ctx, db.TokenScopeSubmitLinter); err != nil {
+		return err
+	}
+	_, _, _, err := c.ownedLinter(ctx, linterId)
+	if err != nil {
+		return err
+	}
+	dockerImage, shaHash, err := linterSource(gitUrl, gitBranch, imageReference)
+	if err != nil {
+		return err
+	}
+	return c.Storage.UpdateLinterSource(ctx, db.UpdateLinterSourceParams{
+		LinterID:                linterId,
+		LinterGitUrl:            gitUrl,
+		LinterGitBranch:         gitBranch,
+		LinterLastDockerImage:   dockerImage,
+		LinterLastDockerShaHash: shaHash,
+		UpdatedAt:               pgtype.Timestamp{Time: time.Now(), Valid: true},
+	})
+}
+
+var AllTokenScopes = []string{
+	string(db.TokenScopeReadOnly),
+	string(db.TokenScopeModerate),
+	string(db.TokenScopeSubmitLinter),
+}
+
+const apiTokenPrefix = "gbh_"
+
+// HashApiToken is used to store only hashes of the tokens: tokens are random enough, so salt is not needed
+func HashApiToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// CreateApiToken returns the plain token of the current user - it is shown only once and can't be recovered later
+func (c ApiController) CreateApiToken(ctx context.Context, name, scope string) (string, error) {
+	if err := requireSession(ctx); err != nil {
+		return "", err
+	}
+	user, err := c.authorize(ctx, db.AccessRoleViewer, db.AccessRoleLinterOwner, db.AccessRoleModerator)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		return "", invalidArgumentf("token name required")
+	}
+	if !slices.Contains(AllTokenScopes, scope) {
+		return "", invalidArgumentf("unexpected token scope: %v", scope)
+	}
+	secret := make([]byte, 32)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	_, err = c.Storage.CreateApiToken(ctx, db.CreateApiTokenParams{
+		UserLogin:  user,
+		TokenName:  name,
+		TokenHash:  HashApiToken(token),
+		TokenScope: db.TokenScope(scope),
+		CreatedAt:  pgtype.Timestamp{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create token: %w", err)
+	}
+	return token, nil
+}
+
+func (c ApiController) ApiTokens(ctx context.Context) (ApiTokensDto, error) {
+	if err := requireSession(ctx); err != nil {
+		return ApiTokensDto{}, err
+	}
+	user, err := c.authorize(ctx, db.AccessRoleViewer, db.AccessRoleLinterOwner, db.AccessRoleModerator)
+	if err != nil {
+		return ApiTokensDto{}, err
+	}
+	tokens, err := c.Storage.ListApiTokens(ctx, user)
+	if err != nil {
+		return ApiTokensDto{}, err
+	}
+	dtoTokens := make([]ApiTokenDto, 0, len(tokens))
+	for _, token := range tokens {
+		dtoToken := ApiTokenDto{
+			Id:        token.TokenID,
+			Name:      token.TokenName,
+			Scope:     string(token.TokenScope),
+			CreatedAt: token.CreatedAt.Time.Format(time.DateTime),
+		}
+		if token.LastUsedAt.Valid {
+			dtoToken.LastUsedAt = token.LastUsedAt.Time.Format(time.DateTime)
+		}
+		if token.RevokedAt.Valid {
+			dtoToken.RevokedAt = token.RevokedAt.Time.Format(time.DateTime)
+		}
+		dtoTokens = append(dtoTokens, dtoToken)
+	}
+	return ApiTokensDto{Login: userName(ctx), CsrfToken: csrfToken(ctx), AllScopes: AllTokenScopes, Tokens: dtoTokens}, nil
+}
+
+func (c ApiController) RevokeApiToken(ctx context.Context, tokenId int64) error {
+	if err := requireSession(ctx); err != nil {
+		return err
+	}
+	user, err := c.authorize(ctx, db.AccessRoleViewer, db.AccessRoleLinterOwner, db.AccessRoleModerator)
+	if err != nil {
+		return err
+	}
+	revoked, err := c.Storage.RevokeApiToken(ctx, db.RevokeApiTokenParams{
+		TokenID:   tokenId,
+		UserLogin: user,
+		RevokedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	if revoked == 0 {
+		return fmt.Errorf("active token %w: %v", ErrNotFound, tokenId)
+	}
+	return nil
+}
+
+// AuthenticateApiToken resolves owner and scope of the token and records its usage
+func (c ApiController) AuthenticateApiToken(ctx context.Context, token string) (string, db.TokenScope, error) {
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return "", "", fmt.Errorf("malformed API token")
+	}
+	apiToken, err := c.Storage.GetActiveApiToken(ctx, HashApiToken(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", fmt.Errorf("API token is unknown or revoked")
+	} else if err != nil {
+		return "", "", fmt.Errorf("failed to get API token: %w", err)
+	}
+	err = c.Storage.TouchApiToken(ctx, db.TouchApiTokenParams{
+		TokenID:    apiToken.TokenID,
+		LastUsedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to track API token usage: %w", err)
+	}
+	return apiToken.UserLogin, apiToken.TokenScope, nil
 }
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gobughunt/lib/utils"
+	"github.com/sivukhin/gobughunt/storage"
+	"github.com/sivukhin/gobughunt/storage/db"
+)
+
+func newTestController(t *testing.T, roles map[string]db.AccessRole) ApiController {
+	memory := storage.NewMemory()
+	for login, role := range roles {
+		require.Nil(t, memory.GrantUserRole(context.Background(), db.GrantUserRoleParams{
+			UserLogin: login,
+			Role:      role,
+			GrantedBy: "test",
+			GrantedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+		}))
+	}
+	return ApiController{Storage: memory, Quorum: 1}
+}
+
+func userContext(login string) context.Context {
+	return context.WithValue(context.Background(), "user", login)
+}
+
+func TestUpdateLinterSource(t *testing.T) {
+	c := newTestController(t, map[string]db.AccessRole{"owner": db.AccessRoleViewer, "admin": db.AccessRoleAdmin})
+	owner, admin := userContext("owner"), userContext("admin")
+	status := func() db.LinterStatus {
+		linter, err := c.Storage.GetLinter(context.Background(), "nilaway")
+		require.Nil(t, err)
+		return linter.LinterStatus
+	}
+	image := "ghcr.io/uber-go/nilaway@sha256:" + strings.Repeat("a", 64)
+
+	require.Nil(t, c.SubmitLinter(owner, "nilaway", "https://github.com/uber-go/nilaway", "main", ""))
+	require.Equal(t, db.LinterStatusPending, status())
+	require.Nil(t, c.LinterStatusChange(admin, "nilaway", "active"))
+
+	t.Run("same source", func(t *testing.T) {
+		require.Nil(t, c.UpdateLinterSource(owner, "nilaway", "https://github.com/uber-go/nilaway", "main", ""))
+		require.Equal(t, db.LinterStatusActive, status())
+	})
+	t.Run("branch", func(t *testing.T) {
+		require.Nil(t, c.UpdateLinterSource(owner, "nilaway", "https://github.com/uber-go/nilaway", "dev", ""))
+		require.Equal(t, db.LinterStatusPending, status())
+		require.ErrorIs(t, c.LinterStatusChange(owner, "nilaway", "active"), ErrConflict)
+		require.Nil(t, c.LinterStatusChange(admin, "nilaway", "active"))
+	})
+	t.Run("url", func(t *testing.T) {
+		require.Nil(t, c.UpdateLinterSource(owner, "nilaway", "https://github.com/sivukhin/nilaway", "dev", ""))
+		require.Equal(t, db.LinterStatusPending, status())
+		require.Nil(t, c.LinterStatusChange(admin, "nilaway", "active"))
+	})
+	t.Run("image", func(t *testing.T) {
+		require.Nil(t, c.UpdateLinterSource(owner, "nilaway", "", "", image))
+		require.Equal(t, db.LinterStatusPending, status())
+		require.Nil(t, c.LinterStatusChange(admin, "nilaway", "active"))
+
+		require.Nil(t, c.UpdateLinterSource(owner, "nilaway", "", "", image))
+		require.Equal(t, db.LinterStatusActive, status())
+		require.Nil(t, c.UpdateLinterSource(owner, "nilaway", "", "", "ghcr.io/uber-go/nilaway@sha256:"+strings.Repeat("b", 64)))
+		require.Equal(t, db.LinterStatusPending, status())
+	})
+	t.Run("not owner", func(t *testing.T) {
+		require.ErrorIs(t, c.UpdateLinterSource(userContext("other"), "nilaway", "", "", image), ErrAccessDenied)
+	})
+}
+
+func TestLintHighlightRevert(t *testing.T) {
+	c := newTestController(t, map[string]db.AccessRole{"m1": db.AccessRoleModerator, "m2": db.AccessRoleModerator, "admin": db.AccessRoleAdmin})
+	c.Quorum = 2
+	ctx := context.Background()
+	require.Nil(t, c.Storage.AddLintTask(ctx, db.AddLintTaskParams{
+		LintID:              "lint",
+		LintStatus:          db.LintStatusSucceeded,
+		LinterID:            "nilaway",
+		LinterDockerImage:   "nilaway",
+		LinterDockerShaHash: "sha",
+		RepoID:              "hugo",
+		RepoGitUrl:          "https://github.com/sivukhin/hugo",
+		RepoGitCommitHash:   "c1",
+		CreatedAt:           pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}))
+	require.Nil(t, c.Storage.AddLintHighlights(ctx, []db.AddLintHighlightParams{
+		{LintID: "lint", Path: "a.go", StartLine: 1, EndLine: 1, Severity: db.HighlightSeverityWarning, RuleID: "nilness", Fingerprint: "fp"},
+	}))
+	// lastEvent returns id of the latest moderation event of the user
+	lastEvent := func(login string) int64 {
+		events, err := c.Storage.ListModerationEvents(ctx, db.ListModerationEventsParams{Fingerprints: []string{"fp"}, LintIds: []string{"lint"}})
+		require.Nil(t, err)
+		var eventId int64
+		for _, event := range events {
+			if event.ModeratorLogin == login && event.EventID > eventId {
+				eventId = event.EventID
+			}
+		}
+		return eventId
+	}
+
+	status, err := c.LintHighlightModerate(userContext("m1"), "lint", "fp", "accepted", "")
+	require.Nil(t, err)
+	require.Equal(t, "pending", status)
+	status, err = c.LintHighlightModerate(userContext("m2"), "lint", "fp", "accepted", "")
+	require.Nil(t, err)
+	require.Equal(t, "accepted", status)
+
+	t.Run("vote of other moderator", func(t *testing.T) {
+		_, err := c.LintHighlightRevert(userContext("m1"), lastEvent("m2"), "")
+		require.ErrorIs(t, err, ErrAccessDenied)
+	})
+	t.Run("own vote", func(t *testing.T) {
+		status, err := c.LintHighlightRevert(userContext("m2"), lastEvent("m2"), "mistake")
+		require.Nil(t, err)
+		require.Equal(t, "pending", status)
+	})
+	t.Run("revert of revert", func(t *testing.T) {
+		_, err := c.LintHighlightRevert(userContext("admin"), lastEvent("m2"), "")
+		require.ErrorIs(t, err, ErrInvalidArgument)
+	})
+	t.Run("admin", func(t *testing.T) {
+		status, err := c.LintHighlightRevert(userContext("admin"), lastEvent("m1"), "")
+		require.Nil(t, err)
+		require.Equal(t, "pending", status)
+		votes, err := c.Storage.CountModerationVotes(ctx, db.CountModerationVotesParams{LintID: "lint", Fingerprint: "fp"})
+		require.Nil(t, err)
+		require.Equal(t, int64(0), votes.AcceptedVotes)
+	})
+}
+
+func TestLintHighlightModerateSameLine(t *testing.T) {
+	c := newTestController(t, map[string]db.AccessRole{"moderator": db.AccessRoleModerator})
+	ctx := context.Background()
+	require.Nil(t, c.Storage.AddLintTask(ctx, db.AddLintTaskParams{
+		LintID:              "lint",
+		LintStatus:          db.LintStatusSucceeded,
+		LinterID:            "golangci",
+		LinterDockerImage:   "golangci",
+		LinterDockerShaHash: "sha",
+		RepoID:              "hugo",
+		RepoGitUrl:          "https://github.com/sivukhin/hugo",
+		RepoGitCommitHash:   "c1",
+		CreatedAt:           pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}))
+	// golangci reports several rules on the same lines
+	require.Nil(t, c.Storage.AddLintHighlights(ctx, []db.AddLintHighlightParams{
+		{LintID: "lint", Path: "a.go", StartLine: 1, EndLine: 1, Severity: db.HighlightSeverityWarning, RuleID: "errcheck", Fingerprint: "fp-errcheck"},
+		{LintID: "lint", Path: "a.go", StartLine: 1, EndLine: 1, Severity: db.HighlightSeverityWarning, RuleID: "nilness", Fingerprint: "fp-nilness"},
+	}))
+
+	status, err := c.LintHighlightModerate(userContext("moderator"), "lint", "fp-nilness", "accepted", "")
+	require.Nil(t, err)
+	require.Equal(t, "accepted", status)
+
+	for fingerprint, expected := range map[string]db.HighlightStatus{"fp-nilness": db.HighlightStatusAccepted, "fp-errcheck": db.HighlightStatusPending} {
+		current, err := c.Storage.GetModerationStatus(ctx, db.GetModerationStatusParams{LintID: "lint", Fingerprint: fingerprint})
+		require.Nil(t, err)
+		require.Equal(t, expected, current, fingerprint)
+	}
+	votes, err := c.Storage.CountModerationVotes(ctx, db.CountModerationVotesParams{LintID: "lint", Fingerprint: "fp-errcheck"})
+	require.Nil(t, err)
+	require.Equal(t, int64(0), votes.AcceptedVotes)
+	events, err := c.Storage.ListModerationEvents(ctx, db.ListModerationEventsParams{
+		Fingerprints: []string{"fp-errcheck", "fp-nilness"},
+		LintIds:      []string{"lint"},
+	})
+	require.Nil(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "fp-nilness", events[0].Fingerprint)
+
+	rules, err := c.Storage.ListBugHuntLinterRules(ctx)
+	require.Nil(t, err)
+	accepted := make(map[string]int64)
+	for _, rule := range rules {
+		accepted[rule.RuleID] = rule.AcceptedHighlight
+	}
+	require.Equal(t, map[string]int64{"errcheck": 0, "nilness": 1}, accepted)
+}
+
+func TestPagination(t *testing.T) {
+	c := newTestController(t, map[string]db.AccessRole{"viewer": db.AccessRoleViewer, "moderator": db.AccessRoleModerator})
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	for _, repoId := range []string{"hugo", "caddy", "etcd"} {
+		require.Nil(t, c.Storage.UpsertRepo(ctx, db.UpsertRepoParams{
+			RepoID:     repoId,
+			RepoGitUrl: "https://github.com/sivukhin/" + repoId,
+			CreatedAt:  pgtype.Timestamp{Time: now, Valid: true},
+		}))
+	}
+	require.Nil(t, c.Storage.UpsertLinter(ctx, db.UpsertLinterParams{
+		LinterID:     "nilaway",
+		LinterGitUrl: "https://github.com/uber-go/nilaway",
+		CreatedAt:    pgtype.Timestamp{Time: now, Valid: true},
+	}))
+	for i, lintId := range []string{"lint-1", "lint-2", "lint-3"} {
+		require.Nil(t, c.Storage.AddLintTask(ctx, db.AddLintTaskParams{
+			LintID:              lintId,
+			LintStatus:          db.LintStatusSucceeded,
+			LinterID:            "nilaway",
+			LinterDockerImage:   "nilaway",
+			LinterDockerShaHash: "sha",
+			RepoID:              "hugo",
+			RepoGitUrl:          "https://github.com/sivukhin/hugo",
+			RepoGitCommitHash:   lintId,
+			// the first two tasks are created at the same time - id breaks the tie
+			CreatedAt: pgtype.Timestamp{Time: now.Add(time.Duration(i/2) * time.Second), Valid: true},
+		}))
+		_, err := c.Storage.AddLintAttempt(ctx, db.AddLintAttemptParams{
+			LintID:    lintId,
+			Attempt:   1,
+			WorkerID:  "worker-" + lintId,
+			StartedAt: pgtype.Timestamp{Time: now, Valid: true},
+		})
+		require.Nil(t, err)
+		require.Nil(t, c.Storage.AddLintHighlights(ctx, []db.AddLintHighlightParams{
+			{LintID: lintId, Path: "a.go", StartLine: 1, EndLine: 1, Severity: db.HighlightSeverityWarning, Fingerprint: lintId + "-a"},
+			{LintID: lintId, Path: "a.go", StartLine: 1, EndLine: 2, Severity: db.HighlightSeverityWarning, Fingerprint: lintId + "-b"},
+		}))
+	}
+
+	t.Run("repos", func(t *testing.T) {
+		var ids []string
+		var after pageCursor
+		for {
+			page, err := c.DashboardRepos(ctx, after, 2)
+			require.Nil(t, err)
+			require.LessOrEqual(t, len(page.Items), 2)
+			for _, repo := range page.Items {
+				ids = append(ids, repo.Id)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			after, _, err = parsePage(url.Values{"cursor": {page.NextCursor}})
+			require.Nil(t, err)
+		}
+		require.Equal(t, []string{"caddy", "etcd", "hugo"}, ids)
+	})
+	t.Run("lint tasks", func(t *testing.T) {
+		first, err := c.LintTasks(userContext("moderator"), "", pageCursor{}, 2)
+		require.Nil(t, err)
+		require.Len(t, first.Tasks, 2)
+		require.Equal(t, "lint-3", first.Tasks[0].Id)
+		require.Equal(t, "lint-2", first.Tasks[1].Id)
+
+		before, _, err := parsePage(url.Values{"cursor": {first.NextCursor}})
+		require.Nil(t, err)
+		last, err := c.LintTasks(userContext("moderator"), "", before, 2)
+		require.Nil(t, err)
+		require.Len(t, last.Tasks, 1)
+		require.Equal(t, "lint-1", last.Tasks[0].Id)
+		require.Empty(t, last.NextCursor)
+
+		// attempts are loaded only for the tasks of the page
+		for _, task := range append(first.Tasks, last.Tasks...) {
+			require.Len(t, task.History, 1)
+			require.Equal(t, "worker-"+task.Id, task.History[0].Worker)
+		}
+	})
+	t.Run("highlights are not shifted by moderation", func(t *testing.T) {
+		highlights := func(after pageCursor) LintHighlightsDto {
+			dto, err := c.LintHighlights(userContext("moderator"), "", "hugo", "", "", "", "", after, 4)
+			require.Nil(t, err)
+			return dto
+		}
+		first := highlights(pageCursor{})
+		require.Len(t, first.Highlights, 4)
+		// moderation of the seen highlight changes its status but not its place in the list
+		_, err := c.LintHighlightModerate(userContext("moderator"), "lint-1", "lint-1-a", "rejected", "")
+		require.Nil(t, err)
+
+		after, _, err := parsePage(url.Values{"cursor": {first.NextCursor}})
+		require.Nil(t, err)
+		last := highlights(after)
+		require.Empty(t, last.NextCursor)
+
+		seen := make(map[string]struct{})
+		for _, highlight := range append(first.Highlights, last.Highlights...) {
+			seen[fmt.Sprintf("%v/%v:%v-%v", highlight.LintId, highlight.Path, highlight.StartLine, highlight.EndLine)] = struct{}{}
+		}
+		require.Len(t, seen, 6)
+
+		for _, highlight := range highlights(pageCursor{}).Highlights {
+			if highlight.Fingerprint == "lint-1-a" {
+				require.Len(t, highlight.History, 1)
+			} else {
+				require.Empty(t, highlight.History)
+			}
+		}
+	})
+	t.Run("highlights at the same location", func(t *testing.T) {
+		require.Nil(t, c.Storage.AddLintTask(ctx, db.AddLintTaskParams{
+			LintID:              "lint-4",
+			LintStatus:          db.LintStatusSucceeded,
+			LinterID:            "nilaway",
+			LinterDockerImage:   "nilaway",
+			LinterDockerShaHash: "sha",
+			RepoID:              "caddy",
+			RepoGitUrl:          "https://github.com/sivukhin/caddy",
+			RepoGitCommitHash:   "lint-4",
+			CreatedAt:           pgtype.Timestamp{Time: now, Valid: true},
+		}))
+		require.Nil(t, c.Storage.AddLintHighlights(ctx, []db.AddLintHighlightParams{
+			{LintID: "lint-4", Path: "a.go", StartLine: 1, EndLine: 1, Severity: db.HighlightSeverityWarning, RuleID: "errcheck", Fingerprint: "fp-errcheck"},
+			{LintID: "lint-4", Path: "a.go", StartLine: 1, EndLine: 1, Severity: db.HighlightSeverityWarning, RuleID: "nilness", Fingerprint: "fp-nilness"},
+			{LintID: "lint-4", Path: "a.go", StartLine: 1, EndLine: 1, Severity: db.HighlightSeverityWarning, RuleID: "unused", Fingerprint: "fp-unused"},
+		}))
+		var rules []string
+		var after pageCursor
+		for {
+			page, err := c.LintHighlights(userContext("moderator"), "", "caddy", "", "", "", "", after, 1)
+			require.Nil(t, err)
+			require.LessOrEqual(t, len(page.Highlights), 1)
+			for _, highlight := range page.Highlights {
+				rules = append(rules, highlight.RuleId)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			after, _, err = parsePage(url.Values{"cursor": {page.NextCursor}})
+			require.Nil(t, err)
+		}
+		require.Equal(t, []string{"errcheck", "nilness", "unused"}, rules)
+	})
+}
+
+func TestReviewAccess(t *testing.T) {
+	c := newTestController(t, map[string]db.AccessRole{"viewer": db.AccessRoleViewer, "owner": db.AccessRoleViewer, "moderator": db.AccessRoleModerator})
+	ctx := context.Background()
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	require.Nil(t, c.Storage.UpsertRepo(ctx, db.UpsertRepoParams{RepoID: "hugo", RepoGitUrl: "https://github.com/sivukhin/hugo", CreatedAt: now}))
+	require.Nil(t, c.SubmitLinter(userContext("owner"), "nilaway", "https://github.com/uber-go/nilaway", "main", ""))
+	require.Nil(t, c.Storage.UpsertLinter(ctx, db.UpsertLinterParams{LinterID: "errcheck", LinterGitUrl: "https://github.com/kisielk/errcheck", CreatedAt: now}))
+	logs, err := utils.Gzip([]byte("panic: nil pointer dereference"))
+	require.Nil(t, err)
+	for _, linterId := range []string{"nilaway", "errcheck"} {
+		lintId := "lint-" + linterId
+		require.Nil(t, c.Storage.AddLintTask(ctx, db.AddLintTaskParams{
+			LintID:              lintId,
+			LintStatus:          db.LintStatusSucceeded,
+			LinterID:            linterId,
+			LinterDockerImage:   linterId,
+			LinterDockerShaHash: "sha",
+			RepoID:              "hugo",
+			RepoGitUrl:          "https://github.com/sivukhin/hugo",
+			RepoGitCommitHash:   "commit",
+			CreatedAt:           now,
+		}))
+		require.Nil(t, c.Storage.AddLintHighlights(ctx, []db.AddLintHighlightParams{
+			{LintID: lintId, Path: "a.go", StartLine: 1, EndLine: 1, Severity: db.HighlightSeverityWarning, Fingerprint: "fp"},
+		}))
+		_, err := c.Storage.AddLintAttempt(ctx, db.AddLintAttemptParams{LintID: lintId, Attempt: 1, WorkerID: "worker", StartedAt: now})
+		require.Nil(t, err)
+		require.Nil(t, c.Storage.SetLintLogs(ctx, db.SetLintLogsParams{LintID: lintId, Attempt: 1, Stdout: logs, Stderr: logs, CreatedAt: now}))
+	}
+	// reviewed returns lints whose highlights, attempts and logs are visible to the user
+	reviewed := func(login string) (highlights, attempts, logs []string) {
+		highlightsDto, err := c.LintHighlights(userContext(login), "", "", "", "", "", "", pageCursor{}, 10)
+		require.Nil(t, err)
+		for _, highlight := range highlightsDto.Highlights {
+			highlights = append(highlights, highlight.LintId)
+		}
+		tasksDto, err := c.LintTasks(userContext(login), "", pageCursor{}, 10)
+		require.Nil(t, err)
+		require.Len(t, tasksDto.Tasks, 2)
+		for _, task := range tasksDto.Tasks {
+			if len(task.History) > 0 {
+				attempts = append(attempts, task.Id)
+			}
+			_, err := c.LintLogs(userContext(login), task.Id)
+			if err == nil {
+				require.True(t, task.HasLogs)
+				logs = append(logs, task.Id)
+			} else {
+				require.ErrorIs(t, err, ErrAccessDenied)
+				require.False(t, task.HasLogs)
+			}
+		}
+		slices.Sort(highlights)
+		slices.Sort(attempts)
+		slices.Sort(logs)
+		return highlights, attempts, logs
+	}
+
+	t.Run("viewer", func(t *testing.T) {
+		highlights, attempts, logs := reviewed("viewer")
+		require.Empty(t, highlights)
+		require.Empty(t, attempts)
+		require.Empty(t, logs)
+	})
+	t.Run("owner", func(t *testing.T) {
+		highlights, attempts, logs := reviewed("owner")
+		require.Equal(t, []string{"lint-nilaway"}, highlights)
+		require.Equal(t, []string{"lint-nilaway"}, attempts)
+		require.Equal(t, []string{"lint-nilaway"}, logs)
+	})
+	t.Run("moderator", func(t *testing.T) {
+		highlights, attempts, logs := reviewed("moderator")
+		require.Equal(t, []string{"lint-errcheck", "lint-nilaway"}, highlights)
+		require.Equal(t, []string{"lint-errcheck", "lint-nilaway"}, attempts)
+		require.Equal(t, []string{"lint-errcheck", "lint-nilaway"}, logs)
+	})
+	t.Run("moderated highlight", func(t *testing.T) {
+		_, err := c.LintHighlightModerate(userContext("moderator"), "lint-errcheck", "fp", "accepted", "")
+		require.Nil(t, err)
+		highlights, _, _ := reviewed("viewer")
+		require.Equal(t, []string{"lint-errcheck"}, highlights)
+	})
+	t.Run("anonymous", func(t *testing.T) {
+		_, err := c.LintHighlights(userContext("anonymous"), "", "", "", "", "", "", pageCursor{}, 10)
+		require.ErrorIs(t, err, ErrAccessDenied)
+	})
+}
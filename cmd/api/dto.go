@@ -8,59 +8,170 @@ type DashboardDto struct {
 
 type LintHighlightsDto struct {
 	Login      string
+	CsrfToken  string
 	LintId     string
 	RepoId     string
 	LinterId   string
+	RuleId     string
+	Severity   string
+	Status     string
 	Highlights []LintHighlightDto
+	NextCursor string
 }
 
 type StatDto struct {
-	TotalHighlight    int
-	PendingHighlight  int
-	RejectedHighlight int
-	AcceptedHighlight int
+	TotalHighlight    int `json:"totalHighlight"`
+	PendingHighlight  int `json:"pendingHighlight"`
+	RejectedHighlight int `json:"rejectedHighlight"`
+	AcceptedHighlight int `json:"acceptedHighlight"`
+	FixedHighlight    int `json:"fixedHighlight"` // accepted highlights which disappeared in the later commits of the repo
 }
 
 type LinterDto struct {
-	Id                 string
-	GitUrl             string
-	GitBranch          string
-	DockerImage        *string
-	DockerImageShaHash *string
+	Id                 string    `json:"id"`
+	GitUrl             string    `json:"gitUrl"`
+	GitBranch          string    `json:"gitBranch"`
+	DockerImage        *string   `json:"dockerImage,omitempty"`
+	DockerImageShaHash *string   `json:"dockerImageShaHash,omitempty"`
+	Status             string    `json:"status,omitempty"`
+	Owner              *string   `json:"owner,omitempty"`
+	BuildError         *string   `json:"buildError,omitempty"` // the last commit failed to build - it's retried after the source update
+	Rules              []RuleDto `json:"rules,omitempty"`
+	Usage              *UsageDto `json:"usage,omitempty"` // aggregated over the lint tasks with collected resource usage
+	*StatDto
+}
+
+type UsageDto struct {
+	TotalTasks      int     `json:"totalTasks"`
+	TotalCpuSeconds float64 `json:"totalCpuSeconds"`
+	AvgCpuSeconds   float64 `json:"avgCpuSeconds"`
+	MaxRssBytes     int64   `json:"maxRssBytes"`
+	AvgRssBytes     int64   `json:"avgRssBytes"`
+	AvgIoBytes      int64   `json:"avgIoBytes"`
+	MaxPids         int64   `json:"maxPids"`
+}
+
+// RuleDto is a single check (or sub-linter) of the linter image
+type RuleDto struct {
+	Id string `json:"id"`
 	*StatDto
 }
 
 type RepoDto struct {
-	Id            string
-	GitUrl        string
-	GitBranch     string
-	GitCommitHash *string
+	Id            string  `json:"id"`
+	GitUrl        string  `json:"gitUrl"`
+	GitBranch     string  `json:"gitBranch"`
+	GitCommitHash *string `json:"gitCommitHash,omitempty"`
 	*StatDto
 }
 
 type LintTaskDto struct {
-	Id              string
-	Status          string
-	StatusComment   *string
-	LintDurationSec *float64
-	Linter          LinterDto
-	Repo            RepoDto
+	Id              string        `json:"id"`
+	Status          string        `json:"status"`
+	StatusComment   *string       `json:"statusComment,omitempty"`
+	LintDurationSec *float64      `json:"lintDurationSec,omitempty"`
+	Linter          LinterDto     `json:"linter"`
+	Repo            RepoDto       `json:"repo"`
+	Attempts        int           `json:"attempts"`
+	NextAttemptAt   string        `json:"nextAttemptAt,omitempty"` // set only for the task waiting for retry
+	Usage           *TaskUsageDto `json:"usage,omitempty"`
+	HasLogs         bool          `json:"hasLogs"` // raw output of the linter is available at /lint-logs page
+
+	History []LintAttemptDto `json:"history,omitempty"`
+}
+
+// TaskUsageDto is a resource usage of the linter container sampled during the last attempt of the task
+type TaskUsageDto struct {
+	CpuSeconds  float64 `json:"cpuSeconds"`
+	MaxRssBytes int64   `json:"maxRssBytes"`
+	IoBytes     int64   `json:"ioBytes"`
+	MaxPids     int64   `json:"maxPids"`
+}
+
+type LintAttemptDto struct {
+	Attempt    int     `json:"attempt"`
+	Worker     string  `json:"worker"`
+	Status     string  `json:"status,omitempty"` // empty while attempt is running
+	StartedAt  string  `json:"startedAt"`
+	FinishedAt string  `json:"finishedAt,omitempty"`
+	ExitCode   *int32  `json:"exitCode,omitempty"`
+	Error      *string `json:"error,omitempty"`
 }
 
 type HighlightSnippetDto struct {
-	StartLine int
-	EndLine   int
-	Code      string
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	Code      string `json:"code"`
 }
 
 type LintHighlightDto struct {
-	LintId      string
-	Linter      LinterDto
-	Repo        RepoDto
-	Status      string
-	Path        string
-	StartLine   int
-	EndLine     int
-	Explanation string
-	Snippet     HighlightSnippetDto
+	LintId      string              `json:"lintId"`
+	Fingerprint string              `json:"fingerprint"` // identifies the highlight within the lint
+	Linter      LinterDto           `json:"linter"`
+	Repo        RepoDto             `json:"repo"`
+	Status      string              `json:"status"`
+	Path        string              `json:"path"`
+	StartLine   int                 `json:"startLine"`
+	EndLine     int                 `json:"endLine"`
+	StartColumn int                 `json:"startColumn"`
+	EndColumn   int                 `json:"endColumn"`
+	Severity    string              `json:"severity"`
+	RuleId      string              `json:"ruleId"`
+	Explanation string              `json:"explanation"`
+	Snippet     HighlightSnippetDto `json:"snippet"`
+
+	FirstSeenCommitHash *string `json:"firstSeenCommitHash,omitempty"`
+	LastSeenCommitHash  *string `json:"lastSeenCommitHash,omitempty"`
+	FixedCommitHash     *string `json:"fixedCommitHash,omitempty"`
+
+	History []ModerationEventDto `json:"history,omitempty"`
+}
+
+type ModerationEventDto struct {
+	Id              int64  `json:"id"`
+	Moderator       string `json:"moderator"`
+	PreviousStatus  string `json:"previousStatus"`
+	Status          string `json:"status"`
+	Vote            string `json:"vote"` // empty if moderator withdrew the vote
+	Comment         string `json:"comment"`
+	RevertedEventId *int64 `json:"revertedEventId,omitempty"`
+	CreatedAt       string `json:"createdAt"`
+}
+
+type UsersDto struct {
+	Login     string
+	CsrfToken string
+	AllRoles  []string
+	Users     []UserDto
+}
+
+type UserDto struct {
+	Login       string   `json:"login"`
+	Name        string   `json:"name"`
+	Roles       []string `json:"roles,omitempty"`
+	CreatedAt   string   `json:"createdAt"`
+	LastLoginAt string   `json:"lastLoginAt"`
+}
+
+type LintersDto struct {
+	Login     string
+	CsrfToken string
+	Admin     bool
+	Linters   []LinterDto
+}
+
+type ApiTokensDto struct {
+	Login     string
+	CsrfToken string
+	AllScopes []string
+	Tokens    []ApiTokenDto
+}
+
+type ApiTokenDto struct {
+	Id         int64
+	Name       string
+	Scope      string
+	CreatedAt  string
+	LastUsedAt string // empty if token was never used
+	RevokedAt  string // empty for active token
 }
@@ -3,90 +3,182 @@ package main
 import (
 	"context"
 	_ "embed"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
-	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/docker/go-units"
+	"github.com/jackc/pgx/v5"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/endpoints"
 
+	"github.com/sivukhin/gobughunt/lib"
 	"github.com/sivukhin/gobughunt/lib/logging"
 	"github.com/sivukhin/gobughunt/lib/utils"
 	"github.com/sivukhin/gobughunt/storage"
+	"github.com/sivukhin/gobughunt/storage/db"
 )
 
+// settings of the server are read from the env in main
 var (
-	connectionDuration       = utils.EnvMustParseDurationSec("CONNECTION_DURATION_SEC")
-	connectionString         = utils.EnvMustParseString("CONNECTION_STRING")
-	serverLocal              = utils.EnvTryParseBool("SERVER_LOCAL")
-	serverListenAddr         = utils.EnvMustParseString("SERVER_LISTEN_ADDR")
-	serverJwtSecretKey       = []byte(utils.EnvMustParseString("SERVER_JWT_SECRET_KEY"))
-	serverModeratorLogins    = utils.EnvMustParseStringArray("SERVER_MODERATOR_LOGINS")
-	githubOauth2ClientId     = utils.EnvMustParseString("GITHUB_OAUTH_CLIENT_ID")
-	githubOauth2ClientSecret = utils.EnvMustParseString("GITHUB_OAUTH_CLIENT_SECRET")
+	connectionDuration     time.Duration
+	connectionString       string
+	skipMigrations         bool // only check that schema is up to date
+	serverLocal            bool
+	serverListenAddr       string
+	serverJwtSecretKey     []byte
+	serverSessionDuration  time.Duration
+	serverSessionLifetime  time.Duration // session can't be refreshed after that time since the login
+	serverAdminLogins      []string      // logins of the identity provider (subject claim for OIDC)
+	serverModeratorLogins  []string      // moderators from the times before roles were stored in the database
+	serverModerationQuorum int64
+	oauthProvider          string // github, gitlab, gitea or oidc
+	oauthBaseUrl           string
+	oauthNameClaim         string
+	oauthRedirectUrl       string
+	oauthClientId          string
+	oauthClientSecret      string
 )
 
+func readEnv() {
+	connectionDuration = utils.EnvMustParseDurationSec("CONNECTION_DURATION_SEC")
+	connectionString = utils.EnvMustParseString("CONNECTION_STRING")
+	skipMigrations = utils.EnvTryParseBool("SKIP_MIGRATIONS")
+	serverLocal = utils.EnvTryParseBool("SERVER_LOCAL")
+	serverListenAddr = utils.EnvMustParseString("SERVER_LISTEN_ADDR")
+	serverJwtSecretKey = []byte(utils.EnvMustParseString("SERVER_JWT_SECRET_KEY"))
+	serverSessionDuration = utils.EnvMustParseDurationSec("SERVER_SESSION_DURATION_SEC")
+	serverSessionLifetime = utils.EnvTryParseDurationSec("SERVER_SESSION_LIFETIME_SEC", 7*24*time.Hour)
+	serverAdminLogins = utils.EnvMustParseStringArray("SERVER_ADMIN_LOGINS")
+	serverModeratorLogins = utils.EnvTryParseStringArray("SERVER_MODERATOR_LOGINS")
+	serverModerationQuorum = utils.EnvMustParseInt("SERVER_MODERATION_QUORUM")
+	oauthProvider = utils.EnvTryParseString("OAUTH_PROVIDER", "github")
+	oauthBaseUrl = utils.EnvTryParseString("OAUTH_BASE_URL", "")
+	oauthNameClaim = utils.EnvTryParseString("OAUTH_NAME_CLAIM", "")
+	oauthRedirectUrl = utils.EnvTryParseString("OAUTH_REDIRECT_URL", "")
+	oauthClientId = utils.EnvMustParseStringDeprecated("OAUTH_CLIENT_ID", "GITHUB_OAUTH_CLIENT_ID")
+	oauthClientSecret = utils.EnvMustParseStringDeprecated("OAUTH_CLIENT_SECRET", "GITHUB_OAUTH_CLIENT_SECRET")
+}
+
 var (
 	//go:embed templates/dashboard.html
 	dashboardTemplateString string
 	//go:embed templates/lint-tasks.html
 	lintTasksTemplateString string
+	//go:embed templates/lint-logs.html
+	lintLogsTemplateString string
 	//go:embed templates/lint-highlights.html
 	lintHighlightsTemplateString string
 	//go:embed templates/about.html
 	aboutTemplateString string
+	//go:embed templates/users.html
+	usersTemplateString string
+	//go:embed templates/linters.html
+	lintersTemplateString string
+	//go:embed templates/tokens.html
+	tokensTemplateString string
 )
 
-var (
-	githubOauthConfig = &oauth2.Config{
-		ClientID:     githubOauth2ClientId,
-		ClientSecret: githubOauth2ClientSecret,
-		Scopes:       []string{"(no scope)"},
-		Endpoint:     endpoints.GitHub,
-		RedirectURL:  utils.Ternary(serverLocal, "http://localhost:3000", ""),
-	}
-)
+// sessions manages cookie sessions of the users (set up in main)
+var sessions SessionManager
+
+// authenticateApiToken resolves login and scope of the token from the "Authorization: Bearer" header (set up in main)
+var authenticateApiToken func(ctx context.Context, token string) (string, db.TokenScope, error)
 
 func basicAuth(writer http.ResponseWriter, request *http.Request) (*http.Request, bool) {
 	if serverLocal {
 		return request, true
 	}
-	signedJwt, err := request.Cookie("GobughuntJwt")
-	if err != nil {
-		return request, true
+	if authorization := request.Header.Get("Authorization"); authorization != "" {
+		token, ok := strings.CutPrefix(authorization, "Bearer ")
+		if !ok {
+			unauthorized(writer, fmt.Errorf("unsupported authorization scheme"))
+			return request, false
+		}
+		user, scope, err := authenticateApiToken(request.Context(), strings.TrimSpace(token))
+		if err != nil {
+			unauthorized(writer, err)
+			return request, false
+		}
+		ctx := context.WithValue(request.Context(), "user", user)
+		return request.WithContext(context.WithValue(ctx, "scope", scope)), true
 	}
-	var claims jwt.MapClaims
-	token, err := jwt.ParseWithClaims(signedJwt.Value, &claims, func(token *jwt.Token) (interface{}, error) { return serverJwtSecretKey, nil })
-	if err != nil {
-		logging.Logger.Errorf("failed to parse JWT token: %v", err)
-		return request, true
+	return sessions.Authenticate(writer, request), true
+}
+
+type lintHighlightsFilter struct {
+	LintId   string
+	RepoId   string
+	LinterId string
+	RuleId   string
+	Severity string
+	Status   string
+}
+
+func parseLintHighlightsFilter(params url.Values) (lintHighlightsFilter, error) {
+	filter := lintHighlightsFilter{
+		LintId:   params.Get("lintId"),
+		RepoId:   params.Get("repoId"),
+		LinterId: params.Get("linterId"),
+		RuleId:   params.Get("ruleId"),
+		Severity: params.Get("severity"),
+		Status:   params.Get("status"),
 	}
-	if !token.Valid {
-		logging.Logger.Errorf("JWT token is invalid")
-		return request, true
+	status := filter.Status
+	if status != "" && status != "pending" && status != "disputed" && status != "accepted" && status != "rejected" {
+		return lintHighlightsFilter{}, invalidArgumentf("unexpected status: %v", status)
 	}
-	user, ok := claims["user"]
-	if !ok {
-		return request, true
+	if filter.LintId == "" && filter.RepoId == "" && filter.LinterId == "" && status != "disputed" {
+		return lintHighlightsFilter{}, invalidArgumentf("one of three parameters should be set: lintId, repoId, linterId")
+	}
+	severity := filter.Severity
+	if severity != "" && severity != "notice" && severity != "warning" && severity != "error" {
+		return lintHighlightsFilter{}, invalidArgumentf("unexpected severity: %v", severity)
 	}
-	return request.WithContext(context.WithValue(request.Context(), "user", user)), true
-	//verifier := oauth2.GenerateVerifier()
-	//redirect := githubOauthConfig.AuthCodeURL("", oauth2.S256ChallengeOption(verifier))
-	//http.Redirect(writer, request, redirect, http.StatusTemporaryRedirect)
-	//return false
+	return filter, nil
+}
+
+var ErrMethodNotAllowed = errors.New("method not allowed")
 
-	//user, pass, ok := request.BasicAuth()
-	//if !ok || user != serverUser || pass != serverPass {
-	//	writer.Header().Set("WWW-Authenticate", "Basic realm=\"Access to the gobughunt\"")
-	//	writer.WriteHeader(http.StatusUnauthorized)
-	//	return false
-	//}
-	//return true
+// httpStatus maps errors of the controller to the HTTP status codes
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrMethodNotAllowed):
+		return http.StatusMethodNotAllowed
+	case errors.Is(err, ErrInvalidArgument):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrAccessDenied):
+		return http.StatusForbidden
+	case errors.Is(err, ErrNotFound), errors.Is(err, pgx.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func unauthorized(writer http.ResponseWriter, err error) {
+	logging.Logger.Errorf("failed to authenticate API token: %v", err)
+	writer.Header().Set("WWW-Authenticate", `Bearer realm="gobughunt"`)
+	writer.WriteHeader(http.StatusUnauthorized)
+	_, _ = writer.Write([]byte("invalid API token"))
+}
+
+// post restricts state-changing handlers to POST requests - so they are always protected by CSRF token
+func post(handle func(request *http.Request, writer http.ResponseWriter) (string, error)) func(request *http.Request, writer http.ResponseWriter) (string, error) {
+	return func(request *http.Request, writer http.ResponseWriter) (string, error) {
+		if request.Method != http.MethodPost {
+			return "", fmt.Errorf("%w: %v", ErrMethodNotAllowed, request.Method)
+		}
+		return handle(request, writer)
+	}
 }
 
 func log(handle http.Handler) http.HandlerFunc {
@@ -105,10 +197,17 @@ func wrap(handle func(request *http.Request, writer http.ResponseWriter) (string
 		}
 		request = update
 
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			err := sessions.CheckCsrf(request)
+			if err != nil {
+				writer.WriteHeader(httpStatus(err))
+				_, _ = writer.Write([]byte(err.Error()))
+				return
+			}
+		}
 		result, err := handle(request, writer)
 		if err != nil {
-			// todo (sivukhin, 2024-02-25): add more errors
-			writer.WriteHeader(http.StatusInternalServerError)
+			writer.WriteHeader(httpStatus(err))
 			_, _ = writer.Write([]byte(err.Error()))
 			return
 		}
@@ -123,154 +222,330 @@ func wrap(handle func(request *http.Request, writer http.ResponseWriter) (string
 }
 
 func main() {
+	readEnv()
 	templateFuncs := template.FuncMap{
-		"DerefF64": func(f *float64) float64 { return *f },
-		"DerefStr": func(s *string) string { return *s },
+		"DerefF64":  func(f *float64) float64 { return *f },
+		"DerefStr":  func(s *string) string { return *s },
+		"Inc":       func(i int) int { return i + 1 },
+		"HasString": func(values []string, value string) bool { return slices.Contains(values, value) },
+		"Bytes":     func(size int64) string { return units.BytesSize(float64(size)) },
 	}
 
 	var (
 		dashboardTemplate      = template.Must(template.New("dashboard").Funcs(templateFuncs).Parse(dashboardTemplateString))
 		lintTasksTemplate      = template.Must(template.New("lint-tasks").Funcs(templateFuncs).Parse(lintTasksTemplateString))
+		lintLogsTemplate       = template.Must(template.New("lint-logs").Funcs(templateFuncs).Parse(lintLogsTemplateString))
 		lintHighlightsTemplate = template.Must(template.New("lint-highlights").Funcs(templateFuncs).Parse(lintHighlightsTemplateString))
 		aboutTemplate          = template.Must(template.New("about").Funcs(templateFuncs).Parse(aboutTemplateString))
+		usersTemplate          = template.Must(template.New("users").Funcs(templateFuncs).Parse(usersTemplateString))
+		lintersTemplate        = template.Must(template.New("linters").Funcs(templateFuncs).Parse(lintersTemplateString))
+		tokensTemplate         = template.Must(template.New("tokens").Funcs(templateFuncs).Parse(tokensTemplateString))
 	)
 
 	connectCtx, cancel := context.WithTimeout(context.Background(), connectionDuration)
 	defer cancel()
 
-	pgStorage, err := storage.NewPgQueries(connectCtx, connectionString)
+	pool, err := storage.NewPgStorage(connectCtx, connectionString)
 	if err != nil {
 		logging.Logger.Fatalf("failed to create task storage: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = storage.Migrate(connectCtx, pool, storage.Migrations())
+		if err != nil {
+			logging.Logger.Fatalf("failed to migrate database: %v", err)
+		}
+		return
+	}
+	err = storage.PrepareSchema(connectCtx, pool, skipMigrations)
+	if err != nil {
+		logging.Logger.Fatalf("failed to prepare database schema: %v", err)
+	}
+
 	apiController := ApiController{
-		Storage:         pgStorage,
-		ModeratorLogins: serverModeratorLogins,
+		Storage: storage.NewPostgres(pool),
+		Quorum:  int(serverModerationQuorum),
+	}
+	identityProvider, err := lib.NewIdentityProvider(connectCtx, lib.IdentityProviderConfig{
+		Provider:     oauthProvider,
+		ClientId:     oauthClientId,
+		ClientSecret: oauthClientSecret,
+		RedirectUrl:  utils.Ternary(serverLocal, "http://localhost:3000", oauthRedirectUrl),
+		BaseUrl:      oauthBaseUrl,
+		NameClaim:    oauthNameClaim,
+	})
+	if err != nil {
+		logging.Logger.Fatalf("failed to create identity provider: %v", err)
+	}
+
+	authenticateApiToken = apiController.AuthenticateApiToken
+	sessions = SessionManager{
+		Storage:   apiController.Storage,
+		SecretKey: serverJwtSecretKey,
+		Duration:  serverSessionDuration,
+		Lifetime:  serverSessionLifetime,
+		Secure:    !serverLocal,
+	}
+	err = apiController.BootstrapRole(connectCtx, db.AccessRoleAdmin, serverAdminLogins)
+	if err != nil {
+		logging.Logger.Fatalf("failed to bootstrap admins: %v", err)
+	}
+	err = apiController.BootstrapRole(connectCtx, db.AccessRoleModerator, serverModeratorLogins)
+	if err != nil {
+		logging.Logger.Fatalf("failed to bootstrap moderators: %v", err)
 	}
 
 	server := http.NewServeMux()
 	static := http.FileServer(http.Dir("./static"))
 	server.Handle("/static/", log(http.StripPrefix("/static/", static)))
 	server.Handle("/lint-highlights", log(wrap(func(request *http.Request, writer http.ResponseWriter) (string, error) {
-		params := request.URL.Query()
-		lintId := params.Get("lintId")
-		repoId := params.Get("repoId")
-		linterId := params.Get("linterId")
-		if lintId == "" && repoId == "" && linterId == "" {
-			return "", fmt.Errorf("one of three parameters should be set: lintId, repoId, linterId")
+		filter, err := parseLintHighlightsFilter(request.URL.Query())
+		if err != nil {
+			return "", err
 		}
-		dtoHighlights, err := apiController.LintHighlights(request.Context(), lintId, repoId, linterId)
+		dtoHighlights, err := apiController.LintHighlights(
+			request.Context(),
+			filter.LintId,
+			filter.RepoId,
+			filter.LinterId,
+			filter.RuleId,
+			filter.Severity,
+			filter.Status,
+			pageCursor{},
+			unlimitedPage,
+		)
 		if err != nil {
 			return "", err
 		}
+		sortForReview(dtoHighlights.Highlights)
 		return RenderTemplate(lintHighlightsTemplate, dtoHighlights)
 	})))
 	server.HandleFunc("/lint-tasks", log(wrap(func(request *http.Request, writer http.ResponseWriter) (string, error) {
-		dtoTasks, err := apiController.LintTasks(request.Context(), 0, 10000)
+		linterId := request.URL.Query().Get("linterId")
+		dtoTasks, err := apiController.LintTasks(request.Context(), linterId, pageCursor{}, 10000)
 		if err != nil {
 			return "", err
 		}
+		sortByStatus(dtoTasks.Tasks)
 		return RenderTemplate(lintTasksTemplate, dtoTasks)
 	})))
+	server.HandleFunc("/lint-logs", log(wrap(func(request *http.Request, writer http.ResponseWriter) (string, error) {
+		lintId := request.URL.Query().Get("lintId")
+		if lintId == "" {
+			return "", invalidArgumentf("lintId required")
+		}
+		dtoLogs, err := apiController.LintLogs(request.Context(), lintId)
+		if err != nil {
+			return "", err
+		}
+		return RenderTemplate(lintLogsTemplate, dtoLogs)
+	})))
 	server.HandleFunc("/about", log(wrap(func(request *http.Request, writer http.ResponseWriter) (string, error) {
 		login, _ := request.Context().Value("user").(string)
 		return RenderTemplate(aboutTemplate, struct{ Login string }{Login: login})
 	})))
 	server.HandleFunc("/login", log(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		verifier := oauth2.GenerateVerifier()
-		redirect := githubOauthConfig.AuthCodeURL("", oauth2.S256ChallengeOption(verifier))
+		redirect := sessions.BeginOauth(writer, identityProvider.OauthConfig())
 		http.Redirect(writer, request, redirect, http.StatusTemporaryRedirect)
 	})))
 	server.HandleFunc("/logout", log(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		writer.Header().Set("Set-Cookie", "GobughuntJwt=; path=/; expires=Thu, 01 Jan 1970 00:00:00 GMT")
+		err := sessions.Revoke(writer, request)
+		if err != nil {
+			logging.Logger.Errorf("failed to revoke session: %v", err)
+		}
 		http.Redirect(writer, request, "/", http.StatusTemporaryRedirect)
 	})))
-	server.Handle("/lint-highlight/moderate", log(wrap(func(request *http.Request, writer http.ResponseWriter) (string, error) {
+	server.Handle("/lint-highlight/moderate", log(wrap(post(func(request *http.Request, writer http.ResponseWriter) (string, error) {
 		params := request.URL.Query()
 		lintId := params.Get("lintId")
 		if lintId == "" {
-			return "", fmt.Errorf("lintId required")
+			return "", invalidArgumentf("lintId required")
 		}
-		path := params.Get("path")
-		if path == "" {
-			return "", fmt.Errorf("path required")
+		fingerprint := params.Get("fingerprint")
+		if fingerprint == "" {
+			return "", invalidArgumentf("fingerprint required")
 		}
-		startLineString := params.Get("startLine")
-		if startLineString == "" {
-			return "", fmt.Errorf("startLine required")
+		status := params.Get("status")
+		if status != "accepted" && status != "rejected" {
+			return "", invalidArgumentf("unexpected status: %v", status)
+		}
+		comment := request.FormValue("comment")
+		status, err := apiController.LintHighlightModerate(request.Context(), lintId, fingerprint, status, comment)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`<div class="%v">%v</div>`, status, status), nil
+	}))))
+	server.Handle("/lint-highlight/revert", log(wrap(post(func(request *http.Request, writer http.ResponseWriter) (string, error) {
+		eventIdString := request.URL.Query().Get("eventId")
+		if eventIdString == "" {
+			return "", invalidArgumentf("eventId required")
+		}
+		eventId, err := strconv.ParseInt(eventIdString, 10, 64)
+		if err != nil {
+			return "", err
+		}
+		comment := request.FormValue("comment")
+		status, err := apiController.LintHighlightRevert(request.Context(), eventId, comment)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`<div class="%v">reverted to %v</div>`, status, status), nil
+	}))))
+	server.HandleFunc("/users", log(wrap(func(request *http.Request, writer http.ResponseWriter) (string, error) {
+		dtoUsers, err := apiController.Users(request.Context())
+		if err != nil {
+			return "", err
+		}
+		return RenderTemplate(usersTemplate, dtoUsers)
+	})))
+	server.Handle("/users/role", log(wrap(post(func(request *http.Request, writer http.ResponseWriter) (string, error) {
+		params := request.URL.Query()
+		login := params.Get("login")
+		if login == "" {
+			return "", invalidArgumentf("login required")
 		}
-		startLine, err := strconv.Atoi(startLineString)
+		role := params.Get("role")
+		if role == "" {
+			return "", invalidArgumentf("role required")
+		}
+		action := params.Get("action")
+		if action != "grant" && action != "revoke" {
+			return "", invalidArgumentf("unexpected action: %v", action)
+		}
+		err := apiController.UserRoleChange(request.Context(), login, role, action == "grant")
 		if err != nil {
 			return "", err
 		}
-		endLineString := params.Get("endLine")
-		if endLineString == "" {
-			return "", fmt.Errorf("endLine required")
+		return fmt.Sprintf(`<span>%vd</span>`, action), nil
+	}))))
+	server.HandleFunc("/linters", log(wrap(func(request *http.Request, writer http.ResponseWriter) (string, error) {
+		dtoLinters, err := apiController.Linters(request.Context())
+		if err != nil {
+			return "", err
 		}
-		endLine, err := strconv.Atoi(endLineString)
+		return RenderTemplate(lintersTemplate, dtoLinters)
+	})))
+	server.Handle("/linters/submit", log(wrap(post(func(request *http.Request, writer http.ResponseWriter) (string, error) {
+		linterId := request.FormValue("linterId")
+		if linterId == "" {
+			return "", invalidArgumentf("linterId required")
+		}
+		gitUrl := request.FormValue("gitUrl")
+		gitBranch := request.FormValue("gitBranch")
+		dockerImage := request.FormValue("dockerImage")
+		err := apiController.SubmitLinter(request.Context(), linterId, gitUrl, gitBranch, dockerImage)
 		if err != nil {
 			return "", err
 		}
+		return fmt.Sprintf(`<div class="pending">linter %v submitted: waiting for approval</div>`, template.HTMLEscapeString(linterId)), nil
+	}))))
+	server.Handle("/linters/status", log(wrap(post(func(request *http.Request, writer http.ResponseWriter) (string, error) {
+		params := request.URL.Query()
+		linterId := params.Get("linterId")
+		if linterId == "" {
+			return "", invalidArgumentf("linterId required")
+		}
 		status := params.Get("status")
-		if status != "accepted" && status != "rejected" {
-			return "", fmt.Errorf("unexpected status: %v", status)
+		if status != "active" && status != "paused" && status != "rejected" {
+			return "", invalidArgumentf("unexpected status: %v", status)
 		}
-		err = apiController.LintHighlightModerate(request.Context(), lintId, path, startLine, endLine, status)
+		err := apiController.LinterStatusChange(request.Context(), linterId, status)
 		if err != nil {
 			return "", err
 		}
 		return fmt.Sprintf(`<div class="%v">%v</div>`, status, status), nil
+	}))))
+	server.Handle("/linters/update", log(wrap(post(func(request *http.Request, writer http.ResponseWriter) (string, error) {
+		linterId := request.URL.Query().Get("linterId")
+		if linterId == "" {
+			return "", invalidArgumentf("linterId required")
+		}
+		gitUrl := request.FormValue("gitUrl")
+		gitBranch := request.FormValue("gitBranch")
+		dockerImage := request.FormValue("dockerImage")
+		err := apiController.UpdateLinterSource(request.Context(), linterId, gitUrl, gitBranch, dockerImage)
+		if err != nil {
+			return "", err
+		}
+		return `<div class="pending">new version will be picked up on the next iteration</div>`, nil
+	}))))
+	server.HandleFunc("/tokens", log(wrap(func(request *http.Request, writer http.ResponseWriter) (string, error) {
+		dtoTokens, err := apiController.ApiTokens(request.Context())
+		if err != nil {
+			return "", err
+		}
+		return RenderTemplate(tokensTemplate, dtoTokens)
 	})))
+	server.Handle("/tokens/create", log(wrap(post(func(request *http.Request, writer http.ResponseWriter) (string, error) {
+		name := request.FormValue("name")
+		if name == "" {
+			return "", invalidArgumentf("name required")
+		}
+		scope := request.FormValue("scope")
+		if scope == "" {
+			return "", invalidArgumentf("scope required")
+		}
+		token, err := apiController.CreateApiToken(request.Context(), name, scope)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`<div>copy the token now, it will not be shown again: <code>%v</code></div>`, token), nil
+	}))))
+	server.Handle("/tokens/revoke", log(wrap(post(func(request *http.Request, writer http.ResponseWriter) (string, error) {
+		tokenIdString := request.URL.Query().Get("tokenId")
+		if tokenIdString == "" {
+			return "", invalidArgumentf("tokenId required")
+		}
+		tokenId, err := strconv.ParseInt(tokenIdString, 10, 64)
+		if err != nil {
+			return "", invalidArgumentf("malformed tokenId: %v", tokenIdString)
+		}
+		err = apiController.RevokeApiToken(request.Context(), tokenId)
+		if err != nil {
+			return "", err
+		}
+		return `<span class="rejected">revoked</span>`, nil
+	}))))
 	server.HandleFunc("/oauth/callback", func(writer http.ResponseWriter, request *http.Request) {
+		verifier, err := sessions.FinishOauth(writer, request)
+		if err != nil {
+			logging.Logger.Errorf("failed to finish OAuth flow: %v", err)
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
 		code := request.URL.Query().Get("code")
 		if code == "" {
 			writer.WriteHeader(http.StatusUnauthorized)
 			return
 		}
-		token, err := githubOauthConfig.Exchange(request.Context(), code)
+		token, err := identityProvider.OauthConfig().Exchange(request.Context(), code, oauth2.VerifierOption(verifier))
 		if err != nil {
 			logging.Logger.Errorf("failed to get token from code: %v", err)
 			writer.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-		githubRequest, err := http.NewRequestWithContext(request.Context(), "GET", "https://api.github.com/user", nil)
+		identity, err := identityProvider.Identify(request.Context(), token)
 		if err != nil {
-			logging.Logger.Errorf("failed to create request to GitHub: %v", err)
+			logging.Logger.Errorf("failed to get identity of the user: %v", err)
 			writer.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-		githubRequest.Header.Set("Authorization", "Bearer "+token.AccessToken)
-		response, err := http.DefaultClient.Do(githubRequest)
-		if err != nil {
-			logging.Logger.Errorf("failed to exec request to GitHub: %v", err)
-			writer.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		data, err := io.ReadAll(response.Body)
-		if err != nil {
-			logging.Logger.Errorf("failed to read response from GitHub: %v", err)
-			writer.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		var user struct {
-			Login string `json:"login"`
-		}
-		err = json.Unmarshal(data, &user)
+		err = apiController.Login(request.Context(), identity)
 		if err != nil {
-			logging.Logger.Errorf("failed to unmarshal response from GitHub: %v", err)
+			logging.Logger.Errorf("failed to register user: %v", err)
 			writer.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-		jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user": user.Login})
-		signedJwtToken, err := jwtToken.SignedString(serverJwtSecretKey)
+		err = sessions.Start(writer, identity)
 		if err != nil {
-			logging.Logger.Errorf("failed sign JWT token: %v", err)
+			logging.Logger.Errorf("failed to start session: %v", err)
 			writer.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-		writer.Header().Set("Set-Cookie", "GobughuntJwt="+signedJwtToken+"; path=/")
 		http.Redirect(writer, request, "/", http.StatusTemporaryRedirect)
 	})
+	registerRestApi(server, apiController)
 	server.HandleFunc("/", log(wrap(func(request *http.Request, writer http.ResponseWriter) (string, error) {
 		dashboardDto, err := apiController.Dashboard(request.Context())
 		if err != nil {
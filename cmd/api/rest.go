@@ -0,0 +1,279 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+//go:embed openapi.json
+var openApiDocument []byte
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+	unlimitedPage    = math.MaxInt32 - 1 // HTML pages render the whole list at once
+)
+
+type ErrorDto struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"`
+}
+
+type PageDto[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"` // empty for the last page
+}
+
+type ModerateRequestDto struct {
+	LintId      string `json:"lintId"`
+	Fingerprint string `json:"fingerprint"`
+	Status      string `json:"status"`
+	Comment     string `json:"comment"`
+}
+
+type RevertRequestDto struct {
+	EventId int64  `json:"eventId"`
+	Comment string `json:"comment"`
+}
+
+type ModerateResponseDto struct {
+	Status string `json:"status"`
+}
+
+type SubmitLinterRequestDto struct {
+	Id          string `json:"id"`
+	GitUrl      string `json:"gitUrl"`
+	GitBranch   string `json:"gitBranch"`
+	DockerImage string `json:"dockerImage"` // image pinned by digest: image@sha256:<hash>
+}
+
+func writeJson(writer http.ResponseWriter, status int, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(value)
+}
+
+type jsonHandler func(request *http.Request) (any, error)
+
+// wrapJson is the JSON counterpart of wrap: handler is selected by the method of the request
+// and errors are reported as ErrorDto with the corresponding HTTP status
+func wrapJson(handlers map[string]jsonHandler) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		update, ok := basicAuth(writer, request)
+		if !ok {
+			return
+		}
+		request = update
+
+		handle, ok := handlers[request.Method]
+		if !ok {
+			writeError(writer, fmt.Errorf("%w: %v", ErrMethodNotAllowed, request.Method))
+			return
+		}
+		if request.Method != http.MethodGet {
+			err := sessions.CheckCsrf(request)
+			if err != nil {
+				writeError(writer, err)
+				return
+			}
+		}
+		result, err := handle(request)
+		if err != nil {
+			writeError(writer, err)
+			return
+		}
+		writeJson(writer, http.StatusOK, result)
+	}
+}
+
+func writeError(writer http.ResponseWriter, err error) {
+	status := httpStatus(err)
+	writeJson(writer, status, ErrorDto{Error: err.Error(), Status: status})
+}
+
+func decodeJson(request *http.Request, value any) error {
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(value)
+	if err != nil {
+		return invalidArgumentf("malformed request body: %v", err)
+	}
+	return nil
+}
+
+// pageCursor is the sort key of the last item of the previous page. Lists are ordered by the creation time and id of the items
+// (highlights also by their location in the lint) - so pages don't shift when items are added or moderated between the requests
+type pageCursor struct {
+	CreatedAt time.Time `json:"createdAt"`
+	Id        string    `json:"id"`
+	Path      string    `json:"path,omitempty"`
+	StartLine int32     `json:"startLine,omitempty"`
+	EndLine   int32     `json:"endLine,omitempty"`
+	// Fingerprint breaks ties between highlights reported at the same location
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+func (cursor pageCursor) createdAt() pgtype.Timestamp {
+	return pgtype.Timestamp{Time: cursor.CreatedAt, Valid: true}
+}
+
+func encodeCursor(cursor pageCursor) string {
+	encoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// parsePage extracts cursor and limit of the page: cursor is opaque for the clients and empty for the first page
+func parsePage(params url.Values) (pageCursor, int, error) {
+	limit := defaultPageLimit
+	if limitString := params.Get("limit"); limitString != "" {
+		parsed, err := strconv.Atoi(limitString)
+		if err != nil || parsed <= 0 || parsed > maxPageLimit {
+			return pageCursor{}, 0, invalidArgumentf("limit must be in range [1, %v]: %v", maxPageLimit, limitString)
+		}
+		limit = parsed
+	}
+	cursor := params.Get("cursor")
+	if cursor == "" {
+		return pageCursor{}, limit, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pageCursor{}, 0, invalidArgumentf("malformed cursor: %v", cursor)
+	}
+	var parsed pageCursor
+	err = json.Unmarshal(decoded, &parsed)
+	if err != nil || parsed.Id == "" {
+		return pageCursor{}, 0, invalidArgumentf("malformed cursor: %v", cursor)
+	}
+	return parsed, limit, nil
+}
+
+// cutPage cuts the page from the items fetched with one extra item - next cursor is set only if more items left
+func cutPage[T any](items []T, limit int, key func(T) pageCursor) ([]T, string) {
+	if len(items) <= limit {
+		return items, ""
+	}
+	return items[:limit], encodeCursor(key(items[limit-1]))
+}
+
+func registerRestApi(server *http.ServeMux, apiController ApiController) {
+	server.HandleFunc("/api/v1/openapi.json", log(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write(openApiDocument)
+	})))
+	server.HandleFunc("/api/v1/linters", log(wrapJson(map[string]jsonHandler{
+		http.MethodGet: func(request *http.Request) (any, error) {
+			after, limit, err := parsePage(request.URL.Query())
+			if err != nil {
+				return nil, err
+			}
+			return apiController.DashboardLinters(request.Context(), after, limit)
+		},
+		http.MethodPost: func(request *http.Request) (any, error) {
+			var submit SubmitLinterRequestDto
+			if err := decodeJson(request, &submit); err != nil {
+				return nil, err
+			}
+			err := apiController.SubmitLinter(request.Context(), submit.Id, submit.GitUrl, submit.GitBranch, submit.DockerImage)
+			if err != nil {
+				return nil, err
+			}
+			return LinterDto{Id: submit.Id, GitUrl: submit.GitUrl, GitBranch: submit.GitBranch, Status: "pending"}, nil
+		},
+	})))
+	server.HandleFunc("/api/v1/repos", log(wrapJson(map[string]jsonHandler{http.MethodGet: func(request *http.Request) (any, error) {
+		after, limit, err := parsePage(request.URL.Query())
+		if err != nil {
+			return nil, err
+		}
+		return apiController.DashboardRepos(request.Context(), after, limit)
+	}})))
+	server.HandleFunc("/api/v1/lint-tasks", log(wrapJson(map[string]jsonHandler{http.MethodGet: func(request *http.Request) (any, error) {
+		params := request.URL.Query()
+		before, limit, err := parsePage(params)
+		if err != nil {
+			return nil, err
+		}
+		tasks, err := apiController.LintTasks(request.Context(), params.Get("linterId"), before, limit)
+		if err != nil {
+			return nil, err
+		}
+		return PageDto[LintTaskDto]{Items: tasks.Tasks, NextCursor: tasks.NextCursor}, nil
+	}})))
+	server.HandleFunc("/api/v1/lint-highlights", log(wrapJson(map[string]jsonHandler{http.MethodGet: func(request *http.Request) (any, error) {
+		params := request.URL.Query()
+		after, limit, err := parsePage(params)
+		if err != nil {
+			return nil, err
+		}
+		filter, err := parseLintHighlightsFilter(params)
+		if err != nil {
+			return nil, err
+		}
+		highlights, err := apiController.LintHighlights(
+			request.Context(),
+			filter.LintId,
+			filter.RepoId,
+			filter.LinterId,
+			filter.RuleId,
+			filter.Severity,
+			filter.Status,
+			after,
+			limit,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return PageDto[LintHighlightDto]{Items: highlights.Highlights, NextCursor: highlights.NextCursor}, nil
+	}})))
+	server.HandleFunc("/api/v1/lint-highlights/moderate", log(wrapJson(map[string]jsonHandler{http.MethodPost: func(request *http.Request) (any, error) {
+		var moderate ModerateRequestDto
+		if err := decodeJson(request, &moderate); err != nil {
+			return nil, err
+		}
+		if moderate.LintId == "" || moderate.Fingerprint == "" {
+			return nil, invalidArgumentf("lintId and fingerprint required")
+		}
+		if moderate.Status != "accepted" && moderate.Status != "rejected" {
+			return nil, invalidArgumentf("unexpected status: %v", moderate.Status)
+		}
+		status, err := apiController.LintHighlightModerate(
+			request.Context(),
+			moderate.LintId,
+			moderate.Fingerprint,
+			moderate.Status,
+			moderate.Comment,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return ModerateResponseDto{Status: status}, nil
+	}})))
+	server.HandleFunc("/api/v1/lint-highlights/revert", log(wrapJson(map[string]jsonHandler{http.MethodPost: func(request *http.Request) (any, error) {
+		var revert RevertRequestDto
+		if err := decodeJson(request, &revert); err != nil {
+			return nil, err
+		}
+		if revert.EventId == 0 {
+			return nil, invalidArgumentf("eventId required")
+		}
+		status, err := apiController.LintHighlightRevert(request.Context(), revert.EventId, revert.Comment)
+		if err != nil {
+			return nil, err
+		}
+		return ModerateResponseDto{Status: status}, nil
+	}})))
+	server.HandleFunc("/api/v1/", log(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writeError(writer, fmt.Errorf("%w: %v", ErrNotFound, request.URL.Path))
+	})))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePage(t *testing.T) {
+	t.Run("first page", func(t *testing.T) {
+		cursor, limit, err := parsePage(url.Values{})
+		require.Nil(t, err)
+		require.Equal(t, pageCursor{}, cursor)
+		require.Equal(t, defaultPageLimit, limit)
+	})
+	t.Run("cursor", func(t *testing.T) {
+		expected := pageCursor{CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC), Id: "lint", Path: "a.go", StartLine: 1, EndLine: 2, Fingerprint: "fp"}
+		cursor, limit, err := parsePage(url.Values{"cursor": {encodeCursor(expected)}, "limit": {"10"}})
+		require.Nil(t, err)
+		require.Equal(t, expected, cursor)
+		require.Equal(t, 10, limit)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		for _, params := range []url.Values{
+			{"limit": {"0"}},
+			{"limit": {"1001"}},
+			{"cursor": {"!"}},
+			{"cursor": {"MTA"}}, // offset cursor of the previous API version
+			{"cursor": {encodeCursor(pageCursor{})}},
+		} {
+			_, _, err := parsePage(params)
+			require.ErrorIs(t, err, ErrInvalidArgument, params)
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/oauth2"
+
+	"github.com/sivukhin/gobughunt/lib"
+	"github.com/sivukhin/gobughunt/lib/logging"
+	"github.com/sivukhin/gobughunt/storage"
+	"github.com/sivukhin/gobughunt/storage/db"
+)
+
+const (
+	sessionCookie = "GobughuntJwt"
+	oauthCookie   = "GobughuntOauth"
+	csrfHeader    = "X-CSRF-Token"
+	oauthDuration = 10 * time.Minute
+)
+
+// SessionManager issues short-living JWT sessions which are transparently refreshed while user is active.
+// Every session has its own id, so /logout can revoke it on the server side even if someone copied the token
+type SessionManager struct {
+	Storage   storage.Queries
+	SecretKey []byte
+	Duration  time.Duration
+	Lifetime  time.Duration // refreshes never extend the session beyond that time since the login
+	Secure    bool          // set Secure flag for cookies (disabled for local development over http)
+}
+
+type sessionClaims struct {
+	User      string           `json:"user"`
+	Name      string           `json:"name,omitempty"` // display name of the user
+	StartedAt *jwt.NumericDate `json:"sat"`            // time of the login: refreshed tokens have their own iat
+	jwt.RegisteredClaims
+}
+
+func randomString() string {
+	value := make([]byte, 32)
+	_, _ = rand.Read(value) // crypto/rand never fails on supported platforms
+	return base64.RawURLEncoding.EncodeToString(value)
+}
+
+func (s SessionManager) setCookie(writer http.ResponseWriter, name, value string, expiresAt time.Time) {
+	http.SetCookie(writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   s.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (s SessionManager) issue(writer http.ResponseWriter, user, name, sessionId string, startedAt time.Time) error {
+	now := time.Now()
+	expiresAt := now.Add(s.Duration)
+	if s.Lifetime > 0 && expiresAt.After(startedAt.Add(s.Lifetime)) {
+		expiresAt = startedAt.Add(s.Lifetime)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, sessionClaims{
+		User:      user,
+		Name:      name,
+		StartedAt: jwt.NewNumericDate(startedAt),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        sessionId,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	signed, err := token.SignedString(s.SecretKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign JWT token: %w", err)
+	}
+	s.setCookie(writer, sessionCookie, signed, expiresAt)
+	return nil
+}
+
+// Start creates new session for the user who just logged in
+func (s SessionManager) Start(writer http.ResponseWriter, identity lib.Identity) error {
+	return s.issue(writer, identity.Login, identity.Name, randomString(), time.Now())
+}
+
+func (s SessionManager) parse(request *http.Request) (*sessionClaims, error) {
+	cookie, err := request.Cookie(sessionCookie)
+	if err != nil {
+		return nil, nil
+	}
+	var claims sessionClaims
+	_, err = jwt.ParseWithClaims(
+		cookie.Value,
+		&claims,
+		func(token *jwt.Token) (interface{}, error) { return s.SecretKey, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT token: %w", err)
+	}
+	if claims.User == "" || claims.ID == "" || claims.StartedAt == nil {
+		return nil, fmt.Errorf("JWT token has no user, session id or start time")
+	}
+	if s.Lifetime > 0 && time.Since(claims.StartedAt.Time) > s.Lifetime {
+		return nil, fmt.Errorf("session %v is older than %v", claims.ID, s.Lifetime)
+	}
+	revoked, err := s.Storage.IsSessionRevoked(request.Context(), claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check session revocation: %w", err)
+	}
+	if revoked {
+		return nil, fmt.Errorf("session %v was revoked", claims.ID)
+	}
+	return &claims, nil
+}
+
+// Authenticate puts user and session id into the request context - request stays anonymous if there is no valid session.
+// Session is refreshed when more than a half of its duration passed, but only until its lifetime is over: then user must log in again
+func (s SessionManager) Authenticate(writer http.ResponseWriter, request *http.Request) *http.Request {
+	claims, err := s.parse(request)
+	if err != nil {
+		logging.Logger.Errorf("invalid session: %v", err)
+		s.setCookie(writer, sessionCookie, "", time.Unix(0, 0))
+		return request
+	}
+	if claims == nil {
+		return request
+	}
+	renewable := s.Lifetime == 0 || claims.ExpiresAt.Time.Before(claims.StartedAt.Time.Add(s.Lifetime))
+	if renewable && time.Until(claims.ExpiresAt.Time) < s.Duration/2 {
+		err = s.issue(writer, claims.User, claims.Name, claims.ID, claims.StartedAt.Time)
+		if err != nil {
+			logging.Logger.Errorf("failed to refresh session: %v", err)
+		}
+	}
+	ctx := context.WithValue(request.Context(), "user", claims.User)
+	ctx = context.WithValue(ctx, "name", claims.Name)
+	ctx = context.WithValue(ctx, "session", claims.ID)
+	return request.WithContext(context.WithValue(ctx, "csrf", s.CsrfToken(claims.ID)))
+}
+
+// Revoke adds current session to the revocation list - so copies of the token will not be accepted anymore
+func (s SessionManager) Revoke(writer http.ResponseWriter, request *http.Request) error {
+	s.setCookie(writer, sessionCookie, "", time.Unix(0, 0))
+	claims, err := s.parse(request)
+	if err != nil || claims == nil {
+		return err
+	}
+	now := time.Now()
+	// any token of the session (even refreshed one) expires not later than that
+	err = s.Storage.RevokeSession(request.Context(), db.RevokeSessionParams{
+		SessionID: claims.ID,
+		UserLogin: claims.User,
+		RevokedAt: pgtype.Timestamp{Time: now, Valid: true},
+		ExpiresAt: pgtype.Timestamp{Time: now.Add(s.Duration), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	_, err = s.Storage.DeleteExpiredRevokedSessions(request.Context(), pgtype.Timestamp{Time: now, Valid: true})
+	if err != nil {
+		return fmt.Errorf("failed to delete expired revoked sessions: %w", err)
+	}
+	return nil
+}
+
+// CsrfToken is bound to the session: it is HMAC of the session id, so it can't be forged without the secret key
+func (s SessionManager) CsrfToken(sessionId string) string {
+	if sessionId == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, s.SecretKey)
+	mac.Write([]byte("csrf:" + sessionId))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// CheckCsrf validates CSRF token of the state-changing request authenticated by the session cookie
+func (s SessionManager) CheckCsrf(request *http.Request) error {
+	sessionId, _ := request.Context().Value("session").(string)
+	if sessionId == "" {
+		return nil // request is either anonymous or authenticated by API token which browser never attaches automatically
+	}
+	token := request.Header.Get(csrfHeader)
+	if token == "" || !hmac.Equal([]byte(token), []byte(s.CsrfToken(sessionId))) {
+		return fmt.Errorf("%w: invalid CSRF token", ErrAccessDenied)
+	}
+	return nil
+}
+
+// BeginOauth remembers state and PKCE verifier of the OAuth flow in the short-living cookie and returns redirect url
+func (s SessionManager) BeginOauth(writer http.ResponseWriter, config *oauth2.Config) string {
+	state, verifier := randomString(), oauth2.GenerateVerifier()
+	s.setCookie(writer, oauthCookie, state+"."+verifier, time.Now().Add(oauthDuration))
+	return config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+}
+
+// FinishOauth validates state of the OAuth callback and returns PKCE verifier for the code exchange
+func (s SessionManager) FinishOauth(writer http.ResponseWriter, request *http.Request) (string, error) {
+	cookie, err := request.Cookie(oauthCookie)
+	if err != nil {
+		return "", fmt.Errorf("OAuth flow was not started")
+	}
+	s.setCookie(writer, oauthCookie, "", time.Unix(0, 0))
+	state, verifier, ok := strings.Cut(cookie.Value, ".")
+	if !ok || state == "" || verifier == "" {
+		return "", fmt.Errorf("malformed OAuth cookie")
+	}
+	if !hmac.Equal([]byte(state), []byte(request.URL.Query().Get("state"))) {
+		return "", fmt.Errorf("OAuth state mismatch")
+	}
+	return verifier, nil
+}
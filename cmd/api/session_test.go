@@ -0,0 +1,159 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gobughunt/lib"
+	"github.com/sivukhin/gobughunt/storage"
+)
+
+func newTestSessions() SessionManager {
+	return SessionManager{Storage: storage.NewMemory(), SecretKey: []byte("secret"), Duration: time.Hour, Lifetime: 24 * time.Hour}
+}
+
+// sessionRequest returns request with the session cookie set by the response (if any)
+func sessionRequest(recorder *httptest.ResponseRecorder) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == sessionCookie && cookie.Value != "" {
+			request.AddCookie(cookie)
+		}
+	}
+	return request
+}
+
+// signedSession creates request with the session token issued in the past
+func signedSession(t *testing.T, s SessionManager, startedAt, expiresAt time.Time) *http.Request {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, sessionClaims{
+		User:      "42",
+		Name:      "sivukhin",
+		StartedAt: jwt.NewNumericDate(startedAt),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "session",
+			IssuedAt:  jwt.NewNumericDate(startedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}).SignedString(s.SecretKey)
+	require.Nil(t, err)
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.AddCookie(&http.Cookie{Name: sessionCookie, Value: token})
+	return request
+}
+
+func parseSession(t *testing.T, s SessionManager, recorder *httptest.ResponseRecorder) *sessionClaims {
+	claims, err := s.parse(sessionRequest(recorder))
+	require.Nil(t, err)
+	require.NotNil(t, claims)
+	return claims
+}
+
+func TestSessionAuthenticate(t *testing.T) {
+	t.Run("start", func(t *testing.T) {
+		s := newTestSessions()
+		recorder := httptest.NewRecorder()
+		require.Nil(t, s.Start(recorder, lib.Identity{Login: "42", Name: "sivukhin"}))
+
+		authenticated := s.Authenticate(httptest.NewRecorder(), sessionRequest(recorder))
+		require.Equal(t, "42", authenticated.Context().Value("user"))
+		require.Equal(t, "sivukhin", authenticated.Context().Value("name"))
+		require.NotEmpty(t, authenticated.Context().Value("session"))
+		require.NotEmpty(t, authenticated.Context().Value("csrf"))
+	})
+	t.Run("anonymous", func(t *testing.T) {
+		s := newTestSessions()
+		authenticated := s.Authenticate(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Nil(t, authenticated.Context().Value("user"))
+	})
+	t.Run("refresh", func(t *testing.T) {
+		s := newTestSessions()
+		startedAt := time.Now().Add(-2 * time.Hour)
+		recorder := httptest.NewRecorder()
+		authenticated := s.Authenticate(recorder, signedSession(t, s, startedAt, time.Now().Add(10*time.Minute)))
+		require.Equal(t, "42", authenticated.Context().Value("user"))
+
+		claims := parseSession(t, s, recorder)
+		require.Equal(t, "session", claims.ID)
+		require.Equal(t, "sivukhin", claims.Name)
+		require.Equal(t, startedAt.Unix(), claims.StartedAt.Unix())
+		require.WithinDuration(t, time.Now().Add(time.Hour), claims.ExpiresAt.Time, time.Minute)
+	})
+	t.Run("fresh session is not refreshed", func(t *testing.T) {
+		s := newTestSessions()
+		recorder := httptest.NewRecorder()
+		s.Authenticate(recorder, signedSession(t, s, time.Now(), time.Now().Add(time.Hour)))
+		require.Empty(t, recorder.Result().Cookies())
+	})
+	t.Run("refresh is capped by lifetime", func(t *testing.T) {
+		s := newTestSessions()
+		startedAt := time.Now().Add(-s.Lifetime + 20*time.Minute)
+		recorder := httptest.NewRecorder()
+		s.Authenticate(recorder, signedSession(t, s, startedAt, time.Now().Add(10*time.Minute)))
+		claims := parseSession(t, s, recorder)
+		require.Equal(t, startedAt.Add(s.Lifetime).Unix(), claims.ExpiresAt.Unix())
+
+		recorder = httptest.NewRecorder()
+		s.Authenticate(recorder, signedSession(t, s, startedAt, startedAt.Add(s.Lifetime)))
+		require.Empty(t, recorder.Result().Cookies())
+	})
+	t.Run("lifetime is over", func(t *testing.T) {
+		s := newTestSessions()
+		recorder := httptest.NewRecorder()
+		authenticated := s.Authenticate(recorder, signedSession(t, s, time.Now().Add(-s.Lifetime-time.Minute), time.Now().Add(10*time.Minute)))
+		require.Nil(t, authenticated.Context().Value("user"))
+		require.Len(t, recorder.Result().Cookies(), 1)
+		require.Empty(t, recorder.Result().Cookies()[0].Value)
+	})
+	t.Run("wrong secret", func(t *testing.T) {
+		s := newTestSessions()
+		request := signedSession(t, SessionManager{SecretKey: []byte("other")}, time.Now(), time.Now().Add(time.Hour))
+		authenticated := s.Authenticate(httptest.NewRecorder(), request)
+		require.Nil(t, authenticated.Context().Value("user"))
+	})
+}
+
+func TestSessionRevoke(t *testing.T) {
+	s := newTestSessions()
+	recorder := httptest.NewRecorder()
+	require.Nil(t, s.Start(recorder, lib.Identity{Login: "42", Name: "sivukhin"}))
+	// copy of the token which stays in the hands of someone else after logout
+	stolen := sessionRequest(recorder)
+
+	logout := httptest.NewRecorder()
+	require.Nil(t, s.Revoke(logout, sessionRequest(recorder)))
+	require.Empty(t, logout.Result().Cookies()[0].Value)
+
+	authenticated := s.Authenticate(httptest.NewRecorder(), stolen)
+	require.Nil(t, authenticated.Context().Value("user"))
+
+	// other sessions of the user are not affected
+	other := httptest.NewRecorder()
+	require.Nil(t, s.Start(other, lib.Identity{Login: "42", Name: "sivukhin"}))
+	authenticated = s.Authenticate(httptest.NewRecorder(), sessionRequest(other))
+	require.Equal(t, "42", authenticated.Context().Value("user"))
+}
+
+func TestSessionCheckCsrf(t *testing.T) {
+	s := newTestSessions()
+	recorder := httptest.NewRecorder()
+	require.Nil(t, s.Start(recorder, lib.Identity{Login: "42", Name: "sivukhin"}))
+	authenticated := s.Authenticate(httptest.NewRecorder(), sessionRequest(recorder))
+	sessionId := authenticated.Context().Value("session").(string)
+
+	require.Nil(t, s.CheckCsrf(httptest.NewRequest(http.MethodPost, "/", nil)))
+	require.ErrorIs(t, s.CheckCsrf(authenticated), ErrAccessDenied)
+
+	authenticated.Header.Set(csrfHeader, s.CsrfToken("other"))
+	require.ErrorIs(t, s.CheckCsrf(authenticated), ErrAccessDenied)
+
+	authenticated.Header.Set(csrfHeader, SessionManager{SecretKey: []byte("other")}.CsrfToken(sessionId))
+	require.ErrorIs(t, s.CheckCsrf(authenticated), ErrAccessDenied)
+
+	authenticated.Header.Set(csrfHeader, s.CsrfToken(sessionId))
+	require.Nil(t, s.CheckCsrf(authenticated))
+}
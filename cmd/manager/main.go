@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"os"
 	"syscall"
 
 	"github.com/sivukhin/gobughunt/lib"
@@ -15,6 +16,10 @@ func main() {
 	var (
 		connectionDuration  = utils.EnvMustParseDurationSec("CONNECTION_DURATION_SEC")
 		connectionString    = utils.EnvMustParseString("CONNECTION_STRING")
+		skipMigrations      = utils.EnvTryParseBool("SKIP_MIGRATIONS")
+		dockerRegistry      = os.Getenv("MANAGER_DOCKER_REGISTRY")
+		dockerRegistryAuth  = os.Getenv("MANAGER_DOCKER_REGISTRY_AUTH")
+		buildTimeout        = utils.EnvMustParseDurationSec("MANAGER_BUILD_TIMEOUT_SEC")
 		fetchTimeout        = utils.EnvMustParseDurationSec("MANAGER_FETCH_TIMEOUT_SEC")
 		refreshTimeout      = utils.EnvMustParseDurationSec("MANAGER_REFRESH_TIMEOUT_SEC")
 		scheduleTimeout     = utils.EnvMustParseDurationSec("MANAGER_SCHEDULE_TIMEOUT_SEC")
@@ -25,15 +30,31 @@ func main() {
 	defer cancel()
 	signalsCtx := timeout.SignalsCtx(syscall.SIGTERM, syscall.SIGKILL)
 
-	pgStorage, err := storage.NewPgQueries(connectCtx, connectionString)
+	pgPool, err := storage.NewPgStorage(connectCtx, connectionString)
 	if err != nil {
 		logging.Logger.Fatalf("failed to create task storage: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = storage.Migrate(connectCtx, pgPool, storage.Migrations())
+		if err != nil {
+			logging.Logger.Fatalf("failed to migrate database: %v", err)
+		}
+		return
+	}
+	err = storage.PrepareSchema(connectCtx, pgPool, skipMigrations)
+	if err != nil {
+		logging.Logger.Fatalf("failed to prepare database schema: %v", err)
+	}
+
 	manager := lib.Manager{
-		Storage:             pgStorage,
-		DockerApi:           lib.Docker,
+		Storage: storage.NewPostgres(pgPool),
+		DockerApi: lib.NaiveDockerApi{
+			RegistryAuth: dockerRegistryAuth,
+		},
 		GitApi:              lib.Git,
+		DockerRegistry:      dockerRegistry,
+		BuildTimeout:        buildTimeout,
 		FetchTimeout:        fetchTimeout,
 		RefreshTimeout:      refreshTimeout,
 		ScheduleTimeout:     scheduleTimeout,
@@ -1 +1 @@
-TRUNCATE TABLE lint_highlights;
\ No newline at end of file
+TRUNCATE TABLE lint_highlights, highlight_tracks;
\ No newline at end of file
@@ -1,4 +1,4 @@
 SELECT *
 FROM lint_tasks
-WHERE lint_status = 'pending'
+WHERE lint_status IN ('pending', 'running')
 ORDER BY created_at;
\ No newline at end of file
@@ -1,6 +1,9 @@
 UPDATE lint_tasks
-SET lint_status = 'pending',
-    locked_at   = NULL
+SET lint_status     = 'pending',
+    lint_attempts   = 0,
+    next_attempt_at = NULL,
+    locked_at       = NULL,
+    locked_by       = NULL
 WHERE lint_id = $1;
 
 --- DELIMITER ---
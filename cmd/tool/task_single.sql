@@ -8,6 +8,6 @@ SELECT lint_id,
        locked_at
 FROM lint_tasks
 WHERE lint_status = 'pending'
-  AND (locked_at IS NULL)
+  AND (next_attempt_at IS NULL OR next_attempt_at <= now())
 ORDER BY created_at
 LIMIT 1
\ No newline at end of file
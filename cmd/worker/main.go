@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"syscall"
 
 	"github.com/sivukhin/gobughunt/lib"
@@ -15,39 +17,74 @@ func main() {
 	var (
 		connectionDuration = utils.EnvMustParseDurationSec("CONNECTION_DURATION_SEC")
 		connectionString   = utils.EnvMustParseString("CONNECTION_STRING")
+		skipMigrations     = utils.EnvTryParseBool("SKIP_MIGRATIONS")
 		iterationDelay     = utils.EnvMustParseDurationSec("WORKER_ITERATION_DELAY_SEC")
 		cleanupTimeout     = utils.EnvMustParseDurationSec("WORKER_CLEANUP_TIMEOUT_SEC")
 		takeTimeout        = utils.EnvMustParseDurationSec("WORKER_TAKE_TIMEOUT_SEC")
 		lintTimeout        = utils.EnvMustParseDurationSec("WORKER_LINT_TIMEOUT_SEC")
 		updateTimeout      = utils.EnvMustParseDurationSec("WORKER_UPDATE_TIMEOUT_SEC")
 		lockDuration       = utils.EnvMustParseDurationSec("WORKER_TASK_LOCK_DURATION_SEC")
+		maxAttempts        = utils.EnvMustParseInt("WORKER_MAX_ATTEMPTS")
+		retryDelay         = utils.EnvMustParseDurationSec("WORKER_RETRY_DELAY_SEC")
+		concurrency        = utils.EnvMustParseInt("WORKER_CONCURRENCY")
+		totalMemoryGb      = utils.EnvMustParseInt("WORKER_TOTAL_MEMORY_GB")
+		totalCpuMillis     = utils.EnvMustParseInt("WORKER_TOTAL_CPU_MILLIS")
 		dockerMemoryGb     = utils.EnvMustParseInt("DOCKER_MEMORY_GB")
 		dockerCpuMillis    = utils.EnvMustParseInt("DOCKER_CPU_MILLIS")
+		dockerLogKb        = utils.EnvMustParseInt("DOCKER_LOG_KB")
+		dockerMaxOutputMb  = utils.EnvMustParseInt("DOCKER_MAX_OUTPUT_MB")
+		dockerMaxLineKb    = utils.EnvMustParseInt("DOCKER_MAX_LINE_KB")
+		maxHighlights      = utils.EnvMustParseInt("WORKER_MAX_HIGHLIGHTS")
 		dockerTempDir      = utils.EnvMustParseString("DOCKER_TEMP_DIR")
 	)
 	connectCtx, cancel := context.WithTimeout(context.Background(), connectionDuration)
 	defer cancel()
 	signalsCtx := timeout.SignalsCtx(syscall.SIGTERM, syscall.SIGKILL)
 
-	pgStorage, err := storage.NewPgQueries(connectCtx, connectionString)
+	pgPool, err := storage.NewPgStorage(connectCtx, connectionString)
 	if err != nil {
 		logging.Logger.Fatalf("failed to create task storage: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = storage.Migrate(connectCtx, pgPool, storage.Migrations())
+		if err != nil {
+			logging.Logger.Fatalf("failed to migrate database: %v", err)
+		}
+		return
+	}
+	err = storage.PrepareSchema(connectCtx, pgPool, skipMigrations)
+	if err != nil {
+		logging.Logger.Fatalf("failed to prepare database schema: %v", err)
+	}
+
+	dockerApi := lib.NaiveDockerApi{
+		MemoryBytes: dockerMemoryGb * 1024 * 1024 * 1024,
+		CpuMilli:    dockerCpuMillis,
+		PidLimit:    16 * 1024,
+		LogBytes:    int(dockerLogKb) * 1024,
+
+		MaxOutputBytes: dockerMaxOutputMb * 1024 * 1024,
+		MaxLineBytes:   int(dockerMaxLineKb) * 1024,
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		logging.Logger.Fatalf("failed to get hostname: %v", err)
+	}
 	worker := lib.Worker{
-		Storage: pgStorage,
-		DockerApi: lib.NaiveDockerApi{
-			MemoryBytes: dockerMemoryGb * 1024 * 1024 * 1024,
-			CpuMilli:    dockerCpuMillis,
-			PidLimit:    16 * 1024,
-		},
-		Linting:        lib.NaiveLinting{TempDir: dockerTempDir, DockerApi: lib.Docker, GitApi: lib.Git},
+		Id:             fmt.Sprintf("%v-%v", hostname, os.Getpid()),
+		Storage:        storage.NewPostgres(pgPool),
+		DockerApi:      dockerApi,
+		Linting:        lib.NaiveLinting{TempDir: dockerTempDir, DockerApi: dockerApi, GitApi: lib.Git, MaxHighlights: int(maxHighlights)},
+		Concurrency:    lib.WorkerSlots(int(concurrency), totalMemoryGb*1024*1024*1024, totalCpuMillis, dockerApi),
 		IterationDelay: iterationDelay,
 		CleanupTimeout: cleanupTimeout,
 		TakeTimeout:    takeTimeout,
 		LintTimeout:    lintTimeout,
 		UpdateTimeout:  updateTimeout,
 		LockDuration:   lockDuration,
+		MaxAttempts:    int(maxAttempts),
+		RetryDelay:     retryDelay,
 	}
 	worker.RunForever(signalsCtx)
 }
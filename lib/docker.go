@@ -1,32 +1,52 @@
 package lib
 
 import (
+	"archive/tar"
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
+	"github.com/docker/go-units"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/sivukhin/gobughunt/lib/dto"
 	"github.com/sivukhin/gobughunt/lib/logging"
+	"github.com/sivukhin/gobughunt/lib/utils"
 )
 
 type DockerApi interface {
 	Cleanup(ctx context.Context) error
-	Exec(ctx context.Context, dockerImage string, containerBindPath, localBindPath string) ([]string, error)
+	Exec(ctx context.Context, dockerImage string, containerBindPath, localBindPath string) (DockerExecResult, error)
+	// Build builds image from the Dockerfile located at the localContextPath, pushes it to the registry and returns sha256 digest of the pushed image
+	Build(ctx context.Context, dockerImage string, localContextPath string) (string, error)
 }
 
 type NaiveDockerApi struct {
-	MemoryBytes int64
-	CpuMilli    int64
-	PidLimit    int64
+	MemoryBytes  int64
+	CpuMilli     int64
+	PidLimit     int64
+	LogBytes     int    // every output stream of the container is stored up to this size in the result of Exec
+	RegistryAuth string // base64 encoded credentials for the registry used in Build
+	// container is killed as soon as its output exceeds one of the limits - Exec returns lines read so far with DockerOutputLimitErr
+	MaxOutputBytes int64
+	MaxLineBytes   int
 }
 
 // Docker reasonable defaults
@@ -34,22 +54,91 @@ var Docker DockerApi = NaiveDockerApi{
 	MemoryBytes: 4 * 1024 * 1024 * 1024, // 4 GiB
 	CpuMilli:    4 * 1000,               // 4 CPU
 	PidLimit:    1024,                   // 1024 processes
+	LogBytes:    1024 * 1024,            // 1 MiB
+
+	MaxOutputBytes: 64 * 1024 * 1024, // 64 MiB
+	MaxLineBytes:   1024 * 1024,      // 1 MiB
+}
+
+var (
+	DockerNonZeroExitCodeErr = errors.New("non zero exit code")
+	DockerOOMKilledErr       = errors.New("container killed by out of memory")
+	DockerDeadlineErr        = errors.New("container exceeded deadline")
+	DockerOutputLimitErr     = errors.New("container exceeded output limit")
+	DockerBuildErr           = errors.New("docker build failed")
+)
+
+// DockerExitCodeErr describes the state of the container which finished with non-zero exit code.
+// errors.Is(err, DockerNonZeroExitCodeErr) holds for it, and errors.Is(err, DockerOOMKilledErr) holds if container was OOM-killed
+type DockerExitCodeErr struct {
+	ExitCode    int64
+	OOMKilled   bool
+	MemoryLimit int64 // memory limit of the container in bytes, zero if unlimited
+}
+
+// Signal returns the signal which terminated the container - by convention exit code is 128+signal in that case
+func (e DockerExitCodeErr) Signal() syscall.Signal {
+	if e.ExitCode > 128 && e.ExitCode < 128+65 {
+		return syscall.Signal(e.ExitCode - 128)
+	}
+	return 0
+}
+
+func (e DockerExitCodeErr) Error() string {
+	message := fmt.Sprintf("%v: %v", DockerNonZeroExitCodeErr, e.ExitCode)
+	if signal := e.Signal(); signal != 0 {
+		message += fmt.Sprintf(" (signal: %v)", signal)
+	}
+	if e.OOMKilled && e.MemoryLimit > 0 {
+		message += fmt.Sprintf(": %v: limit is %v", DockerOOMKilledErr, units.BytesSize(float64(e.MemoryLimit)))
+	} else if e.OOMKilled {
+		message += fmt.Sprintf(": %v", DockerOOMKilledErr)
+	}
+	return message
 }
 
-var DockerNonZeroExitCodeErr = errors.New("non zero exit code")
+func (e DockerExitCodeErr) Unwrap() []error {
+	if e.OOMKilled {
+		return []error{DockerNonZeroExitCodeErr, DockerOOMKilledErr}
+	}
+	return []error{DockerNonZeroExitCodeErr}
+}
+
+// DockerExecResult is the output of the container together with resources it consumed - it's returned even if Exec failed.
+// Lines contain both stdout and stderr of the container, while Logs keep them apart
+type DockerExecResult struct {
+	Lines []string
+	Logs  dto.LintLogs
+	Usage dto.ResourceUsage
+}
+
+// ContainerLabel marks containers created by Exec - Cleanup never touches containers without this label
+const ContainerLabel = "gobughunt"
+
+// buildCacheMaxAge protects fresh build cache from Cleanup - builds run in other processes which pruneLock can't guard
+const buildCacheMaxAge = 24 * time.Hour
+
+// pruneLock is held for reading by Exec until the container is started: pulled image and created container are unused till then,
+// so prune would delete them. Cleanup holds it for writing - prune waits for the starting containers and new ones wait for the prune
+var pruneLock sync.RWMutex
 
 func (_ NaiveDockerApi) Cleanup(ctx context.Context) error {
 	cli, err := client.NewClientWithOpts(client.FromEnv)
 	if err != nil {
 		return fmt.Errorf("unable to create docker client: %w", err)
 	}
-	containerReport, err := cli.ContainersPrune(ctx, filters.NewArgs())
+	pruneLock.Lock()
+	defer pruneLock.Unlock()
+	containerReport, err := cli.ContainersPrune(ctx, filters.NewArgs(filters.Arg("label", ContainerLabel)))
 	if err != nil {
 		return err
 	}
 	logging.Logger.Infof("containers pruned: reclaimed %v bytes", containerReport.SpaceReclaimed)
 
-	cacheReport, err := cli.BuildCachePrune(ctx, types.BuildCachePruneOptions{All: true})
+	cacheReport, err := cli.BuildCachePrune(ctx, types.BuildCachePruneOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("until", buildCacheMaxAge.String())),
+	})
 	if err != nil {
 		return err
 	}
@@ -73,15 +162,89 @@ func (d NaiveDockerApi) Exec(
 	ctx context.Context,
 	dockerImage string,
 	containerBindPath, localBindPath string,
-) ([]string, error) {
+) (DockerExecResult, error) {
 	cli, err := client.NewClientWithOpts(client.FromEnv)
 	if err != nil {
-		return nil, fmt.Errorf("unable to create docker client: %w", err)
+		return DockerExecResult{}, fmt.Errorf("unable to create docker client: %w", err)
 	}
 	logging.Logger.Infof("ready to exec docker image %v", dockerImage)
+	pruneLock.RLock()
+	containerId, attach, err := d.startContainer(ctx, cli, dockerImage, containerBindPath, localBindPath)
+	pruneLock.RUnlock()
+	if containerId != "" {
+		defer func() {
+			killCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // todo (sivukhin, 2024-02-11): how to avoid this hard-coded timeout,
+			defer cancel()
+			_ = cli.ContainerKill(killCtx, containerId, "SIGKILL") // cleanup - we can ignore error
+			// remove container right away because concurrent Cleanup must not be responsible for containers of running lints
+			_ = cli.ContainerRemove(killCtx, containerId, types.ContainerRemoveOptions{Force: true})
+		}()
+	}
+	if err != nil {
+		return DockerExecResult{}, err
+	}
+	// stats stream ends together with the container, but we also stop it explicitly if wait failed
+	statsCtx, stopStats := context.WithCancel(ctx)
+	defer stopStats()
+	var usage dto.ResourceUsage
+	statsDone := make(chan struct{})
+	go func() {
+		defer close(statsDone)
+		var err error
+		usage, err = collectStats(statsCtx, cli, containerId)
+		if err != nil {
+			logging.Logger.Errorf("unable to collect stats of container %v: %v", containerId, err)
+		}
+	}()
+
+	var lines []string
+	stdout, stderr := &logBuffer{limit: d.LogBytes}, &logBuffer{limit: d.LogBytes}
+	waitCtx, stopWait := context.WithCancelCause(ctx)
+	defer stopWait(nil)
+	var errGroup errgroup.Group
+	errGroup.Go(func() error {
+		var err error
+		lines, err = d.readOutput(&DockerStreamReader{Reader: attach.Reader, Stdout: stdout, Stderr: stderr})
+		if errors.Is(err, DockerOutputLimitErr) {
+			stopWait(err) // nobody reads the output anymore - so we stop waiting and container is killed right away
+			return err
+		} else if err != nil {
+			return fmt.Errorf("unable to read stdout of container %v: %w", containerId, err)
+		}
+		return nil
+	})
+
+	statusC, errC := cli.ContainerWait(waitCtx, containerId, container.WaitConditionNotRunning)
+	select {
+	case status := <-statusC:
+		if status.StatusCode != 0 {
+			err = d.exitCodeErr(cli, containerId, status.StatusCode)
+		}
+	case err = <-errC:
+		if errors.Is(context.Cause(waitCtx), DockerOutputLimitErr) {
+			err = nil // limit violation is reported by the reader of the output
+		} else if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("%w: %w", DockerDeadlineErr, err)
+		}
+	}
+	attach.Close()
+	err = errors.Join(err, errGroup.Wait())
+	stopStats()
+	<-statsDone
+	logs := dto.LintLogs{Stdout: stdout.data, Stderr: stderr.data, StdoutBytes: stdout.total, StderrBytes: stderr.total}
+	return DockerExecResult{Lines: lines, Logs: logs, Usage: usage}, err
+}
+
+// startContainer pulls the image and starts the container attached to its output - id of the container is returned even if start failed
+func (d NaiveDockerApi) startContainer(
+	ctx context.Context,
+	cli *client.Client,
+	dockerImage string,
+	containerBindPath, localBindPath string,
+) (string, types.HijackedResponse, error) {
 	pull, err := cli.ImagePull(ctx, dockerImage, types.ImagePullOptions{All: true})
 	if err != nil {
-		return nil, fmt.Errorf("unable to pull docker image %v: %w", dockerImage, err)
+		return "", types.HijackedResponse{}, fmt.Errorf("unable to pull docker image %v: %w", dockerImage, err)
 	}
 	for {
 		n, err := io.Copy(io.Discard, pull)
@@ -89,12 +252,16 @@ func (d NaiveDockerApi) Exec(
 			break
 		} else if err != nil {
 			_ = pull.Close()
-			return nil, fmt.Errorf("unable to pull docker image %v: %w", dockerImage, err)
+			return "", types.HijackedResponse{}, fmt.Errorf("unable to pull docker image %v: %w", dockerImage, err)
 		}
 	}
 	_ = pull.Close()
 
-	containerConfig := &container.Config{Image: dockerImage, Cmd: []string{containerBindPath}}
+	containerConfig := &container.Config{
+		Image:  dockerImage,
+		Cmd:    []string{containerBindPath},
+		Labels: map[string]string{ContainerLabel: "lint"},
+	}
 	hostConfig := &container.HostConfig{
 		Binds: []string{fmt.Sprintf("%v:%v", localBindPath, containerBindPath)},
 		Resources: container.Resources{
@@ -106,13 +273,8 @@ func (d NaiveDockerApi) Exec(
 	}
 	create, err := cli.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, "")
 	if err != nil {
-		return nil, fmt.Errorf("unable to create container for image %v: %w", dockerImage, err)
+		return "", types.HijackedResponse{}, fmt.Errorf("unable to create container for image %v: %w", dockerImage, err)
 	}
-	defer func() {
-		killCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // todo (sivukhin, 2024-02-11): how to avoid this hard-coded timeout,
-		defer cancel()
-		_ = cli.ContainerKill(killCtx, create.ID, "SIGKILL") // cleanup - we can ignore error
-	}()
 	attach, err := cli.ContainerAttach(ctx, create.ID, types.ContainerAttachOptions{
 		Stream: true,
 		Stderr: true,
@@ -120,43 +282,262 @@ func (d NaiveDockerApi) Exec(
 	})
 	// we want to take control over attached container - so we will manually call attach.Close() when we want to exit (context canceled or container succeeded)
 	if err != nil {
-		return nil, fmt.Errorf("unable to attach to container %v: %w", create.ID, err)
+		return create.ID, types.HijackedResponse{}, fmt.Errorf("unable to attach to container %v: %w", create.ID, err)
 	}
 	err = cli.ContainerStart(ctx, create.ID, types.ContainerStartOptions{})
 	if err != nil {
 		attach.Close()
-		return nil, fmt.Errorf("unable to start container %v: %w", create.ID, err)
+		return create.ID, types.HijackedResponse{}, fmt.Errorf("unable to start container %v: %w", create.ID, err)
 	}
+	return create.ID, attach, nil
+}
+
+// readOutput splits output of the container into lines until output exceeds the limits
+func (d NaiveDockerApi) readOutput(reader io.Reader) ([]string, error) {
+	maxLineBytes := utils.Ternary(d.MaxLineBytes > 0, d.MaxLineBytes, bufio.MaxScanTokenSize)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, min(maxLineBytes, 4096)), maxLineBytes)
 	lines := make([]string, 0)
-	var errGroup errgroup.Group
-	errGroup.Go(func() error {
-		scanner := bufio.NewScanner(&DockerStreamReader{Reader: attach.Reader})
-		for scanner.Scan() {
-			lines = append(lines, scanner.Text())
+	var outputBytes int64
+	for scanner.Scan() {
+		outputBytes += int64(len(scanner.Bytes())) + 1 // count line separator too
+		if d.MaxOutputBytes > 0 && outputBytes > d.MaxOutputBytes {
+			return lines, fmt.Errorf("%w: output is larger than %v", DockerOutputLimitErr, units.BytesSize(float64(d.MaxOutputBytes)))
 		}
-		if err := scanner.Err(); err != nil {
-			return fmt.Errorf("unable to read stdout of container %v: %w", create.ID, err)
+		lines = append(lines, scanner.Text())
+	}
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return lines, fmt.Errorf("%w: line is longer than %v", DockerOutputLimitErr, units.BytesSize(float64(maxLineBytes)))
+	}
+	return lines, scanner.Err()
+}
+
+// logBuffer keeps the head of the stream up to the limit and counts the size of the whole stream
+type logBuffer struct {
+	limit int
+	data  []byte
+	total int64
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.total += int64(len(p))
+	if rest := b.limit - len(b.data); rest > 0 {
+		b.data = append(b.data, p[:min(rest, len(p))]...)
+	}
+	return len(p), nil
+}
+
+// collectStats samples stats of the container until it stops - cgroup counters are cumulative, so we keep the latest values of them
+func collectStats(ctx context.Context, cli *client.Client, containerId string) (dto.ResourceUsage, error) {
+	var usage dto.ResourceUsage
+	stats, err := cli.ContainerStats(ctx, containerId, true)
+	if err != nil {
+		return usage, err
+	}
+	defer stats.Body.Close()
+	decoder := json.NewDecoder(stats.Body)
+	for {
+		var sample types.StatsJSON
+		err := decoder.Decode(&sample)
+		if errors.Is(err, io.EOF) || ctx.Err() != nil {
+			return usage, nil
+		} else if err != nil {
+			return usage, err
 		}
-		return nil
+		usage = AddStatsSample(usage, sample)
+	}
+}
+
+// AddStatsSample accumulates single stats sample of the container: both cgroup v1 and v2 stats layouts are supported
+func AddStatsSample(usage dto.ResourceUsage, sample types.StatsJSON) dto.ResourceUsage {
+	if sample.Read.IsZero() {
+		return usage // docker sends empty sample for the container which isn't running anymore
+	}
+	usage.Samples++
+	usage.CpuSeconds = max(usage.CpuSeconds, float64(sample.CPUStats.CPUUsage.TotalUsage)/float64(time.Second))
+	rss, ok := sample.MemoryStats.Stats["rss"] // cgroup v1
+	if !ok {
+		rss, ok = sample.MemoryStats.Stats["anon"] // cgroup v2
+	}
+	if !ok {
+		rss = sample.MemoryStats.Usage
+	}
+	usage.MaxRssBytes = max(usage.MaxRssBytes, int64(rss))
+	var ioBytes uint64
+	for _, entry := range sample.BlkioStats.IoServiceBytesRecursive {
+		if op := strings.ToLower(entry.Op); op == "read" || op == "write" {
+			ioBytes += entry.Value
+		}
+	}
+	usage.IoBytes = max(usage.IoBytes, int64(ioBytes))
+	usage.MaxPids = max(usage.MaxPids, int64(sample.PidsStats.Current))
+	return usage
+}
+
+// exitCodeErr inspects state of the finished container in order to tell OOM kill from the ordinary failure
+func (d NaiveDockerApi) exitCodeErr(cli *client.Client, containerId string, exitCode int64) error {
+	exitErr := DockerExitCodeErr{ExitCode: exitCode, MemoryLimit: d.MemoryBytes}
+	// container is already stopped, so inspect must not depend on the (possibly expired) lint deadline
+	inspectCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	inspect, err := cli.ContainerInspect(inspectCtx, containerId)
+	if err != nil {
+		logging.Logger.Errorf("unable to inspect container %v: %v", containerId, err)
+		return exitErr
+	}
+	if inspect.State != nil {
+		exitErr.OOMKilled = inspect.State.OOMKilled
+	}
+	return exitErr
+}
+
+func (d NaiveDockerApi) Build(ctx context.Context, dockerImage string, localContextPath string) (string, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return "", fmt.Errorf("unable to create docker client: %w", err)
+	}
+	buildContext, err := archiveDirectory(localContextPath)
+	if err != nil {
+		return "", fmt.Errorf("unable to archive build context %v: %w", localContextPath, err)
+	}
+	logging.Logger.Infof("ready to build docker image %v from %v", dockerImage, localContextPath)
+	build, err := cli.ImageBuild(ctx, buildContext, types.ImageBuildOptions{
+		Tags:        []string{dockerImage},
+		Remove:      true,
+		ForceRemove: true,
+		PullParent:  true,
 	})
+	if err != nil {
+		return "", fmt.Errorf("unable to build docker image %v: %w", dockerImage, err)
+	}
+	err = readDockerJsonMessages(build.Body, nil)
+	_ = build.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("%w: image %v: %w", DockerBuildErr, dockerImage, err)
+	}
 
-	statusC, errC := cli.ContainerWait(ctx, create.ID, container.WaitConditionNotRunning)
-	select {
-	case status := <-statusC:
-		if status.StatusCode != 0 {
-			err = fmt.Errorf("%w: %v", DockerNonZeroExitCodeErr, status.StatusCode)
+	push, err := cli.ImagePush(ctx, dockerImage, types.ImagePushOptions{RegistryAuth: d.RegistryAuth})
+	if err != nil {
+		return "", fmt.Errorf("unable to push docker image %v: %w", dockerImage, err)
+	}
+	var digest string
+	err = readDockerJsonMessages(push, func(aux json.RawMessage) {
+		var pushResult struct {
+			Digest string `json:"Digest"`
 		}
-	case err = <-errC:
+		if json.Unmarshal(aux, &pushResult) == nil && pushResult.Digest != "" {
+			digest = pushResult.Digest
+		}
+	})
+	_ = push.Close()
+	if err != nil {
+		return "", fmt.Errorf("unable to push docker image %v: %w", dockerImage, err)
 	}
-	attach.Close()
-	return lines, errors.Join(err, errGroup.Wait())
+	shaHash, ok := strings.CutPrefix(digest, "sha256:")
+	if !ok {
+		return "", fmt.Errorf("unexpected digest of pushed docker image %v: '%v'", dockerImage, digest)
+	}
+	logging.Logger.Infof("docker image %v built and pushed: sha256=%v", dockerImage, shaHash)
+	return shaHash, nil
 }
 
+// readDockerJsonMessages consumes stream of JSON messages produced by build/push Docker API and returns first reported error
+func readDockerJsonMessages(reader io.Reader, onAux func(aux json.RawMessage)) error {
+	decoder := json.NewDecoder(reader)
+	for {
+		var message struct {
+			Error       string `json:"error"`
+			ErrorDetail *struct {
+				Message string `json:"message"`
+			} `json:"errorDetail"`
+			Aux json.RawMessage `json:"aux"`
+		}
+		err := decoder.Decode(&message)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if message.ErrorDetail != nil && message.ErrorDetail.Message != "" {
+			return errors.New(message.ErrorDetail.Message)
+		} else if message.Error != "" {
+			return errors.New(message.Error)
+		}
+		if message.Aux != nil && onAux != nil {
+			onAux(message.Aux)
+		}
+	}
+}
+
+func archiveDirectory(dir string) (io.Reader, error) {
+	buffer := bytes.NewBuffer(nil)
+	writer := tar.NewWriter(buffer)
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		if relativePath == "." {
+			return nil
+		}
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(filePath); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relativePath)
+		if err = writer.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(writer, file)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer, nil
+}
+
+// DockerStreamReader reads multiplexed stdout and stderr of the attached container.
+// Header of every frame holds the stream type, so frames can be additionally copied to Stdout and Stderr writers if they are set
 type DockerStreamReader struct {
 	Reader io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
 	chunk  []byte
 }
 
+const (
+	dockerStdoutStream = 1
+	dockerStderrStream = 2
+)
+
 func (r *DockerStreamReader) Read(p []byte) (int, error) {
 	if len(r.chunk) == 0 {
 		var buffer [8]byte
@@ -170,6 +551,19 @@ func (r *DockerStreamReader) Read(p []byte) (int, error) {
 		if err != nil {
 			return 0, err
 		}
+		var copyTo io.Writer
+		switch buffer[0] {
+		case dockerStdoutStream:
+			copyTo = r.Stdout
+		case dockerStderrStream:
+			copyTo = r.Stderr
+		}
+		if copyTo != nil {
+			_, err = copyTo.Write(r.chunk)
+			if err != nil {
+				return 0, err
+			}
+		}
 	}
 	n := len(p)
 	if len(r.chunk) < n {
@@ -179,3 +573,18 @@ func (r *DockerStreamReader) Read(p []byte) (int, error) {
 	r.chunk = r.chunk[n:]
 	return n, nil
 }
+
+var sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ParseDockerImageDigest splits pinned image reference of the form "registry/image@sha256:<hex>" into image name and sha256 hash
+func ParseDockerImageDigest(reference string) (string, string, error) {
+	dockerImage, digest, ok := strings.Cut(strings.TrimSpace(reference), "@")
+	if !ok || dockerImage == "" {
+		return "", "", fmt.Errorf("image reference must be pinned by digest (image@sha256:<hash>): '%v'", reference)
+	}
+	shaHash, ok := strings.CutPrefix(digest, "sha256:")
+	if !ok || !sha256Pattern.MatchString(shaHash) {
+		return "", "", fmt.Errorf("unexpected digest of image reference: '%v'", reference)
+	}
+	return dockerImage, shaHash, nil
+}
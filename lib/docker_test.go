@@ -1,32 +1,98 @@
 package lib
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gobughunt/lib/dto"
 )
 
 func TestCleanup(t *testing.T) {
 	t.Log(Docker.Cleanup(context.Background()))
 }
 
+// TestCleanupConcurrentExec runs Cleanup against fake docker daemon while Exec pulls the image - nothing can be pruned until the container is started
+func TestCleanupConcurrentExec(t *testing.T) {
+	pulling, pulled := make(chan struct{}), make(chan struct{})
+	var requestsLock sync.Mutex
+	var requests []string
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/") // strip version of the api
+		requestsLock.Lock()
+		requests = append(requests, path)
+		requestsLock.Unlock()
+		switch {
+		case path == "images/create":
+			close(pulling)
+			<-pulled
+			_, _ = w.Write([]byte("{}"))
+		case path == "containers/create":
+			_, _ = w.Write([]byte(`{"Id": "lint"}`))
+		case path == "containers/lint/attach":
+			http.Error(w, "attach is not supported", http.StatusInternalServerError)
+		case strings.HasSuffix(path, "/prune"):
+			_, _ = w.Write([]byte("{}"))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer daemon.Close()
+	t.Setenv("DOCKER_HOST", "tcp://"+daemon.Listener.Addr().String())
+	t.Setenv("DOCKER_API_VERSION", "")
+	t.Setenv("DOCKER_CERT_PATH", "")
+
+	localBindPath := t.TempDir()
+	execErr := make(chan error)
+	go func() {
+		_, err := NaiveDockerApi{}.Exec(context.Background(), "sivukhinnikita/linter:1.0.0", "/src", localBindPath)
+		execErr <- err
+	}()
+	<-pulling
+	cleanupErr := make(chan error)
+	go func() { cleanupErr <- NaiveDockerApi{}.Cleanup(context.Background()) }()
+	time.Sleep(100 * time.Millisecond) // give cleanup a chance to prune the image which is being pulled
+	close(pulled)
+	require.ErrorContains(t, <-execErr, "unable to attach to container lint")
+	require.Nil(t, <-cleanupErr)
+
+	started := slices.Index(requests, "containers/lint/attach")
+	require.NotEqual(t, -1, started)
+	var prunes []string
+	for i, request := range requests {
+		if strings.HasSuffix(request, "/prune") {
+			require.Greater(t, i, started, "%v was sent before the container was started", request)
+			prunes = append(prunes, request)
+		}
+	}
+	require.Equal(t, []string{"containers/prune", "build/prune", "volumes/prune", "images/prune"}, prunes)
+}
+
 func TestDockerExec(t *testing.T) {
 	t.Run("simple", func(t *testing.T) {
 		path, err := filepath.Abs("../")
 		require.Nil(t, err)
-		lines, err := Docker.Exec(
+		result, err := Docker.Exec(
 			context.Background(),
 			"sivukhinnikita/govanish:1.0.0@sha256:91fc7f5131aa71e5659de72b78934ecef3373cf1315469e5e8a9d3e18b7e0b89",
 			"/home",
 			path,
 		)
 		require.Nil(t, err)
-		t.Logf("%#v", lines)
+		t.Logf("%#v, %+v", result.Lines, result.Usage)
 	})
 	t.Run("non-zero exit code", func(t *testing.T) {
 		path, err := filepath.Abs("../")
@@ -42,6 +108,60 @@ func TestDockerExec(t *testing.T) {
 	})
 }
 
+func TestDockerExitCodeErr(t *testing.T) {
+	t.Run("exit code", func(t *testing.T) {
+		err := error(DockerExitCodeErr{ExitCode: 2})
+		require.ErrorIs(t, err, DockerNonZeroExitCodeErr)
+		require.NotErrorIs(t, err, DockerOOMKilledErr)
+		require.Equal(t, "non zero exit code: 2", err.Error())
+	})
+	t.Run("signal", func(t *testing.T) {
+		err := DockerExitCodeErr{ExitCode: 139}
+		require.Equal(t, syscall.SIGSEGV, err.Signal())
+		require.Equal(t, "non zero exit code: 139 (signal: segmentation fault)", err.Error())
+	})
+	t.Run("oom", func(t *testing.T) {
+		err := error(DockerExitCodeErr{ExitCode: 137, OOMKilled: true, MemoryLimit: 128 * 1024 * 1024})
+		require.ErrorIs(t, err, DockerNonZeroExitCodeErr)
+		require.ErrorIs(t, err, DockerOOMKilledErr)
+		require.Equal(t, "non zero exit code: 137 (signal: killed): container killed by out of memory: limit is 128MiB", err.Error())
+	})
+}
+
+func TestAddStatsSample(t *testing.T) {
+	sample := func(cpuNs, pids uint64, memory map[string]uint64, ioBytes ...uint64) types.StatsJSON {
+		stats := types.StatsJSON{}
+		stats.Read = time.Now()
+		stats.CPUStats.CPUUsage.TotalUsage = cpuNs
+		stats.PidsStats.Current = pids
+		stats.MemoryStats.Usage = 1 << 30
+		stats.MemoryStats.Stats = memory
+		for i, value := range ioBytes {
+			stats.BlkioStats.IoServiceBytesRecursive = append(stats.BlkioStats.IoServiceBytesRecursive,
+				types.BlkioStatEntry{Op: []string{"read", "Write", "Total"}[i%3], Value: value},
+			)
+		}
+		return stats
+	}
+	t.Run("cgroup v1", func(t *testing.T) {
+		usage := AddStatsSample(dto.ResourceUsage{}, sample(1_500_000_000, 10, map[string]uint64{"rss": 100, "anon": 200}, 1, 2, 3))
+		usage = AddStatsSample(usage, sample(2_000_000_000, 5, map[string]uint64{"rss": 50}, 10, 20, 30))
+		require.Equal(t, dto.ResourceUsage{Samples: 2, CpuSeconds: 2, MaxRssBytes: 100, IoBytes: 30, MaxPids: 10}, usage)
+	})
+	t.Run("cgroup v2", func(t *testing.T) {
+		usage := AddStatsSample(dto.ResourceUsage{}, sample(0, 1, map[string]uint64{"anon": 200}))
+		require.Equal(t, dto.ResourceUsage{Samples: 1, MaxRssBytes: 200, MaxPids: 1}, usage)
+	})
+	t.Run("no memory stats", func(t *testing.T) {
+		usage := AddStatsSample(dto.ResourceUsage{}, sample(0, 1, nil))
+		require.Equal(t, int64(1<<30), usage.MaxRssBytes)
+	})
+	t.Run("stopped container", func(t *testing.T) {
+		usage := AddStatsSample(dto.ResourceUsage{Samples: 1, CpuSeconds: 1}, types.StatsJSON{})
+		require.Equal(t, dto.ResourceUsage{Samples: 1, CpuSeconds: 1}, usage)
+	})
+}
+
 func TestDockerStreamReader(t *testing.T) {
 	t.Run("short", func(t *testing.T) {
 		r := &DockerStreamReader{Reader: bytes.NewReader(
@@ -71,6 +191,30 @@ func TestDockerStreamReader(t *testing.T) {
 		require.Nil(t, err)
 		require.Equal(t, strings.Repeat("a", 257), string(data))
 	})
+	t.Run("streams", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		r := &DockerStreamReader{
+			Reader: bytes.NewReader(
+				append(
+					append(
+						[]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x06},
+						[]byte("hello\n")...,
+					),
+					append(
+						[]byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x06},
+						[]byte("world\n")...,
+					)...,
+				),
+			),
+			Stdout: &stdout,
+			Stderr: &stderr,
+		}
+		data, err := io.ReadAll(r)
+		require.Nil(t, err)
+		require.Equal(t, "hello\nworld\n", string(data))
+		require.Equal(t, "hello\n", stdout.String())
+		require.Equal(t, "world\n", stderr.String())
+	})
 	t.Run("broken", func(t *testing.T) {
 		r := &DockerStreamReader{Reader: bytes.NewReader(
 			append(
@@ -82,3 +226,57 @@ func TestDockerStreamReader(t *testing.T) {
 		require.NotNil(t, err)
 	})
 }
+
+func TestReadOutput(t *testing.T) {
+	output := "first\nsecond\n" + strings.Repeat("a", 100) + "\nlast"
+	t.Run("within limits", func(t *testing.T) {
+		lines, err := NaiveDockerApi{MaxOutputBytes: 1024, MaxLineBytes: 128}.readOutput(strings.NewReader(output))
+		require.Nil(t, err)
+		require.Equal(t, []string{"first", "second", strings.Repeat("a", 100), "last"}, lines)
+	})
+	t.Run("output limit", func(t *testing.T) {
+		lines, err := NaiveDockerApi{MaxOutputBytes: 64}.readOutput(strings.NewReader(output))
+		require.ErrorIs(t, err, DockerOutputLimitErr)
+		require.ErrorContains(t, err, "output is larger than 64B")
+		require.Equal(t, []string{"first", "second"}, lines)
+	})
+	t.Run("line limit", func(t *testing.T) {
+		lines, err := NaiveDockerApi{MaxLineBytes: 64}.readOutput(strings.NewReader(output))
+		require.ErrorIs(t, err, DockerOutputLimitErr)
+		require.ErrorContains(t, err, "line is longer than 64B")
+		require.Equal(t, []string{"first", "second"}, lines)
+	})
+	t.Run("default line limit", func(t *testing.T) {
+		_, err := NaiveDockerApi{}.readOutput(strings.NewReader(strings.Repeat("a", 2*bufio.MaxScanTokenSize)))
+		require.ErrorIs(t, err, DockerOutputLimitErr)
+	})
+}
+
+func TestLogBuffer(t *testing.T) {
+	buffer := &logBuffer{limit: 8}
+	for _, chunk := range []string{"hello", ", world", "!"} {
+		n, err := buffer.Write([]byte(chunk))
+		require.Nil(t, err)
+		require.Equal(t, len(chunk), n)
+	}
+	require.Equal(t, "hello, w", string(buffer.data))
+	require.Equal(t, int64(13), buffer.total)
+}
+
+func TestParseDockerImageDigest(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	t.Run("pinned", func(t *testing.T) {
+		image, shaHash, err := ParseDockerImageDigest("ghcr.io/sivukhin/govanish@sha256:" + hash)
+		require.Nil(t, err)
+		require.Equal(t, "ghcr.io/sivukhin/govanish", image)
+		require.Equal(t, hash, shaHash)
+	})
+	t.Run("tag", func(t *testing.T) {
+		_, _, err := ParseDockerImageDigest("ghcr.io/sivukhin/govanish:latest")
+		require.NotNil(t, err)
+	})
+	t.Run("broken digest", func(t *testing.T) {
+		_, _, err := ParseDockerImageDigest("ghcr.io/sivukhin/govanish@sha256:xyz")
+		require.NotNil(t, err)
+	})
+}
@@ -18,6 +18,7 @@ type LinterMeta struct {
 
 type LinterInstance struct {
 	Id                 string
+	GitCommitHash      string
 	DockerImage        string
 	DockerImageShaHash string
 }
@@ -50,16 +51,33 @@ func (repo RepoInstance) String() string {
 type LintStatus string
 
 const (
-	Pending LintStatus = "pending"
-	Failed             = "failed"
-	Succeed            = "succeed"
-	Skipped            = "skipped"
+	Pending   LintStatus = "pending"
+	Running              = "running"
+	Succeeded            = "succeeded"
+	Failed               = "failed"
+	Skipped              = "skipped"
+	TimedOut             = "timed_out"
+	Cancelled            = "cancelled"
+	OomKilled            = "oom_killed"
+	Crashed              = "crashed"
+	TooNoisy             = "too_noisy"
 )
 
 type LintTask struct {
-	Id     string
-	Linter LinterInstance
-	Repo   RepoInstance
+	Id        string
+	Linter    LinterInstance
+	Repo      RepoInstance
+	Attempt   int   // 1-based number of the attempt to lint the task
+	AttemptId int64 // identity of the attempt record in the history of the task
+}
+
+// ResourceUsage is sampled from the container stats while the linter is running
+type ResourceUsage struct {
+	Samples     int // zero if container finished before the first sample was taken
+	CpuSeconds  float64
+	MaxRssBytes int64
+	IoBytes     int64 // bytes read from and written to the block devices
+	MaxPids     int64
 }
 
 type LintResult struct {
@@ -77,13 +95,26 @@ type HighlightSnippet struct {
 
 type LintHighlightSnippet struct {
 	LintHighlight
-	Snippet HighlightSnippet
+	Snippet     HighlightSnippet
+	Fingerprint string // content-based identity of the highlight which is stable across commits
 }
 
+type Severity string
+
+const (
+	SeverityNotice  Severity = "notice"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
 type LintHighlight struct {
 	Path        string
 	StartLine   int
 	EndLine     int
+	StartColumn int // 0 if linter reported no column
+	EndColumn   int // 0 if linter reported no column
+	Severity    Severity
+	RuleId      string // identifier of the check (or sub-linter) which produced the highlight, can be empty
 	Explanation string
 }
 
@@ -99,3 +130,11 @@ func (ref GitRef) String() string {
 		return "commit:" + ref.CommitHash
 	}
 }
+
+// LintLogs is the raw output of the linter container - every stream is truncated to the limit of the worker
+type LintLogs struct {
+	Stdout      []byte
+	Stderr      []byte
+	StdoutBytes int64 // size of the whole stream before truncation
+	StderrBytes int64
+}
@@ -0,0 +1,517 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gobughunt/lib/dto"
+	"github.com/sivukhin/gobughunt/lib/utils"
+	"github.com/sivukhin/gobughunt/storage"
+	"github.com/sivukhin/gobughunt/storage/db"
+)
+
+// fakeGit returns the current commit of the repo set by the test
+type fakeGit struct{ commits map[string]string }
+
+func (g fakeGit) Fetch(ctx context.Context, gitUrl string, gitRef dto.GitRef, targetDir string) (GitRepo, error) {
+	commit, ok := g.commits[gitUrl]
+	if !ok {
+		return GitRepo{}, fmt.Errorf("unknown repo: %v", gitUrl)
+	}
+	return GitRepo{CommitHash: commit}, nil
+}
+
+// fakeLinting reports highlights, logs, usage and error set by the test for the commit of the repo
+type fakeLinting struct {
+	highlights map[string][]dto.LintHighlightSnippet
+	logs       map[string]dto.LintLogs
+	usage      map[string]dto.ResourceUsage
+	errs       map[string]error
+}
+
+func (l fakeLinting) Run(ctx context.Context, repo dto.RepoInstance, linter dto.LinterInstance) (LintOutput, error) {
+	output := LintOutput{
+		Highlights: l.highlights[repo.GitCommitHash],
+		Logs:       l.logs[repo.GitCommitHash],
+		Usage:      l.usage[repo.GitCommitHash],
+	}
+	return output, l.errs[repo.GitCommitHash]
+}
+
+func fakeHighlight(path string, line int, fingerprint string) dto.LintHighlightSnippet {
+	return dto.LintHighlightSnippet{
+		LintHighlight: dto.LintHighlight{Path: path, StartLine: line, EndLine: line, RuleId: "nilness", Explanation: "nil dereference"},
+		Snippet:       dto.HighlightSnippet{StartLine: line, EndLine: line, Code: "x.y()"},
+		Fingerprint:   fingerprint,
+	}
+}
+
+type e2e struct {
+	t       *testing.T
+	ctx     context.Context
+	storage *storage.Memory
+	git     fakeGit
+	linting fakeLinting
+	manager Manager
+	worker  Worker
+	repo    dto.Repo
+	linter  dto.Linter
+}
+
+func newE2e(t *testing.T) *e2e {
+	memory := storage.NewMemory()
+	git := fakeGit{commits: map[string]string{"https://github.com/sivukhin/hugo": "c1"}}
+	linting := fakeLinting{
+		highlights: make(map[string][]dto.LintHighlightSnippet),
+		logs:       make(map[string]dto.LintLogs),
+		usage:      make(map[string]dto.ResourceUsage),
+		errs:       make(map[string]error),
+	}
+	linter := dto.Linter{
+		Meta:     dto.LinterMeta{Id: "nilaway", GitUrl: "https://github.com/uber-go/nilaway", GitBranch: "main"},
+		Instance: &dto.LinterInstance{Id: "nilaway", DockerImage: "nilaway:1", DockerImageShaHash: "sha256:1"},
+	}
+	ctx := context.Background()
+	require.Nil(t, memory.UpsertLinter(ctx, db.UpsertLinterParams{
+		LinterID:                linter.Meta.Id,
+		LinterGitUrl:            linter.Meta.GitUrl,
+		LinterGitBranch:         linter.Meta.GitBranch,
+		LinterLastDockerImage:   pgtype.Text{String: linter.Instance.DockerImage, Valid: true},
+		LinterLastDockerShaHash: pgtype.Text{String: linter.Instance.DockerImageShaHash, Valid: true},
+		CreatedAt:               pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}))
+	return &e2e{
+		t:       t,
+		ctx:     ctx,
+		storage: memory,
+		git:     git,
+		linting: linting,
+		manager: Manager{Storage: memory, GitApi: git},
+		worker:  Worker{Id: "worker", Storage: memory, Linting: linting, LockDuration: time.Minute, MaxAttempts: 2, RetryDelay: time.Minute},
+		repo:    dto.Repo{Meta: dto.RepoMeta{Id: "hugo", GitUrl: "https://github.com/sivukhin/hugo", GitBranch: "master"}},
+		linter:  linter,
+	}
+}
+
+// schedule refreshes the repo and creates lint task for its current commit
+func (e *e2e) schedule() {
+	repo, err := e.manager.RefreshRepo(e.ctx, e.repo)
+	require.Nil(e.t, err)
+	require.Nil(e.t, e.manager.ManageOnce(e.ctx, repo, e.linter))
+}
+
+// lint runs single iteration of the worker slot
+func (e *e2e) lint(slot string) error {
+	owner := pgtype.Text{String: slot, Valid: true}
+	task, err := e.worker.takeLintTask(e.ctx, owner)
+	if err != nil {
+		return err
+	}
+	return e.worker.saveLintResult(e.ctx, e.worker.lintTask(e.ctx, task, owner), owner)
+}
+
+func (e *e2e) tasks() []db.ListBugHuntLintTasksRow {
+	tasks, err := e.storage.ListBugHuntLintTasks(e.ctx, db.ListBugHuntLintTasksParams{PageLimit: 100})
+	require.Nil(e.t, err)
+	return tasks
+}
+
+func (e *e2e) attempts() []db.LintAttempt {
+	var lintIds []string
+	for _, task := range e.tasks() {
+		lintIds = append(lintIds, task.LintID)
+	}
+	attempts, err := e.storage.ListLintAttempts(e.ctx, lintIds)
+	require.Nil(e.t, err)
+	return attempts
+}
+
+func (e *e2e) highlights() []db.ListBugHuntHighlightsRow {
+	highlights, err := e.storage.ListBugHuntHighlights(e.ctx, db.ListBugHuntHighlightsParams{
+		LintID: "", LinterID: "", RepoID: "", RuleID: "", Severity: "", ModerationStatus: "", AllPending: true, PageLimit: 100,
+	})
+	require.Nil(e.t, err)
+	return highlights
+}
+
+func TestE2eLintCycle(t *testing.T) {
+	e := newE2e(t)
+	e.linting.highlights["c1"] = []dto.LintHighlightSnippet{fakeHighlight("a.go", 10, "fp-a"), fakeHighlight("b.go", 20, "fp-b")}
+	e.linting.highlights["c2"] = []dto.LintHighlightSnippet{fakeHighlight("b.go", 25, "fp-b")}
+
+	e.schedule()
+	e.schedule() // the same linter image and commit are deduplicated
+	require.Len(t, e.tasks(), 1)
+	require.Nil(t, e.lint("slot-1"))
+	require.ErrorIs(t, e.lint("slot-1"), pgx.ErrNoRows)
+
+	tasks := e.tasks()
+	require.Equal(t, db.LintStatusSucceeded, tasks[0].LintStatus)
+	require.Equal(t, int32(1), tasks[0].LintAttempts)
+	highlights := e.highlights()
+	require.Len(t, highlights, 2)
+	require.Equal(t, db.HighlightStatusPending, highlights[0].ModerationStatus)
+	require.Equal(t, "c1", highlights[0].FirstSeenCommitHash.String)
+
+	for _, highlight := range highlights {
+		require.Nil(t, e.storage.ModerateBugHuntHighlight(e.ctx, db.ModerateBugHuntHighlightParams{
+			LintID:           highlight.LintID,
+			Fingerprint:      highlight.Fingerprint,
+			ModerationStatus: db.HighlightStatusAccepted,
+			ModeratedAt:      pgtype.Timestamp{Time: time.Now(), Valid: true},
+			ModeratedBy:      pgtype.Text{String: "sivukhin", Valid: true},
+		}))
+	}
+
+	// next commit fixes a.go and moves highlight in b.go - its moderation is inherited
+	e.git.commits[e.repo.Meta.GitUrl] = "c2"
+	e.schedule()
+	require.Len(t, e.tasks(), 2)
+	require.Nil(t, e.lint("slot-1"))
+
+	highlights = e.highlights()
+	require.Len(t, highlights, 3) // highlight in b.go is reported by both tasks
+	for _, highlight := range highlights {
+		require.Equal(t, db.HighlightStatusAccepted, highlight.ModerationStatus)
+	}
+	pending, err := e.storage.ListBugHuntHighlights(e.ctx, db.ListBugHuntHighlightsParams{
+		LintID: "", LinterID: "", RepoID: "", RuleID: "", Severity: "", ModerationStatus: "pending", AllPending: true, PageLimit: 100,
+	})
+	require.Nil(t, err)
+	require.Empty(t, pending)
+
+	linters, err := e.storage.ListBugHuntLinters(e.ctx, db.ListBugHuntLintersParams{PageLimit: 100})
+	require.Nil(t, err)
+	require.Len(t, linters, 1)
+	require.Equal(t, int64(2), linters[0].TotalHighlight)
+	require.Equal(t, int64(2), linters[0].AcceptedHighlight)
+	require.Equal(t, int64(1), linters[0].FixedHighlight)
+
+	repos, err := e.storage.ListBugHuntRepos(e.ctx, db.ListBugHuntReposParams{PageLimit: 100})
+	require.Nil(t, err)
+	require.Equal(t, int64(2), repos[0].AcceptedHighlight)
+	require.Equal(t, int64(1), repos[0].FixedHighlight)
+}
+
+func TestE2eHighlightsStatusFilter(t *testing.T) {
+	e := newE2e(t)
+	// highlight without fingerprint doesn't inherit moderation, so each commit has its own status
+	e.linting.highlights["c1"] = []dto.LintHighlightSnippet{fakeHighlight("a.go", 10, "")}
+	e.linting.highlights["c2"] = []dto.LintHighlightSnippet{fakeHighlight("a.go", 10, "")}
+	e.schedule()
+	require.Nil(t, e.lint("slot-1"))
+	highlight := e.highlights()[0]
+	require.Nil(t, e.storage.ModerateBugHuntHighlight(e.ctx, db.ModerateBugHuntHighlightParams{
+		LintID:           highlight.LintID,
+		Fingerprint:      highlight.Fingerprint,
+		ModerationStatus: db.HighlightStatusAccepted,
+		ModeratedAt:      pgtype.Timestamp{Time: time.Now(), Valid: true},
+		ModeratedBy:      pgtype.Text{String: "sivukhin", Valid: true},
+	}))
+	e.git.commits[e.repo.Meta.GitUrl] = "c2"
+	e.schedule()
+	require.Nil(t, e.lint("slot-1"))
+
+	filtered := func(status string) []db.ListBugHuntHighlightsRow {
+		highlights, err := e.storage.ListBugHuntHighlights(e.ctx, db.ListBugHuntHighlightsParams{
+			LintID: "", LinterID: "", RepoID: "", RuleID: "", Severity: "", ModerationStatus: status, AllPending: true, PageLimit: 100,
+		})
+		require.Nil(t, err)
+		return highlights
+	}
+	require.Len(t, filtered(""), 1)
+	require.Len(t, filtered("accepted"), 1)
+	require.Empty(t, filtered("pending")) // pending copy from c2 is shadowed by the accepted one
+}
+
+func TestE2eLintNewImageNotFixes(t *testing.T) {
+	e := newE2e(t)
+	e.linting.highlights["c1"] = []dto.LintHighlightSnippet{fakeHighlight("a.go", 10, "fp-a")}
+	e.schedule()
+	require.Nil(t, e.lint("slot-1"))
+	highlight := e.highlights()[0]
+	require.Nil(t, e.storage.ModerateBugHuntHighlight(e.ctx, db.ModerateBugHuntHighlightParams{
+		LintID:           highlight.LintID,
+		Fingerprint:      highlight.Fingerprint,
+		ModerationStatus: db.HighlightStatusAccepted,
+		ModeratedAt:      pgtype.Timestamp{Time: time.Now(), Valid: true},
+		ModeratedBy:      pgtype.Text{String: "sivukhin", Valid: true},
+	}))
+
+	// new linter image stops reporting the rule - highlight isn't fixed by the next commit
+	e.linter.Instance = &dto.LinterInstance{Id: "nilaway", DockerImage: "nilaway:2", DockerImageShaHash: "sha256:2"}
+	e.git.commits[e.repo.Meta.GitUrl] = "c2"
+	e.schedule()
+	require.Nil(t, e.lint("slot-1"))
+
+	linters, err := e.storage.ListBugHuntLinters(e.ctx, db.ListBugHuntLintersParams{PageLimit: 100})
+	require.Nil(t, err)
+	require.Equal(t, int64(0), linters[0].FixedHighlight)
+
+	// the same image which reported the highlight doesn't report it anymore
+	e.linter.Instance = &dto.LinterInstance{Id: "nilaway", DockerImage: "nilaway:1", DockerImageShaHash: "sha256:1"}
+	e.git.commits[e.repo.Meta.GitUrl] = "c3"
+	e.schedule()
+	require.Nil(t, e.lint("slot-1"))
+
+	linters, err = e.storage.ListBugHuntLinters(e.ctx, db.ListBugHuntLintersParams{PageLimit: 100})
+	require.Nil(t, err)
+	require.Equal(t, int64(1), linters[0].FixedHighlight)
+}
+
+func TestE2eLintLocking(t *testing.T) {
+	e := newE2e(t)
+	e.schedule()
+
+	first := pgtype.Text{String: "slot-1", Valid: true}
+	task, err := e.worker.takeLintTask(e.ctx, first)
+	require.Nil(t, err)
+	_, err = e.worker.takeLintTask(e.ctx, pgtype.Text{String: "slot-2", Valid: true})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// lock expires and the task is taken by another slot - the first slot must not overwrite its result
+	e.worker.LockDuration = -time.Second
+	second := pgtype.Text{String: "slot-2", Valid: true}
+	retaken, err := e.worker.takeLintTask(e.ctx, second)
+	require.Nil(t, err)
+	e.worker.LockDuration = time.Minute
+	err = e.worker.saveLintResult(e.ctx, lintResult{task: task}, first)
+	require.ErrorIs(t, err, LintLockLostErr)
+	require.Equal(t, db.LintStatusRunning, e.tasks()[0].LintStatus)
+
+	_, err = e.worker.takeLintTask(e.ctx, first)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	require.Nil(t, e.worker.saveLintResult(e.ctx, lintResult{task: retaken}, second))
+	require.Equal(t, db.LintStatusSucceeded, e.tasks()[0].LintStatus)
+
+	attempts := e.attempts()
+	require.Len(t, attempts, 2)
+	require.Equal(t, db.LintStatusCancelled, attempts[0].LintStatus.LintStatus)
+	require.Equal(t, "slot-1", attempts[0].WorkerID)
+	require.Equal(t, db.LintStatusSucceeded, attempts[1].LintStatus.LintStatus)
+	require.Equal(t, int32(2), attempts[1].Attempt)
+}
+
+func TestE2eLintRetries(t *testing.T) {
+	e := newE2e(t)
+	e.linting.errs["c1"] = fmt.Errorf("%w: network is unreachable", LintTempErr)
+	e.schedule()
+	require.ErrorIs(t, e.lint("slot-1"), LintTempErr)
+	task := e.tasks()[0]
+	require.Equal(t, db.LintStatusPending, task.LintStatus)
+	require.Equal(t, int32(1), task.LintAttempts)
+	require.True(t, task.NextAttemptAt.Time.After(time.Now()))
+	require.Contains(t, task.LintStatusComment.String, "network is unreachable")
+	require.ErrorIs(t, e.lint("slot-1"), pgx.ErrNoRows) // task waits for the backoff
+}
+
+func TestE2eLintFailures(t *testing.T) {
+	e := newE2e(t)
+	e.worker.RetryDelay = -time.Minute // retries are due right away
+	e.linting.errs["c1"] = fmt.Errorf("%w: network is unreachable", LintTempErr)
+	e.schedule()
+	require.ErrorIs(t, e.lint("slot-1"), LintTempErr)
+	require.Equal(t, db.LintStatusPending, e.tasks()[0].LintStatus)
+	require.ErrorIs(t, e.lint("slot-1"), LintTempErr)
+	tasks := e.tasks()
+	require.Equal(t, db.LintStatusFailed, tasks[0].LintStatus)
+	require.Contains(t, tasks[0].LintStatusComment.String, "gave up after 2 attempts")
+	attempts := e.attempts()
+	require.Len(t, attempts, 2)
+	require.Equal(t, db.LintStatusPending, attempts[0].LintStatus.LintStatus)
+	require.Equal(t, db.LintStatusFailed, attempts[1].LintStatus.LintStatus)
+	require.Contains(t, attempts[0].Error.String, "network is unreachable")
+
+	e.git.commits[e.repo.Meta.GitUrl] = "c2"
+	e.linting.errs["c2"] = fmt.Errorf("%w: linter panicked: %w", LintExecErr, DockerExitCodeErr{ExitCode: 2})
+	e.schedule()
+	require.ErrorIs(t, e.lint("slot-1"), DockerNonZeroExitCodeErr)
+	tasks = e.tasks()
+	require.Equal(t, db.LintStatusFailed, tasks[0].LintStatus)
+	require.Contains(t, tasks[0].LintStatusComment.String, "panicked")
+	require.Equal(t, int32(1), tasks[0].LintAttempts) // only temp errors are retried
+	for _, attempt := range e.attempts() {
+		if attempt.LintID == tasks[0].LintID {
+			require.Equal(t, pgtype.Int4{Int32: 2, Valid: true}, attempt.ExitCode)
+		}
+	}
+	require.Empty(t, e.highlights())
+}
+
+func TestE2eLintTimeout(t *testing.T) {
+	e := newE2e(t)
+	e.linting.errs["c1"] = context.DeadlineExceeded
+	e.schedule()
+
+	owner := pgtype.Text{String: "slot-1", Valid: true}
+	task, err := e.worker.takeLintTask(e.ctx, owner)
+	require.Nil(t, err)
+	ctx, cancel := context.WithTimeout(e.ctx, 0)
+	defer cancel()
+	err = e.worker.saveLintResult(e.ctx, e.worker.lintTask(ctx, task, owner), owner)
+	require.ErrorIs(t, err, LintTimeoutErr)
+	require.Equal(t, db.LintStatusTimedOut, e.tasks()[0].LintStatus)
+	require.Equal(t, db.LintStatusTimedOut, e.attempts()[0].LintStatus.LintStatus)
+}
+
+func TestE2eLintCancellation(t *testing.T) {
+	e := newE2e(t)
+	e.schedule()
+
+	owner := pgtype.Text{String: "slot-1", Valid: true}
+	task, err := e.worker.takeLintTask(e.ctx, owner)
+	require.Nil(t, err)
+	_, err = e.storage.CancelLinterLintTasks(e.ctx, db.CancelLinterLintTasksParams{
+		LinterID:    e.linter.Meta.Id,
+		Comment:     pgtype.Text{String: "linter is paused", Valid: true},
+		CancelledAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+	})
+	require.Nil(t, err)
+	require.ErrorIs(t, e.worker.saveLintResult(e.ctx, lintResult{task: task}, owner), LintLockLostErr)
+	require.Equal(t, db.LintStatusCancelled, e.tasks()[0].LintStatus)
+	require.ErrorIs(t, e.lint("slot-1"), pgx.ErrNoRows)
+
+	_, err = e.storage.ResumeLinterLintTasks(e.ctx, e.linter.Meta.Id)
+	require.Nil(t, err)
+	require.Nil(t, e.lint("slot-1"))
+	require.Equal(t, db.LintStatusSucceeded, e.tasks()[0].LintStatus)
+	attempts := e.attempts()
+	require.Len(t, attempts, 2)
+	require.Equal(t, db.LintStatusCancelled, attempts[0].LintStatus.LintStatus)
+	require.Equal(t, "linter is paused", attempts[0].Error.String)
+	require.Equal(t, int32(1), attempts[1].Attempt)
+}
+
+func TestE2eLintResourceFailures(t *testing.T) {
+	e := newE2e(t)
+	e.linting.errs["c1"] = fmt.Errorf("%w: linter nilaway needs more than 4GiB of memory on repo hugo: %w", LintOOMErr, DockerExitCodeErr{ExitCode: 137, OOMKilled: true})
+	e.schedule()
+	require.ErrorIs(t, e.lint("slot-1"), LintOOMErr)
+	tasks := e.tasks()
+	require.Equal(t, db.LintStatusOomKilled, tasks[0].LintStatus)
+	require.Contains(t, tasks[0].LintStatusComment.String, "needs more than 4GiB of memory")
+	require.Equal(t, pgtype.Int4{Int32: 137, Valid: true}, e.attempts()[0].ExitCode)
+
+	e.git.commits[e.repo.Meta.GitUrl] = "c2"
+	e.linting.errs["c2"] = fmt.Errorf("%w: linter nilaway was terminated by signal %q on repo hugo", LintCrashErr, "segmentation fault")
+	e.schedule()
+	require.ErrorIs(t, e.lint("slot-1"), LintCrashErr)
+	statuses := make([]db.LintStatus, 0)
+	for _, task := range e.tasks() {
+		statuses = append(statuses, task.LintStatus)
+	}
+	require.ElementsMatch(t, []db.LintStatus{db.LintStatusOomKilled, db.LintStatusCrashed}, statuses)
+}
+
+func TestE2eLintUsage(t *testing.T) {
+	e := newE2e(t)
+	e.linting.usage["c1"] = dto.ResourceUsage{Samples: 3, CpuSeconds: 10, MaxRssBytes: 1000, IoBytes: 100, MaxPids: 7}
+	e.linting.usage["c2"] = dto.ResourceUsage{Samples: 2, CpuSeconds: 20, MaxRssBytes: 3001, IoBytes: 300, MaxPids: 5}
+	e.linting.errs["c2"] = fmt.Errorf("%w: linter nilaway needs more memory", LintOOMErr)
+	e.schedule()
+	require.Nil(t, e.lint("slot-1"))
+	e.git.commits[e.repo.Meta.GitUrl] = "c2"
+	e.schedule()
+	require.ErrorIs(t, e.lint("slot-1"), LintOOMErr)
+	e.git.commits[e.repo.Meta.GitUrl] = "c3" // container finished before the first sample - usage is unknown
+	e.schedule()
+	require.Nil(t, e.lint("slot-1"))
+
+	for _, task := range e.tasks() {
+		require.Equal(t, task.LintStatus != db.LintStatusSucceeded || task.RepoGitCommitHash != "c3", task.CpuSeconds.Valid)
+	}
+	usage, err := e.storage.ListBugHuntLinterUsage(e.ctx)
+	require.Nil(t, err)
+	require.Equal(t, []db.ListBugHuntLinterUsageRow{{
+		LinterID:        "nilaway",
+		TotalTasks:      2,
+		TotalCpuSeconds: 30,
+		AvgCpuSeconds:   15,
+		MaxRssBytes:     3001,
+		AvgRssBytes:     2001,
+		AvgIoBytes:      200,
+		MaxPids:         7,
+	}}, usage)
+}
+
+func TestE2eLintLogs(t *testing.T) {
+	e := newE2e(t)
+	e.worker.MaxAttempts = 3
+	e.worker.RetryDelay = -time.Minute // retries are due right away
+	logsOf := func() db.GetLintLogsRow {
+		logs, err := e.storage.GetLintLogs(e.ctx, e.tasks()[0].LintID)
+		require.Nil(t, err)
+		return logs
+	}
+	e.linting.logs["c1"] = dto.LintLogs{Stdout: []byte("cloning..."), StdoutBytes: 10}
+	e.linting.errs["c1"] = fmt.Errorf("%w: network is unreachable", LintTempErr)
+	e.schedule()
+	require.ErrorIs(t, e.lint("slot-1"), LintTempErr)
+	require.True(t, e.tasks()[0].HasLogs)
+	require.Equal(t, int32(1), logsOf().Attempt)
+
+	// silent attempt keeps logs of the previous one
+	e.linting.logs["c1"] = dto.LintLogs{}
+	require.ErrorIs(t, e.lint("slot-1"), LintTempErr)
+	require.Equal(t, int32(1), logsOf().Attempt)
+
+	e.linting.logs["c1"] = dto.LintLogs{Stdout: []byte("::warning file=a.go"), Stderr: []byte("panic: "), StdoutBytes: 19, StderrBytes: 100}
+	e.linting.errs["c1"] = fmt.Errorf("%w: linter crashed", LintCrashErr)
+	require.ErrorIs(t, e.lint("slot-1"), LintCrashErr)
+	logs := logsOf()
+	require.Equal(t, int32(3), logs.Attempt)
+	require.Equal(t, int64(19), logs.StdoutBytes)
+	require.Equal(t, int64(100), logs.StderrBytes)
+	stdout, err := utils.Gunzip(logs.Stdout)
+	require.Nil(t, err)
+	require.Equal(t, "::warning file=a.go", string(stdout))
+	stderr, err := utils.Gunzip(logs.Stderr)
+	require.Nil(t, err)
+	require.Equal(t, "panic: ", string(stderr))
+}
+
+func TestE2eLintNoisy(t *testing.T) {
+	e := newE2e(t)
+	e.linting.highlights["c1"] = []dto.LintHighlightSnippet{fakeHighlight("a.go", 10, "fp-a"), fakeHighlight("b.go", 20, "fp-b")}
+	e.schedule()
+	require.Nil(t, e.lint("slot-1"))
+	for _, highlight := range e.highlights() {
+		require.Nil(t, e.storage.ModerateBugHuntHighlight(e.ctx, db.ModerateBugHuntHighlightParams{
+			LintID:           highlight.LintID,
+			Fingerprint:      highlight.Fingerprint,
+			ModerationStatus: db.HighlightStatusAccepted,
+			ModeratedAt:      pgtype.Timestamp{Time: time.Now(), Valid: true},
+			ModeratedBy:      pgtype.Text{String: "sivukhin", Valid: true},
+		}))
+	}
+
+	// output of the next commit was truncated before highlight in a.go was printed
+	e.git.commits[e.repo.Meta.GitUrl] = "c2"
+	e.linting.highlights["c2"] = []dto.LintHighlightSnippet{fakeHighlight("b.go", 20, "fp-b")}
+	e.linting.errs["c2"] = fmt.Errorf("%w: output is larger than 64MiB", LintNoisyErr)
+	e.schedule()
+	require.ErrorIs(t, e.lint("slot-1"), LintNoisyErr)
+
+	for _, task := range e.tasks() {
+		if task.RepoGitCommitHash != "c2" {
+			continue
+		}
+		require.Equal(t, db.LintStatusTooNoisy, task.LintStatus)
+		require.Contains(t, task.LintStatusComment.String, "output is larger than 64MiB")
+		highlights, err := e.storage.ListBugHuntHighlights(e.ctx, db.ListBugHuntHighlightsParams{
+			LintID: task.LintID, LinterID: "", RepoID: "", RuleID: "", Severity: "", ModerationStatus: "", AllPending: true, PageLimit: 100,
+		})
+		require.Nil(t, err)
+		require.Len(t, highlights, 1) // truncated result is kept and inherits moderation
+		require.Equal(t, db.HighlightStatusAccepted, highlights[0].ModerationStatus)
+	}
+	linters, err := e.storage.ListBugHuntLinters(e.ctx, db.ListBugHuntLintersParams{PageLimit: 100})
+	require.Nil(t, err)
+	require.Equal(t, int64(0), linters[0].FixedHighlight) // missing highlight in a.go doesn't mean it was fixed
+}
@@ -0,0 +1,105 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+
+	"github.com/sivukhin/gobughunt/lib/dto"
+)
+
+// FingerprintHighlights fills Fingerprint of the snippets extracted from the single file content.
+// Fingerprint must survive unrelated edits of the file, so it depends only on the highlighted code (with normalized whitespaces),
+// rule of the highlight and name of the enclosing function - but not on the exact line numbers.
+// Identical highlights within the same function are distinguished by their order of appearance
+func FingerprintHighlights(content []byte, snippets []dto.LintHighlightSnippet) {
+	lines := bytes.Split(content, []byte("\n"))
+	var file *ast.File
+	fset := token.NewFileSet()
+	if len(snippets) > 0 && strings.HasSuffix(snippets[0].Path, ".go") {
+		// file can be broken or written for the newer go version - we will fallback to the fingerprint without function name then
+		file, _ = parser.ParseFile(fset, snippets[0].Path, content, parser.SkipObjectResolution)
+	}
+
+	order := make([]int, len(snippets))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		if snippets[a].StartLine != snippets[b].StartLine {
+			return snippets[a].StartLine - snippets[b].StartLine
+		}
+		return snippets[a].StartColumn - snippets[b].StartColumn
+	})
+	occurrences := make(map[string]int)
+	for _, i := range order {
+		highlight := snippets[i].LintHighlight
+		start := min(max(highlight.StartLine, 1), len(lines))
+		code := lines[start-1 : min(max(highlight.EndLine, start), len(lines))]
+		fingerprint := HighlightFingerprint(highlight.Path, highlight.RuleId, EnclosingFunction(fset, file, highlight.StartLine), code)
+		occurrences[fingerprint]++
+		if occurrence := occurrences[fingerprint]; occurrence > 1 {
+			fingerprint = fmt.Sprintf("%v-%v", fingerprint, occurrence)
+		}
+		snippets[i].Fingerprint = fingerprint
+	}
+}
+
+func HighlightFingerprint(path, ruleId, function string, code [][]byte) string {
+	hash := sha256.New()
+	for _, part := range []string{path, ruleId, function} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	for _, line := range code {
+		normalized := bytes.Join(bytes.Fields(line), []byte(" "))
+		if len(normalized) == 0 {
+			continue
+		}
+		hash.Write(normalized)
+		hash.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:32]
+}
+
+// EnclosingFunction returns name of the top-level function (or method in "Receiver.Name" form) which contains the line.
+// Empty string returned if the line is outside of any function
+func EnclosingFunction(fset *token.FileSet, file *ast.File, line int) string {
+	if file == nil {
+		return ""
+	}
+	for _, decl := range file.Decls {
+		function, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		if fset.Position(function.Pos()).Line > line || fset.Position(function.End()).Line < line {
+			continue
+		}
+		if function.Recv == nil || len(function.Recv.List) == 0 {
+			return function.Name.Name
+		}
+		return receiverName(function.Recv.List[0].Type) + "." + function.Name.Name
+	}
+	return ""
+}
+
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	case *ast.IndexListExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gobughunt/lib/dto"
+)
+
+func fingerprints(content string, highlights ...dto.LintHighlight) []string {
+	snippets := make([]dto.LintHighlightSnippet, 0, len(highlights))
+	for _, highlight := range highlights {
+		snippets = append(snippets, dto.LintHighlightSnippet{LintHighlight: highlight})
+	}
+	FingerprintHighlights([]byte(content), snippets)
+	result := make([]string, 0, len(snippets))
+	for _, snippet := range snippets {
+		result = append(result, snippet.Fingerprint)
+	}
+	return result
+}
+
+func TestFingerprintHighlights(t *testing.T) {
+	before := `package main
+
+func (s *Server) Serve() error {
+	err := s.listen()
+	return err
+}
+
+func main() {
+	_ = 1
+}
+`
+	after := `package main
+
+import "fmt"
+
+// Serve runs the server
+func (s *Server) Serve() error {
+	fmt.Println("serving")
+	err :=   s.listen()
+	return err
+}
+
+func main() {
+	_ = 1
+}
+`
+	t.Run("stable across unrelated edits", func(t *testing.T) {
+		require.Equal(t,
+			fingerprints(before, dto.LintHighlight{Path: "main.go", StartLine: 4, EndLine: 4, RuleId: "errcheck"}),
+			fingerprints(after, dto.LintHighlight{Path: "main.go", StartLine: 8, EndLine: 8, RuleId: "errcheck"}),
+		)
+	})
+	t.Run("depends on rule", func(t *testing.T) {
+		require.NotEqual(t,
+			fingerprints(before, dto.LintHighlight{Path: "main.go", StartLine: 4, EndLine: 4, RuleId: "errcheck"}),
+			fingerprints(before, dto.LintHighlight{Path: "main.go", StartLine: 4, EndLine: 4, RuleId: "ineffassign"}),
+		)
+	})
+	t.Run("depends on enclosing function", func(t *testing.T) {
+		content := "package main\n\nfunc a() {\n\t_ = 1\n}\n\nfunc b() {\n\t_ = 1\n}\n"
+		result := fingerprints(content,
+			dto.LintHighlight{Path: "main.go", StartLine: 4, EndLine: 4},
+			dto.LintHighlight{Path: "main.go", StartLine: 8, EndLine: 8},
+		)
+		require.NotEqual(t, result[0], result[1])
+	})
+	t.Run("duplicates within function", func(t *testing.T) {
+		content := "package main\n\nfunc a() {\n\t_ = 1\n\t_ = 1\n}\n"
+		result := fingerprints(content,
+			dto.LintHighlight{Path: "main.go", StartLine: 5, EndLine: 5},
+			dto.LintHighlight{Path: "main.go", StartLine: 4, EndLine: 4},
+		)
+		require.Equal(t, result[1]+"-2", result[0])
+	})
+	t.Run("non go file", func(t *testing.T) {
+		result := fingerprints("a\nb\n", dto.LintHighlight{Path: "go.mod", StartLine: 2, EndLine: 2})
+		require.Len(t, result[0], 32)
+	})
+}
+
+func TestEnclosingFunction(t *testing.T) {
+	content := `package main
+
+type List[T any] struct{}
+
+func (l *List[T]) Len() int {
+	return 0
+}
+
+func main() {
+}
+`
+	result := fingerprints(content, dto.LintHighlight{Path: "main.go", StartLine: 6, EndLine: 6})
+	require.Equal(t, fingerprints("package main\n\nfunc (l *List[T]) Len() int {\n\treturn 0\n}\n", dto.LintHighlight{Path: "main.go", StartLine: 4, EndLine: 4}), result)
+	require.NotEqual(t, fingerprints("package main\n\nfunc Len() int {\n\treturn 0\n}\n", dto.LintHighlight{Path: "main.go", StartLine: 4, EndLine: 4}), result)
+}
@@ -0,0 +1,87 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sivukhin/gobughunt/lib/dto"
+	"github.com/sivukhin/gobughunt/lib/logging"
+)
+
+// golangci-lint run --out-format json
+// https://golangci-lint.run/usage/configuration/#output-configuration
+type golangciDocument struct {
+	Issues *[]struct {
+		FromLinter string `json:"FromLinter"`
+		Text       string `json:"Text"`
+		Severity   string `json:"Severity"`
+		Pos        struct {
+			Filename string `json:"Filename"`
+			Line     int    `json:"Line"`
+			Column   int    `json:"Column"`
+		} `json:"Pos"`
+		LineRange *struct {
+			From int `json:"From"`
+			To   int `json:"To"`
+		} `json:"LineRange"`
+	} `json:"Issues"`
+}
+
+// ExtractGolangciOutput collects highlights from all golangci-lint JSON reports printed to the output
+func ExtractGolangciOutput(lines []string) []dto.LintHighlight {
+	highlights := make([]dto.LintHighlight, 0)
+	forEachJsonDocument(lines, func(document json.RawMessage) {
+		documentHighlights, err := ExtractGolangciHighlights(document)
+		if err != nil {
+			return // document of another format
+		}
+		highlights = append(highlights, documentHighlights...)
+	})
+	return highlights
+}
+
+func ExtractGolangciHighlights(document []byte) ([]dto.LintHighlight, error) {
+	var golangci golangciDocument
+	err := json.Unmarshal(document, &golangci)
+	if err != nil {
+		return nil, fmt.Errorf("invalid golangci-lint document: %w", err)
+	}
+	if golangci.Issues == nil {
+		return nil, fmt.Errorf("golangci-lint document without issues")
+	}
+	highlights := make([]dto.LintHighlight, 0, len(*golangci.Issues))
+	for _, issue := range *golangci.Issues {
+		highlightPath := NormalizeHighlightPath(issue.Pos.Filename)
+		if highlightPath == "" || issue.Pos.Line < 1 {
+			logging.Logger.Debugf("invalid position of golangci-lint issue: %+v", issue)
+			continue
+		}
+		startLine, endLine := issue.Pos.Line, issue.Pos.Line
+		if issue.LineRange != nil && issue.LineRange.From >= 1 && issue.LineRange.To >= issue.LineRange.From {
+			startLine, endLine = issue.LineRange.From, issue.LineRange.To
+		}
+		highlights = append(highlights, dto.LintHighlight{
+			Path:        highlightPath,
+			StartLine:   startLine,
+			EndLine:     endLine,
+			StartColumn: max(0, issue.Pos.Column),
+			Severity:    golangciSeverity(issue.Severity),
+			RuleId:      issue.FromLinter,
+			Explanation: issue.Text,
+		})
+	}
+	return highlights, nil
+}
+
+// golangciSeverity maps severity configured in golangci-lint (empty by default) to the highlight severity
+func golangciSeverity(severity string) dto.Severity {
+	switch strings.ToLower(severity) {
+	case "error":
+		return dto.SeverityError
+	case "info", "notice", "note":
+		return dto.SeverityNotice
+	default:
+		return dto.SeverityWarning
+	}
+}
@@ -0,0 +1,36 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gobughunt/lib/dto"
+)
+
+func TestExtractGolangciOutput(t *testing.T) {
+	highlights := ExtractGolangciOutput([]string{
+		`level=warning msg="[runner] Can't run linter goanalysis_metalinter"`,
+		`{"Issues":[` +
+			`{"FromLinter":"errcheck","Text":"Error return value of ` + "`f.Close`" + ` is not checked","Severity":"","SourceLines":["\tf.Close()"],"Replacement":null,"Pos":{"Filename":"cmd/main.go","Offset":120,"Line":12,"Column":9},"ExpectNoLint":false,"ExpectedNoLintLinter":""},` +
+			`{"FromLinter":"gocritic","Text":"ifElseChain: rewrite if-else to switch statement","Severity":"warning","Pos":{"Filename":"lib/lib.go","Line":30,"Column":2},"LineRange":{"From":30,"To":34}}` +
+			`],"Report":{"Linters":[{"Name":"errcheck","Enabled":true}]}}`,
+	})
+	require.Equal(t, []dto.LintHighlight{{
+		Path:        "cmd/main.go",
+		StartLine:   12,
+		EndLine:     12,
+		StartColumn: 9,
+		Severity:    dto.SeverityWarning,
+		RuleId:      "errcheck",
+		Explanation: "Error return value of `f.Close` is not checked",
+	}, {
+		Path:        "lib/lib.go",
+		StartLine:   30,
+		EndLine:     34,
+		StartColumn: 2,
+		Severity:    dto.SeverityWarning,
+		RuleId:      "gocritic",
+		Explanation: "ifElseChain: rewrite if-else to switch statement",
+	}}, highlights)
+}
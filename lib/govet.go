@@ -0,0 +1,116 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sivukhin/gobughunt/lib/dto"
+	"github.com/sivukhin/gobughunt/lib/logging"
+)
+
+// go vet -json / go/analysis -json output format: package -> analyzer -> diagnostics (or error object)
+// https://pkg.go.dev/golang.org/x/tools/go/analysis/internal/analysisflags (JSONTree)
+type vetDocument map[string]map[string]json.RawMessage
+
+type vetDiagnostic struct {
+	Category string `json:"category"`
+	Posn     string `json:"posn"`
+	End      string `json:"end"`
+	Message  string `json:"message"`
+	Related  []struct {
+		Posn    string `json:"posn"`
+		End     string `json:"end"`
+		Message string `json:"message"`
+	} `json:"related"`
+}
+
+// ExtractVetOutput collects highlights from all go vet JSON documents printed to the output
+func ExtractVetOutput(lines []string) []dto.LintHighlight {
+	highlights := make([]dto.LintHighlight, 0)
+	forEachJsonDocument(lines, func(document json.RawMessage) {
+		documentHighlights, err := ExtractVetHighlights(document)
+		if err != nil {
+			return // document of another format
+		}
+		highlights = append(highlights, documentHighlights...)
+	})
+	return highlights
+}
+
+func ExtractVetHighlights(document []byte) ([]dto.LintHighlight, error) {
+	var vet vetDocument
+	err := json.Unmarshal(document, &vet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid go vet document: %w", err)
+	}
+	highlights := make([]dto.LintHighlight, 0)
+	for _, pkg := range sortedKeys(vet) {
+		for _, analyzer := range sortedKeys(vet[pkg]) {
+			var diagnostics []vetDiagnostic
+			if err := json.Unmarshal(vet[pkg][analyzer], &diagnostics); err != nil {
+				logging.Logger.Debugf("analyzer %v failed for package %v: %s", analyzer, pkg, vet[pkg][analyzer])
+				continue
+			}
+			for _, diagnostic := range diagnostics {
+				highlightPath, startLine, startColumn, ok := ParsePosition(diagnostic.Posn)
+				if !ok {
+					logging.Logger.Debugf("invalid position of go vet diagnostic: %+v", diagnostic)
+					continue
+				}
+				endLine, endColumn := startLine, 0
+				if endPath, line, column, ok := ParsePosition(diagnostic.End); ok && endPath == highlightPath && line >= startLine {
+					endLine, endColumn = line, column
+				}
+				message := diagnostic.Message
+				for _, related := range diagnostic.Related {
+					if relatedPath, relatedLine, _, ok := ParsePosition(related.Posn); ok {
+						message += fmt.Sprintf(" (related %v:%v: %v)", relatedPath, relatedLine, related.Message)
+					}
+				}
+				highlights = append(highlights, dto.LintHighlight{
+					Path:        highlightPath,
+					StartLine:   startLine,
+					EndLine:     endLine,
+					StartColumn: startColumn,
+					EndColumn:   endColumn,
+					Severity:    dto.SeverityWarning,
+					RuleId:      analyzer,
+					Explanation: message,
+				})
+			}
+		}
+	}
+	return highlights, nil
+}
+
+// ParsePosition parses token.Position string representation (file:line:column or file:line)
+func ParsePosition(position string) (highlightPath string, line, column int, ok bool) {
+	tokens := strings.Split(position, ":")
+	if len(tokens) < 2 {
+		return "", 0, 0, false
+	}
+	if len(tokens) >= 3 {
+		lineValue, lineErr := strconv.Atoi(tokens[len(tokens)-2])
+		columnValue, columnErr := strconv.Atoi(tokens[len(tokens)-1])
+		if lineErr == nil && columnErr == nil && lineValue > 0 {
+			return NormalizeHighlightPath(strings.Join(tokens[:len(tokens)-2], ":")), lineValue, columnValue, true
+		}
+	}
+	lineValue, err := strconv.Atoi(tokens[len(tokens)-1])
+	if err != nil || lineValue < 1 {
+		return "", 0, 0, false
+	}
+	return NormalizeHighlightPath(strings.Join(tokens[:len(tokens)-1], ":")), lineValue, 0, true
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
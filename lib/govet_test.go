@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gobughunt/lib/dto"
+)
+
+func TestExtractVetOutput(t *testing.T) {
+	highlights := ExtractVetOutput([]string{
+		`# github.com/sivukhin/govanish`,
+		`{`,
+		`	"github.com/sivukhin/govanish": {`,
+		`		"printf": [`,
+		`			{`,
+		`				"posn": "/home/repo/main.go:10:2",`,
+		`				"end": "/home/repo/main.go:12:5",`,
+		`				"message": "fmt.Sprintf format %d has arg s of wrong type string"`,
+		`			}`,
+		`		],`,
+		`		"nilness": {"error": "analysis skipped due to errors in package"}`,
+		`	}`,
+		`}`,
+		`# github.com/sivukhin/govanish/lib`,
+		`{`,
+		`	"github.com/sivukhin/govanish/lib": {`,
+		`		"copylocks": [`,
+		`			{`,
+		`				"posn": "lib/lib.go:7:1",`,
+		`				"message": "Lock passes lock by value",`,
+		`				"related": [{"posn": "lib/lib.go:3:1", "message": "lock declared here"}]`,
+		`			}`,
+		`		]`,
+		`	}`,
+		`}`,
+	})
+	require.Equal(t, []dto.LintHighlight{{
+		Path:        "main.go",
+		StartLine:   10,
+		EndLine:     12,
+		StartColumn: 2,
+		EndColumn:   5,
+		Severity:    dto.SeverityWarning,
+		RuleId:      "printf",
+		Explanation: "fmt.Sprintf format %d has arg s of wrong type string",
+	}, {
+		Path:        "lib/lib.go",
+		StartLine:   7,
+		EndLine:     7,
+		StartColumn: 1,
+		Severity:    dto.SeverityWarning,
+		RuleId:      "copylocks",
+		Explanation: "Lock passes lock by value (related lib/lib.go:3: lock declared here)",
+	}}, highlights)
+}
+
+func TestParsePosition(t *testing.T) {
+	path, line, column, ok := ParsePosition("/home/repo/a/b.go:12:7")
+	require.True(t, ok)
+	require.Equal(t, []any{"a/b.go", 12, 7}, []any{path, line, column})
+
+	path, line, column, ok = ParsePosition("a/b.go:12")
+	require.True(t, ok)
+	require.Equal(t, []any{"a/b.go", 12, 0}, []any{path, line, column})
+
+	_, _, _, ok = ParsePosition("-")
+	require.False(t, ok)
+}
@@ -0,0 +1,152 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+
+	"github.com/sivukhin/gobughunt/lib/utils"
+)
+
+// Identity of the user in the external service
+type Identity struct {
+	Login string // stable unique key of the user: roles, votes and tokens are bound to it
+	Name  string // human-readable name of the user which is only displayed
+}
+
+// IdentityProvider authenticates users of the server through OAuth flow of the external service
+type IdentityProvider interface {
+	OauthConfig() *oauth2.Config
+	// Identify returns identity of the user who authorized the token
+	Identify(ctx context.Context, token *oauth2.Token) (Identity, error)
+}
+
+type IdentityProviderConfig struct {
+	Provider     string // github, gitlab, gitea or oidc
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+	BaseUrl      string // url of self-hosted gitlab or gitea instance, or issuer of the OIDC provider
+	NameClaim    string // claim of the OIDC userinfo with display name of the user (preferred_username by default)
+}
+
+// userInfoProvider fetches identity from the JSON fields of the user info endpoint - this is enough for all supported providers
+type userInfoProvider struct {
+	config      *oauth2.Config
+	userInfoUrl string
+	loginField  string
+	nameField   string
+}
+
+func (p userInfoProvider) OauthConfig() *oauth2.Config { return p.config }
+
+func (p userInfoProvider) Identify(ctx context.Context, token *oauth2.Token) (Identity, error) {
+	var userInfo map[string]any
+	err := getJson(ctx, p.config.Client(ctx, token), p.userInfoUrl, &userInfo)
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to get user info: %w", err)
+	}
+	login, _ := userInfo[p.loginField].(string)
+	if login == "" {
+		return Identity{}, fmt.Errorf("user info has no login in the '%v' field", p.loginField)
+	}
+	name, _ := userInfo[p.nameField].(string)
+	return Identity{Login: login, Name: utils.Ternary(name != "", name, login)}, nil
+}
+
+func NewIdentityProvider(ctx context.Context, config IdentityProviderConfig) (IdentityProvider, error) {
+	oauthConfig := &oauth2.Config{
+		ClientID:     config.ClientId,
+		ClientSecret: config.ClientSecret,
+		RedirectURL:  config.RedirectUrl,
+	}
+	baseUrl := strings.TrimSuffix(config.BaseUrl, "/")
+	switch config.Provider {
+	case "", "github":
+		oauthConfig.Scopes = []string{"(no scope)"}
+		oauthConfig.Endpoint = endpoints.GitHub
+		return userInfoProvider{config: oauthConfig, userInfoUrl: "https://api.github.com/user", loginField: "login", nameField: "login"}, nil
+	case "gitlab":
+		if baseUrl == "" {
+			baseUrl = "https://gitlab.com"
+		}
+		oauthConfig.Scopes = []string{"read_user"}
+		oauthConfig.Endpoint = oauth2.Endpoint{AuthURL: baseUrl + "/oauth/authorize", TokenURL: baseUrl + "/oauth/token"}
+		return userInfoProvider{config: oauthConfig, userInfoUrl: baseUrl + "/api/v4/user", loginField: "username", nameField: "username"}, nil
+	case "gitea":
+		if baseUrl == "" {
+			return nil, fmt.Errorf("base url of the gitea instance must be set")
+		}
+		oauthConfig.Endpoint = oauth2.Endpoint{AuthURL: baseUrl + "/login/oauth/authorize", TokenURL: baseUrl + "/login/oauth/access_token"}
+		return userInfoProvider{config: oauthConfig, userInfoUrl: baseUrl + "/api/v1/user", loginField: "login", nameField: "login"}, nil
+	case "oidc":
+		if baseUrl == "" {
+			return nil, fmt.Errorf("issuer of the OIDC provider must be set")
+		}
+		discovery, err := DiscoverOidc(ctx, http.DefaultClient, baseUrl)
+		if err != nil {
+			return nil, err
+		}
+		nameClaim := config.NameClaim
+		if nameClaim == "" {
+			nameClaim = "preferred_username"
+		}
+		oauthConfig.Scopes = []string{"openid", "profile", "email"}
+		oauthConfig.Endpoint = oauth2.Endpoint{AuthURL: discovery.AuthorizationEndpoint, TokenURL: discovery.TokenEndpoint}
+		// users are keyed by the subject: preferred_username and email can be changed by the user and are not unique
+		return userInfoProvider{config: oauthConfig, userInfoUrl: discovery.UserInfoEndpoint, loginField: "sub", nameField: nameClaim}, nil
+	default:
+		return nil, fmt.Errorf("unsupported identity provider: %v", config.Provider)
+	}
+}
+
+type OidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// DiscoverOidc fetches endpoints of the provider from its OpenID configuration document
+func DiscoverOidc(ctx context.Context, client *http.Client, issuer string) (OidcDiscovery, error) {
+	var discovery OidcDiscovery
+	err := getJson(ctx, client, issuer+"/.well-known/openid-configuration", &discovery)
+	if err != nil {
+		return OidcDiscovery{}, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+	// see https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderConfigurationValidation
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return OidcDiscovery{}, fmt.Errorf("OIDC issuer mismatch: expected %v, got %v", issuer, discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.UserInfoEndpoint == "" {
+		return OidcDiscovery{}, fmt.Errorf("OIDC provider has no authorization, token or userinfo endpoint: %+v", discovery)
+	}
+	return discovery, nil
+}
+
+func getJson(ctx context.Context, client *http.Client, url string, value any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %v from %v: %v", response.StatusCode, url, string(data))
+	}
+	return json.Unmarshal(data, value)
+}
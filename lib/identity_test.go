@@ -0,0 +1,122 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// mockOidcServer emulates OIDC provider which issues token for the single code
+func mockOidcServer(t *testing.T, userInfo map[string]any) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	writeJson := func(writer http.ResponseWriter, value any) {
+		writer.Header().Set("Content-Type", "application/json")
+		require.Nil(t, json.NewEncoder(writer).Encode(value))
+	}
+	mux.HandleFunc("/.well-known/openid-configuration", func(writer http.ResponseWriter, request *http.Request) {
+		writeJson(writer, map[string]any{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"userinfo_endpoint":      server.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/token", func(writer http.ResponseWriter, request *http.Request) {
+		require.Nil(t, request.ParseForm())
+		if request.PostForm.Get("code") != "code" || request.PostForm.Get("code_verifier") != "verifier" {
+			writer.WriteHeader(http.StatusBadRequest)
+			writeJson(writer, map[string]any{"error": "invalid_grant"})
+			return
+		}
+		writeJson(writer, map[string]any{"access_token": "access", "token_type": "Bearer", "expires_in": 60})
+	})
+	mux.HandleFunc("/userinfo", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer access" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJson(writer, userInfo)
+	})
+	return server
+}
+
+func TestOidcIdentityProvider(t *testing.T) {
+	ctx := context.Background()
+	t.Run("login", func(t *testing.T) {
+		server := mockOidcServer(t, map[string]any{"sub": "42", "preferred_username": "sivukhin"})
+		provider, err := NewIdentityProvider(ctx, IdentityProviderConfig{Provider: "oidc", ClientId: "client", BaseUrl: server.URL + "/"})
+		require.Nil(t, err)
+		require.Equal(t, server.URL+"/authorize", provider.OauthConfig().Endpoint.AuthURL)
+
+		token, err := provider.OauthConfig().Exchange(ctx, "code", oauth2.VerifierOption("verifier"))
+		require.Nil(t, err)
+		identity, err := provider.Identify(ctx, token)
+		require.Nil(t, err)
+		require.Equal(t, Identity{Login: "42", Name: "sivukhin"}, identity)
+	})
+	t.Run("custom claim", func(t *testing.T) {
+		server := mockOidcServer(t, map[string]any{"sub": "42", "email": "sivukhin@example.com"})
+		provider, err := NewIdentityProvider(ctx, IdentityProviderConfig{Provider: "oidc", BaseUrl: server.URL, NameClaim: "email"})
+		require.Nil(t, err)
+		token, err := provider.OauthConfig().Exchange(ctx, "code", oauth2.VerifierOption("verifier"))
+		require.Nil(t, err)
+		identity, err := provider.Identify(ctx, token)
+		require.Nil(t, err)
+		require.Equal(t, Identity{Login: "42", Name: "sivukhin@example.com"}, identity)
+	})
+	t.Run("no name claim", func(t *testing.T) {
+		server := mockOidcServer(t, map[string]any{"sub": "42"})
+		provider, err := NewIdentityProvider(ctx, IdentityProviderConfig{Provider: "oidc", BaseUrl: server.URL})
+		require.Nil(t, err)
+		token, err := provider.OauthConfig().Exchange(ctx, "code", oauth2.VerifierOption("verifier"))
+		require.Nil(t, err)
+		identity, err := provider.Identify(ctx, token)
+		require.Nil(t, err)
+		require.Equal(t, Identity{Login: "42", Name: "42"}, identity)
+	})
+	t.Run("no subject", func(t *testing.T) {
+		server := mockOidcServer(t, map[string]any{"preferred_username": "sivukhin"})
+		provider, err := NewIdentityProvider(ctx, IdentityProviderConfig{Provider: "oidc", BaseUrl: server.URL})
+		require.Nil(t, err)
+		token, err := provider.OauthConfig().Exchange(ctx, "code", oauth2.VerifierOption("verifier"))
+		require.Nil(t, err)
+		_, err = provider.Identify(ctx, token)
+		require.NotNil(t, err)
+	})
+	t.Run("wrong verifier", func(t *testing.T) {
+		server := mockOidcServer(t, map[string]any{"preferred_username": "sivukhin"})
+		provider, err := NewIdentityProvider(ctx, IdentityProviderConfig{Provider: "oidc", BaseUrl: server.URL})
+		require.Nil(t, err)
+		_, err = provider.OauthConfig().Exchange(ctx, "code", oauth2.VerifierOption("other"))
+		require.NotNil(t, err)
+	})
+	t.Run("unknown issuer", func(t *testing.T) {
+		server := mockOidcServer(t, nil)
+		_, err := NewIdentityProvider(ctx, IdentityProviderConfig{Provider: "oidc", BaseUrl: server.URL + "/realms/other"})
+		require.NotNil(t, err)
+	})
+}
+
+func TestGiteaIdentityProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, "/api/v1/user", request.URL.Path)
+		_, _ = writer.Write([]byte(`{"id": 1, "login": "sivukhin"}`))
+	}))
+	defer server.Close()
+	provider, err := NewIdentityProvider(context.Background(), IdentityProviderConfig{Provider: "gitea", BaseUrl: server.URL})
+	require.Nil(t, err)
+	require.Equal(t, server.URL+"/login/oauth/access_token", provider.OauthConfig().Endpoint.TokenURL)
+	identity, err := provider.Identify(context.Background(), &oauth2.Token{AccessToken: "access"})
+	require.Nil(t, err)
+	require.Equal(t, Identity{Login: "sivukhin", Name: "sivukhin"}, identity)
+
+	_, err = NewIdentityProvider(context.Background(), IdentityProviderConfig{Provider: "bitbucket"})
+	require.NotNil(t, err)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
@@ -12,6 +13,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/go-units"
+
 	"github.com/sivukhin/gobughunt/lib/dto"
 	"github.com/sivukhin/gobughunt/lib/logging"
 )
@@ -19,16 +22,24 @@ import (
 const ContainerBindPath = "/home/repo"
 
 type NaiveLinting struct {
-	TempDir   string
-	DockerApi DockerApi
-	GitApi    GitApi
+	TempDir       string
+	DockerApi     DockerApi
+	GitApi        GitApi
+	MaxHighlights int // only first highlights are reported with LintNoisyErr if linter found more of them
 }
 
 type Linting interface {
-	Run(ctx context.Context, repo dto.RepoInstance, linter dto.LinterInstance) ([]dto.LintHighlightSnippet, error)
+	Run(ctx context.Context, repo dto.RepoInstance, linter dto.LinterInstance) (LintOutput, error)
+}
+
+// LintOutput holds highlights of the linter, its raw output and resources it consumed - logs and usage are reported even if lint failed
+type LintOutput struct {
+	Highlights []dto.LintHighlightSnippet
+	Logs       dto.LintLogs
+	Usage      dto.ResourceUsage
 }
 
-var Lint = NaiveLinting{DockerApi: Docker, GitApi: Git}
+var Lint = NaiveLinting{DockerApi: Docker, GitApi: Git, MaxHighlights: 10_000}
 
 var (
 	LintTempErr    = errors.New("lint failed with temp error")
@@ -36,19 +47,26 @@ var (
 	LintCloneErr   = errors.New("lint clone failed")
 	LintExecErr    = errors.New("lint exec failed")
 	LintSkippedErr = errors.New("lint skipped")
+	LintTimeoutErr = errors.New("lint timed out")
+	LintOOMErr     = errors.New("lint ran out of memory")
+	LintCrashErr   = errors.New("lint crashed")
+	// LintNoisyErr returned together with the truncated result if linter exceeded output or highlights limits
+	LintNoisyErr = errors.New("lint is too noisy")
+	// LintLockLostErr returned when another worker took the task because our lock expired
+	LintLockLostErr = errors.New("lint task lock lost")
 )
 
 func (l NaiveLinting) Run(
 	ctx context.Context,
 	repo dto.RepoInstance,
 	linter dto.LinterInstance,
-) ([]dto.LintHighlightSnippet, error) {
+) (LintOutput, error) {
 	logging.Logger.Infof("start linting repo %v with linter %v", repo, linter)
 	lintStartTime := time.Now()
 
 	targetDir, err := os.MkdirTemp(l.TempDir, "repo_clone_*")
 	if err != nil {
-		return nil, fmt.Errorf("%w: mkdir temp failed: %w", LintTempErr, err)
+		return LintOutput{}, fmt.Errorf("%w: mkdir temp failed: %w", LintTempErr, err)
 	}
 	defer func() {
 		err := os.RemoveAll(targetDir)
@@ -62,55 +80,102 @@ func (l NaiveLinting) Run(
 	_, err = l.GitApi.Fetch(ctx, repo.GitUrl, dto.GitRef{CommitHash: repo.GitCommitHash}, targetDir)
 	if err != nil {
 		logging.Logger.Errorf("clone of repo %v to the directory %v failed: err=%v, elapsed=%v", repo, targetDir, err, time.Since(cloneStartTime))
-		return nil, fmt.Errorf("%w: clone of repo %v failed: %w", LintCloneErr, repo, err)
+		return LintOutput{}, fmt.Errorf("%w: clone of repo %v failed: %w", LintCloneErr, repo, err)
 	} else {
 		logging.Logger.Infof("clone of repo %v to the directory %v succeeded: elapsed=%v", repo, targetDir, time.Since(cloneStartTime))
 	}
 
+	// linter reports its SARIF result at the well-known path - so we must not trust the file from the repo itself
+	err = os.Remove(path.Join(targetDir, SarifResultFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return LintOutput{}, fmt.Errorf("%w: unable to remove SARIF report from the repo: %w", LintTempErr, err)
+	}
+
 	execStartTime := time.Now()
 	targetDirAbs, err := filepath.Abs(targetDir)
 	if err != nil {
-		return nil, fmt.Errorf("%w: unable to get absolute path for directory %v: %w", LintExecErr, targetDir, err)
+		return LintOutput{}, fmt.Errorf("%w: unable to get absolute path for directory %v: %w", LintExecErr, targetDir, err)
 	}
 	logging.Logger.Infof("ready to lint repo %v with linter %v", repo, linter)
-	lines, err := l.DockerApi.Exec(
+	exec, err := l.DockerApi.Exec(
 		ctx,
 		fmt.Sprintf("%v@sha256:%v", linter.DockerImage, linter.DockerImageShaHash),
 		ContainerBindPath,
 		targetDirAbs,
 	)
-	if err != nil {
-		logging.Logger.Errorf("exec of the linter %v against repo %v failed: err=%v, lines=%v, elapsed=%v", linter, repo, err, lines, time.Since(execStartTime))
+	lines, output := exec.Lines, LintOutput{Logs: exec.Logs, Usage: exec.Usage}
+	var noisyErr error
+	if errors.Is(err, DockerOutputLimitErr) {
+		// container was killed by us, so the highlights from the output read so far are still valid
+		logging.Logger.Errorf("exec of the linter %v against repo %v exceeded output limits: err=%v, len(lines)=%v, elapsed=%v", linter, repo, err, len(lines), time.Since(execStartTime))
+		noisyErr = fmt.Errorf("%w: linter %v on repo %v: %w", LintNoisyErr, linter.Id, repo.Id, err)
+	} else if err != nil {
+		logging.Logger.Errorf("exec of the linter %v against repo %v failed: err=%v, len(lines)=%v, elapsed=%v", linter, repo, err, len(lines), time.Since(execStartTime))
 
-		if errors.Is(err, DockerNonZeroExitCodeErr) {
-			return nil, fmt.Errorf("%w: linter %v exited with non-zero code: %w", LintExecErr, linter, err)
+		var exitErr DockerExitCodeErr
+		if errors.As(err, &exitErr) && exitErr.OOMKilled && exitErr.MemoryLimit > 0 {
+			limit := units.BytesSize(float64(exitErr.MemoryLimit))
+			return output, fmt.Errorf("%w: linter %v needs more than %v of memory on repo %v: %w", LintOOMErr, linter.Id, limit, repo.Id, err)
+		} else if errors.As(err, &exitErr) && exitErr.OOMKilled {
+			return output, fmt.Errorf("%w: linter %v ran out of memory on repo %v: %w", LintOOMErr, linter.Id, repo.Id, err)
+		} else if errors.As(err, &exitErr) && exitErr.Signal() != 0 {
+			return output, fmt.Errorf("%w: linter %v was terminated by signal %q on repo %v: %w", LintCrashErr, linter.Id, exitErr.Signal(), repo.Id, err)
+		} else if errors.Is(err, DockerNonZeroExitCodeErr) {
+			return output, fmt.Errorf("%w: linter %v exited with non-zero code: %w", LintExecErr, linter, err)
+		} else if errors.Is(err, DockerDeadlineErr) {
+			return output, fmt.Errorf("%w: linter %v didn't finish in %v on repo %v: %w", LintTimeoutErr, linter.Id, time.Since(lintStartTime).Round(time.Second), repo.Id, err)
 		}
-		return nil, fmt.Errorf("%w: %w", LintExecErr, err)
+		return output, fmt.Errorf("%w: %w", LintExecErr, err)
 	} else {
 		logging.Logger.Infof("exec of the linter %v against repo %v succeed: elapsed=%v", linter, repo, time.Since(execStartTime))
 	}
 	highlights, skipped := ExtractHighlights(lines)
-	logging.Logger.Infof("linting of repo %v with linter %v succeed: len(highlights)=%v, skipped=%v, elapsed=%v", repo, linter, len(highlights), skipped, time.Since(lintStartTime))
 	if skipped {
-		return nil, LintSkippedErr
+		logging.Logger.Infof("linting of repo %v with linter %v skipped: elapsed=%v", repo, linter, time.Since(lintStartTime))
+		return output, LintSkippedErr
 	}
+	if noisyErr == nil { // SARIF report of the killed linter can be incomplete
+		sarifHighlights, err := ExtractSarifOutput(targetDir, lines)
+		if err != nil {
+			return output, fmt.Errorf("%w: failed to extract SARIF highlights: %w", LintFatalErr, err)
+		}
+		highlights = append(highlights, sarifHighlights...)
+	}
+	highlights = append(highlights, ExtractVetOutput(lines)...)
+	highlights = append(highlights, ExtractGolangciOutput(lines)...)
+	if l.MaxHighlights > 0 && len(highlights) > l.MaxHighlights {
+		noisyErr = errors.Join(noisyErr, fmt.Errorf("%w: linter %v reported %v highlights on repo %v, only first %v are kept", LintNoisyErr, linter.Id, len(highlights), repo.Id, l.MaxHighlights))
+		highlights = highlights[:l.MaxHighlights]
+	}
+	logging.Logger.Infof("linting of repo %v with linter %v succeed: len(highlights)=%v, elapsed=%v", repo, linter, len(highlights), time.Since(lintStartTime))
 	highlightSnippets, err := ExtractHighlightSnippets(targetDir, highlights)
 	if err != nil {
-		return nil, fmt.Errorf("%w: failed to extract snippets: %w", LintFatalErr, err)
+		return output, fmt.Errorf("%w: failed to extract snippets: %w", LintFatalErr, err)
 	}
-	return highlightSnippets, nil
+	output.Highlights = highlightSnippets
+	return output, noisyErr
 }
 
 var (
 	// GitHub actions formatting
 	// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-a-warning-message
 	ghDelimiter         = "::"
-	ghSupportedPrefixes = [...]string{"::warning ", "::error "}
-	ghMessageProp       = "message"
-	ghTitleProp         = "title"
-	ghFileProp          = "file"
-	ghStartLineProp     = "line"
-	ghEndLineProp       = "endLine"
+	ghSupportedPrefixes = [...]struct {
+		prefix   string
+		severity dto.Severity
+	}{
+		{prefix: "::warning ", severity: dto.SeverityWarning},
+		{prefix: "::error ", severity: dto.SeverityError},
+		{prefix: "::notice ", severity: dto.SeverityNotice},
+	}
+	ghMessageProp     = "message"
+	ghTitleProp       = "title"
+	ghFileProp        = "file"
+	ghStartLineProp   = "line"
+	ghEndLineProp     = "endLine"
+	ghStartColumnProp = "col"
+	ghEndColumnProp   = "endColumn"
+	ghRuleProp        = "rule" // gobughunt extension: GitHub has no dedicated property for the check identifier
 )
 
 var (
@@ -122,7 +187,13 @@ func ExtractHighlightSnippets(targetDir string, highlights []dto.LintHighlight)
 
 	files := make(map[string][]dto.LintHighlight)
 	for _, highlight := range highlights {
-		fullPath := path.Join(targetDir, highlight.Path)
+		fullPath, err := repoFilePath(targetDir, highlight.Path)
+		if errors.Is(err, errPathOutsideRepo) {
+			logging.Logger.Errorf("skipping highlight outside of the repo: %v", err)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
 		files[fullPath] = append(files[fullPath], highlight)
 	}
 	for fullPath, fileHighlights := range files {
@@ -134,11 +205,35 @@ func ExtractHighlightSnippets(targetDir string, highlights []dto.LintHighlight)
 		if err != nil {
 			return nil, fmt.Errorf("unable to extract snippets for file '%v': %w", fullPath, err)
 		}
+		FingerprintHighlights(content, fileHighlightSnippets)
 		highlightSnippets = append(highlightSnippets, fileHighlightSnippets...)
 	}
 	return highlightSnippets, nil
 }
 
+var errPathOutsideRepo = errors.New("highlight path is outside of the repo")
+
+// repoFilePath resolves path reported by the linter to the file inside the repo dir.
+// Linter is untrusted and has write access to the repo dir - so both ".." paths and symlinks pointing outside of the repo are rejected
+func repoFilePath(targetDir, highlightPath string) (string, error) {
+	if !isRepoRelativePath(highlightPath) {
+		return "", fmt.Errorf("%w: '%v'", errPathOutsideRepo, highlightPath)
+	}
+	root, err := filepath.EvalSymlinks(targetDir)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve repo dir '%v': %w", targetDir, err)
+	}
+	fullPath, err := filepath.EvalSymlinks(filepath.Join(root, filepath.FromSlash(path.Clean(highlightPath))))
+	if err != nil {
+		return "", fmt.Errorf("unable to read file '%v': %w", highlightPath, err)
+	}
+	relative, err := filepath.Rel(root, fullPath)
+	if err != nil || !isRepoRelativePath(filepath.ToSlash(relative)) {
+		return "", fmt.Errorf("%w: '%v' resolved to '%v'", errPathOutsideRepo, highlightPath, fullPath)
+	}
+	return fullPath, nil
+}
+
 const snippetSurroundingLines = 2
 
 func ExtractHighlightSnippetsForFile(content []byte, highlights []dto.LintHighlight) ([]dto.LintHighlightSnippet, error) {
@@ -192,9 +287,11 @@ func ExtractHighlights(rawLines []string) (highlights []dto.LintHighlight, skipp
 			return nil, true
 		}
 		var suffix string
+		var severity dto.Severity
 		var ok bool
-		for _, prefix := range ghSupportedPrefixes {
-			if suffix, ok = strings.CutPrefix(line, prefix); ok {
+		for _, supported := range ghSupportedPrefixes {
+			if suffix, ok = strings.CutPrefix(line, supported.prefix); ok {
+				severity = supported.severity
 				break
 			}
 		}
@@ -228,22 +325,17 @@ func ExtractHighlights(rawLines []string) (highlights []dto.LintHighlight, skipp
 			logging.Logger.Debugf("file attribute absent in output string: line='%v'", line)
 			continue
 		}
-		var explanation string
-		title := attributes[ghTitleProp]
-		message := attributes[ghMessageProp]
-		if title != "" && message != "" {
-			explanation = title + ": " + message
-		} else if title != "" {
-			explanation = title
-		} else if message != "" {
-			explanation = message
-		}
-
+		startColumn, _ := strconv.Atoi(attributes[ghStartColumnProp])
+		endColumn, _ := strconv.Atoi(attributes[ghEndColumnProp])
 		highlights = append(highlights, dto.LintHighlight{
 			Path:        highlightPath,
 			StartLine:   startLine,
 			EndLine:     endLine,
-			Explanation: explanation,
+			StartColumn: max(0, startColumn),
+			EndColumn:   max(0, endColumn),
+			Severity:    severity,
+			RuleId:      attributes[ghRuleProp],
+			Explanation: joinExplanation(attributes[ghTitleProp], attributes[ghMessageProp]),
 		})
 	}
 	return highlights, false
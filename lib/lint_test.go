@@ -2,6 +2,10 @@ package lib
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -13,56 +17,157 @@ import (
 func TestDumb(t *testing.T) {
 	t.Run("non-zero exit code", func(t *testing.T) {
 		d := t.TempDir()
-		lines, err := Docker.Exec(
+		result, err := Docker.Exec(
 			context.Background(),
 			"docker.io/sivukhinnikita/dumb-fail:1.0.0@sha256:acc0726e21d1e9ea1c205216ad74c9d647b8f126d26af3586603462255fef969",
 			d,
 			"/src",
 		)
-		t.Log(lines, err)
+		t.Log(result.Lines, result.Usage, err)
 		require.ErrorIs(t, err, DockerNonZeroExitCodeErr)
 	})
 	t.Run("long", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		d := t.TempDir()
-		lines, err := Docker.Exec(
+		result, err := Docker.Exec(
 			ctx,
 			"docker.io/sivukhinnikita/dumb-long:1.0.0@sha256:79844422ce2abefacdd5451a098944293864942e37e10b0e84c8b687c098780a",
 			d,
 			"/src",
 		)
-		t.Log(lines, err)
+		t.Log(result.Lines, result.Usage, err)
 		require.NotNil(t, err)
 	})
 	t.Run("mem", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		d := t.TempDir()
-		lines, err := NaiveDockerApi{MemoryBytes: 128 * 1024 * 1024}.Exec(
+		result, err := NaiveDockerApi{MemoryBytes: 128 * 1024 * 1024}.Exec(
 			ctx,
 			"docker.io/sivukhinnikita/dumb-mem:1.0.0@sha256:1405e034c51723503eff603a3e0134be2b1471b216161679011e2fc9e6030131",
 			d,
 			"/src",
 		)
-		t.Log(lines, err)
+		t.Log(result.Lines, result.Usage, err)
 		require.ErrorContains(t, err, "non zero exit code: 137")
+		require.ErrorIs(t, err, DockerOOMKilledErr)
 	})
 	t.Run("fork", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 		d := t.TempDir()
-		lines, err := NaiveDockerApi{MemoryBytes: 128 * 1024 * 1024, CpuMilli: 100, PidLimit: 1024}.Exec(
+		result, err := NaiveDockerApi{MemoryBytes: 128 * 1024 * 1024, CpuMilli: 100, PidLimit: 1024}.Exec(
 			ctx,
 			"docker.io/sivukhinnikita/dumb-fork:1.0.0@sha256:4313537ddc991431929700790b060a3daa639c37144d745fb364a4655eabc989",
 			d,
 			"/src",
 		)
-		t.Log(lines, err)
+		t.Log(result.Lines, result.Usage, err)
 		require.ErrorContains(t, err, "non zero exit code: 2")
 	})
 }
 
+// execDocker finishes every exec with the given output lines and error
+type execDocker struct {
+	DockerApi
+	lines []string
+	err   error
+}
+
+func (d execDocker) Exec(ctx context.Context, dockerImage string, containerBindPath, localBindPath string) (DockerExecResult, error) {
+	return DockerExecResult{Lines: d.lines, Usage: dto.ResourceUsage{Samples: 1, CpuSeconds: 1.5}}, d.err
+}
+
+// filesGit writes the files of the repo to the target directory
+type filesGit struct {
+	fakeGit
+	files map[string]string
+}
+
+func (g filesGit) Fetch(ctx context.Context, gitUrl string, gitRef dto.GitRef, targetDir string) (GitRepo, error) {
+	for name, content := range g.files {
+		err := os.WriteFile(filepath.Join(targetDir, name), []byte(content), 0o644)
+		if err != nil {
+			return GitRepo{}, err
+		}
+	}
+	return g.fakeGit.Fetch(ctx, gitUrl, gitRef, targetDir)
+}
+
+func TestLintExecFailures(t *testing.T) {
+	repo := dto.RepoInstance{Id: "hugo", GitUrl: "https://github.com/sivukhin/hugo", GitCommitHash: "c1"}
+	linter := dto.LinterInstance{Id: "nilaway", DockerImage: "nilaway:1", DockerImageShaHash: "1"}
+	lint := func(err error) error {
+		git := fakeGit{commits: map[string]string{repo.GitUrl: repo.GitCommitHash}}
+		output, err := NaiveLinting{TempDir: t.TempDir(), DockerApi: execDocker{err: err}, GitApi: git}.Run(context.Background(), repo, linter)
+		require.Equal(t, 1.5, output.Usage.CpuSeconds) // usage is reported for failed lint too
+		return err
+	}
+	t.Run("oom", func(t *testing.T) {
+		err := lint(DockerExitCodeErr{ExitCode: 137, OOMKilled: true, MemoryLimit: 4 * 1024 * 1024 * 1024})
+		require.ErrorIs(t, err, LintOOMErr)
+		require.ErrorContains(t, err, "linter nilaway needs more than 4GiB of memory on repo hugo")
+	})
+	t.Run("signal", func(t *testing.T) {
+		err := lint(DockerExitCodeErr{ExitCode: 139})
+		require.ErrorIs(t, err, LintCrashErr)
+		require.ErrorContains(t, err, "segmentation fault")
+	})
+	t.Run("exit code", func(t *testing.T) {
+		err := lint(DockerExitCodeErr{ExitCode: 2})
+		require.ErrorIs(t, err, LintExecErr)
+		require.NotErrorIs(t, err, LintCrashErr)
+	})
+	t.Run("deadline", func(t *testing.T) {
+		err := lint(fmt.Errorf("%w: %w", DockerDeadlineErr, context.DeadlineExceeded))
+		require.ErrorIs(t, err, LintTimeoutErr)
+		require.ErrorContains(t, err, "linter nilaway didn't finish in")
+	})
+	t.Run("output limit", func(t *testing.T) {
+		// container killed because of the output limit must not be reported as crash
+		err := lint(errors.Join(fmt.Errorf("%w: output is larger than 1MiB", DockerOutputLimitErr), DockerExitCodeErr{ExitCode: 137}))
+		require.ErrorIs(t, err, LintNoisyErr)
+		require.NotErrorIs(t, err, LintCrashErr)
+		require.ErrorContains(t, err, "output is larger than 1MiB")
+	})
+}
+
+func TestLintNoisy(t *testing.T) {
+	repo := dto.RepoInstance{Id: "hugo", GitUrl: "https://github.com/sivukhin/hugo", GitCommitHash: "c1"}
+	linter := dto.LinterInstance{Id: "nilaway", DockerImage: "nilaway:1", DockerImageShaHash: "1"}
+	git := filesGit{
+		fakeGit: fakeGit{commits: map[string]string{repo.GitUrl: repo.GitCommitHash}},
+		files:   map[string]string{"a.go": "package a\n\nfunc a() {\n}\n"},
+	}
+	lines := []string{
+		"::warning file=a.go,line=1::first",
+		"::warning file=a.go,line=3::second",
+		"::warning file=a.go,line=4::third",
+	}
+	run := func(docker execDocker, maxHighlights int) (LintOutput, error) {
+		linting := NaiveLinting{TempDir: t.TempDir(), DockerApi: docker, GitApi: git, MaxHighlights: maxHighlights}
+		return linting.Run(context.Background(), repo, linter)
+	}
+	t.Run("within limits", func(t *testing.T) {
+		output, err := run(execDocker{lines: lines}, 3)
+		require.Nil(t, err)
+		require.Len(t, output.Highlights, 3)
+	})
+	t.Run("highlights limit", func(t *testing.T) {
+		output, err := run(execDocker{lines: lines}, 2)
+		require.ErrorIs(t, err, LintNoisyErr)
+		require.ErrorContains(t, err, "linter nilaway reported 3 highlights on repo hugo, only first 2 are kept")
+		require.Len(t, output.Highlights, 2)
+	})
+	t.Run("output limit", func(t *testing.T) {
+		output, err := run(execDocker{lines: lines[:1], err: fmt.Errorf("%w: line is longer than 1MiB", DockerOutputLimitErr)}, 0)
+		require.ErrorIs(t, err, LintNoisyErr)
+		require.Len(t, output.Highlights, 1)
+		require.Equal(t, "first", output.Highlights[0].Explanation)
+	})
+}
+
 func TestLint(t *testing.T) {
 	repo := dto.RepoInstance{
 		Id:            "test-repo",
@@ -74,8 +179,34 @@ func TestLint(t *testing.T) {
 		DockerImage:        "docker.io/sivukhinnikita/nilaway:4.0.0",
 		DockerImageShaHash: "8639b178ae41861765a8149f298353bcac36de4b9e1de3bfe175d92953591fd0",
 	}
-	highlights, err := Lint.Run(context.Background(), repo, linter)
-	t.Log(highlights, err)
+	output, err := Lint.Run(context.Background(), repo, linter)
+	t.Log(output.Highlights, output.Usage, err)
+}
+
+func TestExtractHighlightSnippets(t *testing.T) {
+	outside := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(outside, "config.json"), []byte("secret"), 0o644))
+	repo := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(repo, "a.go"), []byte("package a\n"), 0o644))
+	// linter can create symlinks in the repo dir mounted to its container
+	require.Nil(t, os.Symlink(filepath.Join(outside, "config.json"), filepath.Join(repo, "link.go")))
+	require.Nil(t, os.Symlink("a.go", filepath.Join(repo, "b.go")))
+
+	snippets, err := ExtractHighlightSnippets(repo, []dto.LintHighlight{
+		{Path: "a.go", StartLine: 1, EndLine: 1},
+		{Path: "b.go", StartLine: 1, EndLine: 1},
+		{Path: "../" + filepath.Base(outside) + "/config.json", StartLine: 1, EndLine: 1},
+		{Path: filepath.Join(outside, "config.json"), StartLine: 1, EndLine: 1},
+		{Path: "link.go", StartLine: 1, EndLine: 1},
+	})
+	require.Nil(t, err)
+	require.Len(t, snippets, 2)
+	for _, snippet := range snippets {
+		require.Equal(t, "package a\n", snippet.Snippet.Code)
+	}
+
+	_, err = ExtractHighlightSnippets(repo, []dto.LintHighlight{{Path: "missing.go", StartLine: 1, EndLine: 1}})
+	require.NotNil(t, err)
 }
 
 func TestExtractHighlightSnippetsForFile(t *testing.T) {
@@ -119,23 +250,36 @@ func TestExtractHighlights(t *testing.T) {
 			`::warning file=internal/vfs/cryptfs.go,line=262,endLine=263::seems like code vanished from compiled binary`,
 			`::warning file=internal/service/signals_unix.go,line=77::seems like code vanished from compiled binary`,
 			`::error file=internal/service/signals_unix.go,line=77,title=Bug::seems like code vanished from compiled binary`,
+			`::notice file=main.go,line=3,endLine=4,col=2,endColumn=7,rule=govanish::seems like code vanished from compiled binary`,
 		})
 		require.False(t, skipped)
 		require.Equal(t, []dto.LintHighlight{{
 			Path:        "internal/vfs/cryptfs.go",
 			StartLine:   262,
 			EndLine:     263,
+			Severity:    dto.SeverityWarning,
 			Explanation: "seems like code vanished from compiled binary",
 		}, {
 			Path:        "internal/service/signals_unix.go",
 			StartLine:   77,
 			EndLine:     77,
+			Severity:    dto.SeverityWarning,
 			Explanation: "seems like code vanished from compiled binary",
 		}, {
 			Path:        "internal/service/signals_unix.go",
 			StartLine:   77,
 			EndLine:     77,
+			Severity:    dto.SeverityError,
 			Explanation: "Bug: seems like code vanished from compiled binary",
+		}, {
+			Path:        "main.go",
+			StartLine:   3,
+			EndLine:     4,
+			StartColumn: 2,
+			EndColumn:   7,
+			Severity:    dto.SeverityNotice,
+			RuleId:      "govanish",
+			Explanation: "seems like code vanished from compiled binary",
 		}}, highlights)
 		t.Log(highlights, skipped)
 	})
@@ -2,8 +2,10 @@ package lib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/Microsoft/go-winio/pkg/guid"
@@ -18,9 +20,11 @@ import (
 )
 
 type Manager struct {
-	Storage             *db.Queries
+	Storage             storage.Queries
 	DockerApi           DockerApi
 	GitApi              GitApi
+	DockerRegistry      string // linter images are built only if registry is set
+	BuildTimeout        time.Duration
 	FetchTimeout        time.Duration
 	RefreshTimeout      time.Duration
 	ScheduleTimeout     time.Duration
@@ -30,7 +34,9 @@ type Manager struct {
 
 func (m Manager) ManageForever(ctx context.Context) {
 	logging.Logger.Infof(
-		"manager started: fetchTimeout=%v, refreshTimeout=%v, scheduleTimeout=%v, managerFailDelay=%v, managerSuccessDelay=%v",
+		"manager started: dockerRegistry=%v, buildTimeout=%v, fetchTimeout=%v, refreshTimeout=%v, scheduleTimeout=%v, managerFailDelay=%v, managerSuccessDelay=%v",
+		m.DockerRegistry,
+		m.BuildTimeout,
 		m.FetchTimeout,
 		m.RefreshTimeout,
 		m.ScheduleTimeout,
@@ -38,7 +44,38 @@ func (m Manager) ManageForever(ctx context.Context) {
 		m.ManagerSuccessDelay,
 	)
 	periodic := timeout.Periodic(ctx, m.ManagerFailDelay, m.ManagerSuccessDelay)
-	repos := timeout.Process("fetch-repos", periodic, m.FetchTimeout, func(ctx context.Context, _ struct{}, next func(result dto.Repo)) error {
+	build := timeout.Process("build-linters", periodic, m.BuildTimeout, func(ctx context.Context, _ struct{}, next func(struct{})) error {
+		if m.DockerRegistry == "" {
+			next(struct{}{})
+			return nil
+		}
+		linters, err := m.Storage.ListLinters(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch all linters: %w", err)
+		}
+		for _, linter := range linters {
+			// linters submitted as prebuilt images have no git repo to build from
+			if linter.LinterStatus != db.LinterStatusActive || linter.LinterGitUrl == "" {
+				continue
+			}
+			meta := dto.LinterMeta{Id: linter.LinterID, GitUrl: linter.LinterGitUrl, GitBranch: linter.LinterGitBranch}
+			logging.Logger.Infof("starting refresh of the linter %+v", meta)
+			updated, err := m.RefreshLinter(
+				ctx,
+				meta,
+				storage.TryGetText(linter.LinterLastGitCommitHash),
+				storage.TryGetText(linter.LinterFailedGitCommitHash),
+			)
+			if err != nil {
+				logging.Logger.Errorf("failed refresh of linter %+v: %v", meta, err)
+			} else {
+				logging.Logger.Infof("succeeded with refresh of linter %+v", updated)
+			}
+		}
+		next(struct{}{})
+		return nil
+	})
+	repos := timeout.Process("fetch-repos", build, m.FetchTimeout, func(ctx context.Context, _ struct{}, next func(result dto.Repo)) error {
 		repos, err := m.Storage.ListRepos(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to fetch all repos: %w", err)
@@ -81,6 +118,9 @@ func (m Manager) ManageForever(ctx context.Context) {
 		}
 		linters := make([]dto.Linter, 0)
 		for _, linter := range allLinters {
+			if linter.LinterStatus != db.LinterStatusActive {
+				continue
+			}
 			dockerImage, _ := linter.LinterLastDockerImage.Value()
 			dockerImageSha, _ := linter.LinterLastDockerShaHash.Value()
 			if dockerImage == nil || dockerImageSha == nil {
@@ -88,6 +128,7 @@ func (m Manager) ManageForever(ctx context.Context) {
 			}
 			instance := &dto.LinterInstance{
 				Id:                 linter.LinterID,
+				GitCommitHash:      linter.LinterLastGitCommitHash.String,
 				DockerImage:        dockerImage.(string),
 				DockerImageShaHash: dockerImageSha.(string),
 			}
@@ -151,6 +192,79 @@ func (m Manager) RefreshRepo(ctx context.Context, repo dto.Repo) (dto.Repo, erro
 	return updated, err
 }
 
+// RefreshLinter fetches the linter repo and, if a new commit appeared, builds and pushes its Docker image
+// New image digest is recorded in the storage so the scheduler will create fresh lint tasks for it on the next iteration.
+// Commit which failed to build is recorded too - it's not rebuilt until new commit appears or the linter source is updated
+func (m Manager) RefreshLinter(ctx context.Context, linter dto.LinterMeta, lastCommitHash, failedCommitHash *string) (dto.Linter, error) {
+	targetDir, err := os.MkdirTemp("", "linter_clone_*")
+	if err != nil {
+		return dto.Linter{}, fmt.Errorf("mkdir temp failed: %w", err)
+	}
+	defer func() {
+		err := os.RemoveAll(targetDir)
+		if err != nil {
+			logging.Logger.Errorf("failed to remove temp dir %v: %v", targetDir, err)
+		}
+	}()
+	info, err := m.GitApi.Fetch(ctx, linter.GitUrl, dto.GitRef{Branch: linter.GitBranch}, targetDir)
+	if err != nil {
+		return dto.Linter{}, fmt.Errorf("failed to fetch linter %v: %w", linter, err)
+	}
+	if lastCommitHash != nil && *lastCommitHash == info.CommitHash {
+		logging.Logger.Infof("linter %+v is up to date: commit=%v", linter, info.CommitHash)
+		return dto.Linter{Meta: linter}, nil
+	}
+	if failedCommitHash != nil && *failedCommitHash == info.CommitHash {
+		logging.Logger.Infof("linter %+v is skipped: build failed before at commit=%v", linter, info.CommitHash)
+		return dto.Linter{Meta: linter}, nil
+	}
+	dockerImage := LinterDockerImage(m.DockerRegistry, linter.Id, info.CommitHash)
+	dockerImageShaHash, err := m.DockerApi.Build(ctx, dockerImage, targetDir)
+	if errors.Is(err, DockerBuildErr) {
+		// image of the commit will fail to build again - while errors of the registry or the docker daemon are retried
+		err = fmt.Errorf("failed to build linter %v at commit %v: %w", linter, info.CommitHash, err)
+		storeErr := m.Storage.SetLinterBuildFailure(ctx, db.SetLinterBuildFailureParams{
+			LinterID:                  linter.Id,
+			LinterFailedGitCommitHash: pgtype.Text{String: info.CommitHash, Valid: true},
+			LinterBuildError:          pgtype.Text{String: err.Error(), Valid: true},
+		})
+		return dto.Linter{}, errors.Join(err, storeErr)
+	} else if err != nil {
+		return dto.Linter{}, fmt.Errorf("failed to build linter %v at commit %v: %w", linter, info.CommitHash, err)
+	}
+	updated := dto.Linter{
+		Meta: linter,
+		Instance: &dto.LinterInstance{
+			Id:                 linter.Id,
+			GitCommitHash:      info.CommitHash,
+			DockerImage:        dockerImage,
+			DockerImageShaHash: dockerImageShaHash,
+		},
+	}
+	err = m.Storage.UpsertLinter(ctx, db.UpsertLinterParams{
+		LinterID:                linter.Id,
+		LinterGitUrl:            linter.GitUrl,
+		LinterGitBranch:         linter.GitBranch,
+		LinterLastGitCommitHash: pgtype.Text{String: info.CommitHash, Valid: true},
+		LinterLastDockerImage:   pgtype.Text{String: dockerImage, Valid: true},
+		LinterLastDockerShaHash: pgtype.Text{String: dockerImageShaHash, Valid: true},
+		CreatedAt:               pgtype.Timestamp{Time: time.Now(), Valid: true},
+	})
+	return updated, err
+}
+
+// LinterDockerImage returns tagged image name for the linter built from the given commit
+// Linter id is sanitized in order to be a valid Docker repository name
+func LinterDockerImage(registry string, linterId string, commitHash string) string {
+	name := []byte(strings.ToLower(linterId))
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-' || c == '/') {
+			name[i] = '-'
+		}
+	}
+	return fmt.Sprintf("%v/%v:%v", strings.TrimSuffix(registry, "/"), string(name), commitHash)
+}
+
 func (m Manager) ManageOnce(ctx context.Context, repo dto.Repo, linter dto.Linter) error {
 	lintId := utils.Must(guid.NewV4()).String()
 	lintTask := dto.LintTask{Id: lintId, Linter: *linter.Instance, Repo: *repo.Instance}
@@ -0,0 +1,115 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gobughunt/lib/dto"
+	"github.com/sivukhin/gobughunt/storage"
+	"github.com/sivukhin/gobughunt/storage/db"
+)
+
+func TestLinterDockerImage(t *testing.T) {
+	require.Equal(
+		t,
+		"docker.io/gobughunt/uber-go/nilaway:8a1b7e4c",
+		LinterDockerImage("docker.io/gobughunt/", "uber-go/nilaway", "8a1b7e4c"),
+	)
+	require.Equal(
+		t,
+		"localhost:5000/sivukhin/go-vanish-:8a1b7e4c",
+		LinterDockerImage("localhost:5000", "Sivukhin/go vanish!", "8a1b7e4c"),
+	)
+}
+
+// buildDocker counts builds and fails them with the error set by the test for the image
+type buildDocker struct {
+	DockerApi
+	builds *int
+	errs   map[string]error
+}
+
+func (d buildDocker) Build(ctx context.Context, dockerImage string, localContextPath string) (string, error) {
+	*d.builds++
+	if err := d.errs[dockerImage]; err != nil {
+		return "", err
+	}
+	return "sha", nil
+}
+
+func TestRefreshLinter(t *testing.T) {
+	ctx := context.Background()
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	memory := storage.NewMemory()
+	require.Nil(t, memory.UpsertLinter(ctx, db.UpsertLinterParams{LinterID: "nilaway", LinterGitUrl: "https://github.com/uber-go/nilaway", CreatedAt: now}))
+	builds := 0
+	git := fakeGit{commits: map[string]string{}}
+	docker := buildDocker{builds: &builds, errs: map[string]error{}}
+	m := Manager{Storage: memory, DockerApi: docker, GitApi: git, DockerRegistry: "localhost:5000"}
+	refresh := func(commit string) (db.GetLinterRow, error) {
+		git.commits["https://github.com/uber-go/nilaway"] = commit
+		linter, err := memory.GetLinter(ctx, "nilaway")
+		require.Nil(t, err)
+		meta := dto.LinterMeta{Id: linter.LinterID, GitUrl: linter.LinterGitUrl, GitBranch: linter.LinterGitBranch}
+		_, err = m.RefreshLinter(ctx, meta, storage.TryGetText(linter.LinterLastGitCommitHash), storage.TryGetText(linter.LinterFailedGitCommitHash))
+		linter, getErr := memory.GetLinter(ctx, "nilaway")
+		require.Nil(t, getErr)
+		return linter, err
+	}
+	image := func(commit string) string { return LinterDockerImage(m.DockerRegistry, "nilaway", commit) }
+
+	t.Run("broken commit is not rebuilt", func(t *testing.T) {
+		docker.errs[image("c1")] = fmt.Errorf("%w: image %v: COPY failed", DockerBuildErr, image("c1"))
+		linter, err := refresh("c1")
+		require.ErrorIs(t, err, DockerBuildErr)
+		require.Equal(t, 1, builds)
+		require.Equal(t, pgtype.Text{String: "c1", Valid: true}, linter.LinterFailedGitCommitHash)
+		require.Contains(t, linter.LinterBuildError.String, "COPY failed")
+
+		_, err = refresh("c1")
+		require.Nil(t, err)
+		require.Equal(t, 1, builds)
+	})
+	t.Run("new commit", func(t *testing.T) {
+		linter, err := refresh("c2")
+		require.Nil(t, err)
+		require.Equal(t, 2, builds)
+		require.Equal(t, pgtype.Text{String: "c2", Valid: true}, linter.LinterLastGitCommitHash)
+		require.False(t, linter.LinterFailedGitCommitHash.Valid)
+		require.False(t, linter.LinterBuildError.Valid)
+	})
+	t.Run("registry error is retried", func(t *testing.T) {
+		docker.errs[image("c3")] = errors.New("unable to push docker image: connection refused")
+		linter, err := refresh("c3")
+		require.NotNil(t, err)
+		require.False(t, linter.LinterFailedGitCommitHash.Valid)
+		_, err = refresh("c3")
+		require.NotNil(t, err)
+		require.Equal(t, 4, builds)
+	})
+	t.Run("source update", func(t *testing.T) {
+		docker.errs[image("c3")] = fmt.Errorf("%w: image %v: go build failed", DockerBuildErr, image("c3"))
+		_, err := refresh("c3")
+		require.ErrorIs(t, err, DockerBuildErr)
+		_, err = refresh("c3")
+		require.Nil(t, err)
+		require.Equal(t, 5, builds)
+
+		require.Nil(t, memory.UpdateLinterSource(ctx, db.UpdateLinterSourceParams{
+			LinterID:     "nilaway",
+			LinterGitUrl: "https://github.com/uber-go/nilaway",
+			UpdatedAt:    now,
+		}))
+		delete(docker.errs, image("c3"))
+		linter, err := refresh("c3")
+		require.Nil(t, err)
+		require.Equal(t, 6, builds)
+		require.False(t, linter.LinterFailedGitCommitHash.Valid)
+	})
+}
@@ -0,0 +1,207 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/sivukhin/gobughunt/lib/dto"
+	"github.com/sivukhin/gobughunt/lib/logging"
+)
+
+// SarifResultFile is the well-known path (relative to the repo root) where linter can put its SARIF report
+// SARIF 2.1.0: https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const SarifResultFile = ".gobughunt.sarif"
+
+type sarifDocument struct {
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Results []sarifResult `json:"results"`
+}
+
+type sarifResult struct {
+	RuleId string `json:"ruleId"`
+	Rule   *struct {
+		Id string `json:"id"`
+	} `json:"rule"`
+	Level   string `json:"level"`
+	Message struct {
+		Text     string `json:"text"`
+		Markdown string `json:"markdown"`
+	} `json:"message"`
+	Locations []struct {
+		PhysicalLocation *struct {
+			ArtifactLocation struct {
+				Uri string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region *struct {
+				StartLine   int `json:"startLine"`
+				StartColumn int `json:"startColumn"`
+				EndLine     int `json:"endLine"`
+				EndColumn   int `json:"endColumn"`
+			} `json:"region"`
+		} `json:"physicalLocation"`
+	} `json:"locations"`
+	Suppressions []json.RawMessage `json:"suppressions"`
+}
+
+// ExtractSarifOutput collects highlights from the SARIF report file in the repo dir and from SARIF documents printed to the output
+func ExtractSarifOutput(targetDir string, lines []string) ([]dto.LintHighlight, error) {
+	highlights := make([]dto.LintHighlight, 0)
+	report, err := os.ReadFile(path.Join(targetDir, SarifResultFile))
+	if err == nil {
+		reportHighlights, err := ExtractSarifHighlights(report)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SARIF report file: %w", err)
+		}
+		highlights = append(highlights, reportHighlights...)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("unable to read SARIF report: %w", err)
+	}
+	// linter output may contain arbitrary JSON which only resembles SARIF - so broken documents are skipped instead of failing the lint
+	forEachJsonDocument(lines, func(document json.RawMessage) {
+		if !IsSarifDocument(document) {
+			return
+		}
+		documentHighlights, err := ExtractSarifHighlights(document)
+		if err != nil {
+			logging.Logger.Errorf("skipping SARIF document from the output: %v", err)
+			return
+		}
+		highlights = append(highlights, documentHighlights...)
+	})
+	return highlights, nil
+}
+
+// IsSarifDocument checks that JSON document looks like SARIF log
+func IsSarifDocument(document []byte) bool {
+	var header struct {
+		Version *string          `json:"version"`
+		Runs    *json.RawMessage `json:"runs"`
+	}
+	return json.Unmarshal(document, &header) == nil && header.Version != nil && header.Runs != nil
+}
+
+func ExtractSarifHighlights(document []byte) ([]dto.LintHighlight, error) {
+	var sarif sarifDocument
+	err := json.Unmarshal(document, &sarif)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SARIF document: %w", err)
+	}
+	if !strings.HasPrefix(sarif.Version, "2.1") {
+		return nil, fmt.Errorf("unsupported SARIF version: '%v'", sarif.Version)
+	}
+	highlights := make([]dto.LintHighlight, 0)
+	for _, run := range sarif.Runs {
+		for _, result := range run.Results {
+			if len(result.Suppressions) > 0 || len(result.Locations) == 0 {
+				continue
+			}
+			location := result.Locations[0].PhysicalLocation
+			if location == nil || location.Region == nil || location.Region.StartLine < 1 {
+				logging.Logger.Debugf("SARIF result without physical region: %+v", result)
+				continue
+			}
+			highlightPath := NormalizeHighlightPath(location.ArtifactLocation.Uri)
+			if highlightPath == "" {
+				logging.Logger.Debugf("SARIF result without artifact uri: %+v", result)
+				continue
+			}
+			endLine := location.Region.EndLine
+			if endLine < location.Region.StartLine {
+				endLine = location.Region.StartLine
+			}
+			ruleId := result.RuleId
+			if ruleId == "" && result.Rule != nil {
+				ruleId = result.Rule.Id
+			}
+			message := result.Message.Text
+			if message == "" {
+				message = result.Message.Markdown
+			}
+			highlights = append(highlights, dto.LintHighlight{
+				Path:        highlightPath,
+				StartLine:   location.Region.StartLine,
+				EndLine:     endLine,
+				StartColumn: max(0, location.Region.StartColumn),
+				EndColumn:   max(0, location.Region.EndColumn),
+				Severity:    sarifSeverity(result.Level),
+				RuleId:      ruleId,
+				Explanation: message,
+			})
+		}
+	}
+	return highlights, nil
+}
+
+// sarifSeverity maps SARIF result level, "warning" is the default level according to the spec
+func sarifSeverity(level string) dto.Severity {
+	switch level {
+	case "error":
+		return dto.SeverityError
+	case "note", "none":
+		return dto.SeverityNotice
+	default:
+		return dto.SeverityWarning
+	}
+}
+
+// NormalizeHighlightPath converts path reported by linter (absolute path inside container, file:// uri, ./relative path) to the path relative to the repo root.
+// Empty path is returned if reported path points outside of the repo
+func NormalizeHighlightPath(reported string) string {
+	normalized := strings.TrimPrefix(reported, "file://")
+	if trimmed, ok := strings.CutPrefix(normalized, ContainerBindPath); ok {
+		normalized = strings.TrimPrefix(trimmed, "/")
+	}
+	normalized = strings.TrimPrefix(normalized, "./")
+	if normalized == "" || !isRepoRelativePath(normalized) {
+		return ""
+	}
+	return normalized
+}
+
+// isRepoRelativePath checks that path reported by the untrusted linter can't escape the repo root
+func isRepoRelativePath(highlightPath string) bool {
+	cleaned := path.Clean(highlightPath)
+	return !path.IsAbs(cleaned) && cleaned != "." && cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+}
+
+func joinExplanation(title, message string) string {
+	if title != "" && message != "" {
+		return title + ": " + message
+	} else if title != "" {
+		return title
+	}
+	return message
+}
+
+// forEachJsonDocument calls handle for every top-level JSON object in the output which starts at the beginning of the line
+func forEachJsonDocument(lines []string, handle func(document json.RawMessage)) {
+	offsets := make([]int, len(lines)+1)
+	for i, line := range lines {
+		offsets[i+1] = offsets[i] + len(line) + 1
+	}
+	text := strings.Join(lines, "\n")
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(strings.TrimSpace(lines[i]), "{") {
+			continue
+		}
+		decoder := json.NewDecoder(strings.NewReader(text[offsets[i]:]))
+		var document json.RawMessage
+		if decoder.Decode(&document) != nil {
+			continue
+		}
+		handle(document)
+		end := offsets[i] + int(decoder.InputOffset())
+		for i+1 < len(lines) && offsets[i+1] < end {
+			i++
+		}
+	}
+}
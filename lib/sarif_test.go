@@ -0,0 +1,121 @@
+package lib
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gobughunt/lib/dto"
+)
+
+const sarifReport = `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [{
+    "tool": {"driver": {"name": "nilaway"}},
+    "results": [{
+      "ruleId": "nilaway",
+      "level": "error",
+      "message": {"text": "potential nil panic"},
+      "locations": [{"physicalLocation": {
+        "artifactLocation": {"uri": "file:///home/repo/internal/vfs/cryptfs.go"},
+        "region": {"startLine": 262, "startColumn": 3, "endLine": 263, "endColumn": 10}
+      }}]
+    }, {
+      "rule": {"id": "SA4006"},
+      "message": {"text": "value is never used"},
+      "locations": [{"physicalLocation": {
+        "artifactLocation": {"uri": "./internal/service/signals_unix.go", "uriBaseId": "%SRCROOT%"},
+        "region": {"startLine": 77}
+      }}]
+    }, {
+      "ruleId": "suppressed",
+      "message": {"text": "suppressed"},
+      "suppressions": [{"kind": "inSource"}],
+      "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.go"}, "region": {"startLine": 1}}}]
+    }, {
+      "ruleId": "no-region",
+      "message": {"text": "no region"},
+      "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.go"}}}]
+    }]
+  }]
+}`
+
+func TestExtractSarifHighlights(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		highlights, err := ExtractSarifHighlights([]byte(sarifReport))
+		require.Nil(t, err)
+		require.Equal(t, []dto.LintHighlight{{
+			Path:        "internal/vfs/cryptfs.go",
+			StartLine:   262,
+			EndLine:     263,
+			StartColumn: 3,
+			EndColumn:   10,
+			Severity:    dto.SeverityError,
+			RuleId:      "nilaway",
+			Explanation: "potential nil panic",
+		}, {
+			Path:        "internal/service/signals_unix.go",
+			StartLine:   77,
+			EndLine:     77,
+			Severity:    dto.SeverityWarning,
+			RuleId:      "SA4006",
+			Explanation: "value is never used",
+		}}, highlights)
+	})
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := ExtractSarifHighlights([]byte(`{"version": "1.0.0", "runs": []}`))
+		require.NotNil(t, err)
+	})
+}
+
+func TestExtractSarifOutput(t *testing.T) {
+	t.Run("stdout", func(t *testing.T) {
+		highlights, err := ExtractSarifOutput(t.TempDir(), append(
+			[]string{`2024/02/10 11:09:26 ready to analyze module AST`, `{"level": "info", "msg": "structured log"}`},
+			sarifReport,
+		))
+		require.Nil(t, err)
+		require.Len(t, highlights, 2)
+	})
+	t.Run("file", func(t *testing.T) {
+		d := t.TempDir()
+		require.Nil(t, os.WriteFile(path.Join(d, SarifResultFile), []byte(sarifReport), 0644))
+		highlights, err := ExtractSarifOutput(d, []string{`::warning file=main.go,line=1::not a SARIF`})
+		require.Nil(t, err)
+		require.Len(t, highlights, 2)
+	})
+	t.Run("broken stdout document", func(t *testing.T) {
+		highlights, err := ExtractSarifOutput(t.TempDir(), append(
+			[]string{`{"version": "1.0.0", "runs": []}`, `{"version": "2.1.0", "runs": "not a list"}`},
+			sarifReport,
+		))
+		require.Nil(t, err)
+		require.Len(t, highlights, 2)
+	})
+	t.Run("broken file", func(t *testing.T) {
+		d := t.TempDir()
+		require.Nil(t, os.WriteFile(path.Join(d, SarifResultFile), []byte(`{"version": "2.1.0", "runs": "not a list"}`), 0644))
+		_, err := ExtractSarifOutput(d, []string{sarifReport})
+		require.NotNil(t, err)
+	})
+}
+
+func TestNormalizeHighlightPath(t *testing.T) {
+	require.Equal(t, "a/b.go", NormalizeHighlightPath("file:///home/repo/a/b.go"))
+	require.Equal(t, "a/b.go", NormalizeHighlightPath("./a/b.go"))
+	require.Equal(t, "a/b.go", NormalizeHighlightPath("a/b.go"))
+	for _, reported := range []string{
+		"../../../root/.docker/config.json",
+		"a/../../b.go",
+		"/etc/passwd",
+		"file:///etc/passwd",
+		"/home/repo/../../etc/passwd",
+		"..",
+		"",
+	} {
+		require.Equal(t, "", NormalizeHighlightPath(reported), reported)
+	}
+}
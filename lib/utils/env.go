@@ -25,6 +25,13 @@ func EnvMustParseDurationSec(key string) time.Duration {
 	return time.Duration(seconds) * time.Second
 }
 
+func EnvTryParseDurationSec(key string, defaultValue time.Duration) time.Duration {
+	if os.Getenv(key) == "" {
+		return defaultValue
+	}
+	return EnvMustParseDurationSec(key)
+}
+
 func EnvMustParseString(key string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -33,6 +40,26 @@ func EnvMustParseString(key string) string {
 	return value
 }
 
+// EnvMustParseStringDeprecated reads required env var which was renamed: old name is still accepted for compatibility
+func EnvMustParseStringDeprecated(key, deprecatedKey string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	if value := os.Getenv(deprecatedKey); value != "" {
+		logging.Logger.Infof("deprecated env var is used: key=%v, use %v instead", deprecatedKey, key)
+		return value
+	}
+	return EnvMustParseString(key)
+}
+
+func EnvTryParseString(key string, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
 func EnvMustParseStringArray(key string) []string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -41,6 +68,14 @@ func EnvMustParseStringArray(key string) []string {
 	return strings.Split(value, ",")
 }
 
+func EnvTryParseStringArray(key string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
 func EnvMustParseInt(key string) int64 {
 	value := os.Getenv(key)
 	if value == "" {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -11,53 +12,149 @@ import (
 	"github.com/sivukhin/gobughunt/lib/dto"
 	"github.com/sivukhin/gobughunt/lib/logging"
 	"github.com/sivukhin/gobughunt/lib/timeout"
+	"github.com/sivukhin/gobughunt/lib/utils"
+	"github.com/sivukhin/gobughunt/storage"
 	"github.com/sivukhin/gobughunt/storage/db"
 )
 
 type Worker struct {
-	Storage        *db.Queries
+	Id             string // identity of the worker process, lock of the task is owned by the single slot of the worker
+	Storage        storage.Storage
 	DockerApi      DockerApi
 	Linting        Linting
+	Concurrency    int
 	IterationDelay time.Duration
 	CleanupTimeout time.Duration
 	TakeTimeout    time.Duration
 	LintTimeout    time.Duration
 	UpdateTimeout  time.Duration
 	LockDuration   time.Duration
+	MaxAttempts    int           // task which failed with temp error is retried until it was attempted that many times
+	RetryDelay     time.Duration // delay before the first retry, every next retry waits twice longer
+}
+
+// WorkerSlots returns amount of concurrent lint slots which fit into the total resources given per-container limits of the docker
+func WorkerSlots(concurrency int, totalMemoryBytes, totalCpuMilli int64, docker NaiveDockerApi) int {
+	slots := concurrency
+	if docker.MemoryBytes > 0 {
+		slots = min(slots, int(totalMemoryBytes/docker.MemoryBytes))
+	}
+	if docker.CpuMilli > 0 {
+		slots = min(slots, int(totalCpuMilli/docker.CpuMilli))
+	}
+	return max(1, slots)
 }
 
 func (w Worker) RunForever(ctx context.Context) {
 	logging.Logger.Infof(
-		"worker started: iterationDelay=%v, cleanupTimeout=%v, takeTimeout=%v, lintTimeout=%v, updateTimeout=%v, lockDuration=%v",
+		"worker started: id=%v, concurrency=%v, iterationDelay=%v, cleanupTimeout=%v, takeTimeout=%v, lintTimeout=%v, updateTimeout=%v, lockDuration=%v, maxAttempts=%v, retryDelay=%v",
+		w.Id,
+		w.Concurrency,
 		w.IterationDelay,
 		w.CleanupTimeout,
 		w.TakeTimeout,
 		w.LintTimeout,
 		w.UpdateTimeout,
 		w.LockDuration,
+		w.MaxAttempts,
+		w.RetryDelay,
 	)
+	// cleanup runs concurrently with the slots: Exec removes its own container, prune never touches running containers
+	// and waits for the containers which are being started (see pruneLock)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.cleanupForever(ctx)
+	}()
+	for slot := 0; slot < max(1, w.Concurrency); slot++ {
+		wg.Add(1)
+		go func(slot int) {
+			defer wg.Done()
+			w.runSlotForever(ctx, slot)
+		}(slot)
+	}
+	wg.Wait()
+}
+
+func (w Worker) cleanupForever(ctx context.Context) {
 	periodic := timeout.Periodic(ctx, w.IterationDelay, w.IterationDelay)
 	cleanup := timeout.Process("cleanup", periodic, w.CleanupTimeout, func(ctx context.Context, item struct{}, next func(struct{})) error {
 		err := w.DockerApi.Cleanup(ctx)
 		if err != nil {
 			logging.Logger.Errorf("failed to cleanup docker: %v", err)
-		} else {
-			next(struct{}{})
 		}
 		return err
 	})
-	take := timeout.Process("take", cleanup, w.TakeTimeout, func(ctx context.Context, _ struct{}, next func(task dto.LintTask)) error {
-		logging.Logger.Infof("worker run single iteration")
-		now := time.Now()
-		lintTask, err := w.Storage.TryTakeLintTask(ctx, db.TryTakeLintTaskParams{
+	timeout.Close(cleanup)
+}
+
+func (w Worker) runSlotForever(ctx context.Context, slot int) {
+	owner := pgtype.Text{String: fmt.Sprintf("%v/%v", w.Id, slot), Valid: true}
+	periodic := timeout.Periodic(ctx, w.IterationDelay, w.IterationDelay)
+	take := timeout.Process(fmt.Sprintf("take-%v", slot), periodic, w.TakeTimeout, func(ctx context.Context, _ struct{}, next func(task dto.LintTask)) error {
+		logging.Logger.Infof("worker slot %v run single iteration", slot)
+		lintTask, err := w.takeLintTask(ctx, owner)
+		if err != nil {
+			return err
+		}
+		logging.Logger.Infof("worker slot %v took single lint task: %+v", slot, lintTask)
+		next(lintTask)
+		return nil
+	})
+	lint := timeout.Process(fmt.Sprintf("lint-%v", slot), take, w.LintTimeout, func(ctx context.Context, item dto.LintTask, next func(result lintResult)) error {
+		next(w.lintTask(ctx, item, owner))
+		return nil
+	})
+	update := timeout.Process(fmt.Sprintf("update-%v", slot), lint, w.UpdateTimeout, func(ctx context.Context, item lintResult, next func(struct{})) error {
+		return w.saveLintResult(ctx, item, owner)
+	})
+	timeout.Close(update)
+}
+
+type lintResult struct {
+	task       dto.LintTask
+	highlights []dto.LintHighlightSnippet
+	logs       dto.LintLogs
+	usage      dto.ResourceUsage
+	duration   time.Duration
+	err        error
+}
+
+// takeLintTask locks the oldest available task for the slot and starts new attempt - pgx.ErrNoRows is returned if there is nothing to do
+func (w Worker) takeLintTask(ctx context.Context, owner pgtype.Text) (dto.LintTask, error) {
+	now := time.Now()
+	var task dto.LintTask
+	err := w.Storage.InTx(ctx, func(queries storage.Queries) error {
+		lintTask, err := queries.TryTakeLintTask(ctx, db.TryTakeLintTaskParams{
 			LockTimeLowerBound: pgtype.Timestamp{Time: now.Add(-w.LockDuration), Valid: true},
 			LockedAt:           pgtype.Timestamp{Time: now, Valid: true},
+			LockedBy:           owner,
 		})
 		if err != nil {
 			return err
 		}
-		logging.Logger.Infof("took single lint task: %+v", lintTask)
-		next(dto.LintTask{
+		// attempt of the slot which lost the lock without writing the result will never be finished by its owner
+		abandoned, err := queries.AbandonLintAttempts(ctx, db.AbandonLintAttemptsParams{
+			LintID:     lintTask.LintID,
+			FinishedAt: pgtype.Timestamp{Time: now, Valid: true},
+			Error:      pgtype.Text{String: "lock of the task expired before the attempt finished", Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to abandon stale attempts of the task %v: %w", lintTask.LintID, err)
+		} else if abandoned > 0 {
+			logging.Logger.Infof("lint task %v had %v abandoned attempts", lintTask.LintID, abandoned)
+		}
+		attemptId, err := queries.AddLintAttempt(ctx, db.AddLintAttemptParams{
+			LintID:    lintTask.LintID,
+			Attempt:   lintTask.LintAttempts,
+			WorkerID:  owner.String,
+			StartedAt: pgtype.Timestamp{Time: now, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add attempt of the task %v: %w", lintTask.LintID, err)
+		}
+		task = dto.LintTask{
 			Id: lintTask.LintID,
 			Linter: dto.LinterInstance{
 				Id:                 lintTask.LinterID,
@@ -69,73 +166,271 @@ func (w Worker) RunForever(ctx context.Context) {
 				GitUrl:        lintTask.RepoGitUrl,
 				GitCommitHash: lintTask.RepoGitCommitHash,
 			},
-		})
+			Attempt:   int(lintTask.LintAttempts),
+			AttemptId: attemptId,
+		}
 		return nil
 	})
-	type lintResult struct {
-		task       dto.LintTask
-		highlights []dto.LintHighlightSnippet
-		duration   time.Duration
-		err        error
-	}
-	lint := timeout.Process("lint", take, w.LintTimeout, func(ctx context.Context, item dto.LintTask, next func(result lintResult)) error {
-		startTime := time.Now()
-		highlights, err := w.Linting.Run(ctx, item.Repo, item.Linter)
-		next(lintResult{task: item, highlights: highlights, err: err, duration: time.Since(startTime)})
+	return task, err
+}
+
+// lintTask runs the linter while heartbeat keeps the lock of the task
+func (w Worker) lintTask(ctx context.Context, item dto.LintTask, owner pgtype.Text) lintResult {
+	if item.Attempt > max(1, w.MaxAttempts) {
+		// previous attempts were abandoned (e.g. worker crashed) - task most likely kills the worker, so we give up without running it
+		return lintResult{task: item, err: fmt.Errorf("%w: previous attempts were abandoned", LintTempErr)}
+	}
+	lintCtx, cancel := context.WithCancelCause(ctx)
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		w.heartbeat(lintCtx, item.Id, owner, cancel)
+	}()
+	startTime := time.Now()
+	output, err := w.Linting.Run(lintCtx, item.Repo, item.Linter)
+	if cause := context.Cause(lintCtx); errors.Is(cause, LintLockLostErr) {
+		err = cause
+	} else if err != nil && !errors.Is(err, LintTimeoutErr) && errors.Is(lintCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: linter %v didn't finish in %v on repo %v: %w", LintTimeoutErr, item.Linter.Id, w.LintTimeout, item.Repo.Id, err)
+	}
+	cancel(nil)
+	<-heartbeatDone
+	return lintResult{task: item, highlights: output.Highlights, logs: output.Logs, usage: output.Usage, err: err, duration: time.Since(startTime)}
+}
+
+// RetryBackoff returns delay before the next attempt of the task after given amount of failed attempts
+func RetryBackoff(retryDelay time.Duration, attempts int) time.Duration {
+	const maxShift = 16 // cap exponent in order to avoid overflow for misconfigured policies
+	return retryDelay << min(max(attempts-1, 0), maxShift)
+}
+
+// lintOutcome maps the result of the attempt to the next state of the task:
+// temp errors are retried with exponential backoff until attempts are exhausted, all other results are final
+func (w Worker) lintOutcome(item lintResult, now time.Time) (db.LintStatus, pgtype.Text, pgtype.Timestamp) {
+	maxAttempts := max(1, w.MaxAttempts)
+	switch {
+	case item.err == nil:
+		return db.LintStatusSucceeded, pgtype.Text{}, pgtype.Timestamp{}
+	case errors.Is(item.err, LintSkippedErr):
+		return db.LintStatusSkipped, pgtype.Text{}, pgtype.Timestamp{}
+	case errors.Is(item.err, LintTimeoutErr):
+		return db.LintStatusTimedOut, pgtype.Text{String: item.err.Error(), Valid: true}, pgtype.Timestamp{}
+	case errors.Is(item.err, LintOOMErr):
+		return db.LintStatusOomKilled, pgtype.Text{String: item.err.Error(), Valid: true}, pgtype.Timestamp{}
+	case errors.Is(item.err, LintCrashErr):
+		return db.LintStatusCrashed, pgtype.Text{String: item.err.Error(), Valid: true}, pgtype.Timestamp{}
+	case errors.Is(item.err, LintNoisyErr):
+		return db.LintStatusTooNoisy, pgtype.Text{String: item.err.Error(), Valid: true}, pgtype.Timestamp{}
+	case errors.Is(item.err, LintTempErr) && item.task.Attempt < maxAttempts:
+		next := now.Add(RetryBackoff(w.RetryDelay, item.task.Attempt))
+		comment := fmt.Sprintf("attempt %v of %v failed, retry at %v: %v", item.task.Attempt, maxAttempts, next.Format(time.DateTime), item.err)
+		return db.LintStatusPending, pgtype.Text{String: comment, Valid: true}, pgtype.Timestamp{Time: next, Valid: true}
+	case errors.Is(item.err, LintTempErr):
+		comment := fmt.Sprintf("gave up after %v attempts: %v", item.task.Attempt, item.err)
+		return db.LintStatusFailed, pgtype.Text{String: comment, Valid: true}, pgtype.Timestamp{}
+	default:
+		return db.LintStatusFailed, pgtype.Text{String: item.err.Error(), Valid: true}, pgtype.Timestamp{}
+	}
+}
+
+// lintExitCode extracts exit code of the linter container if it finished with non-zero code
+func lintExitCode(err error) pgtype.Int4 {
+	var exitErr DockerExitCodeErr
+	if errors.As(err, &exitErr) {
+		return pgtype.Int4{Int32: int32(exitErr.ExitCode), Valid: true}
+	}
+	return pgtype.Int4{}
+}
+
+// saveLintResult writes status of the task, its highlights and the attempt record - nothing is written to the task if slot lost its lock
+func (w Worker) saveLintResult(ctx context.Context, item lintResult, owner pgtype.Text) error {
+	now := time.Now()
+	attempt := db.FinishLintAttemptParams{
+		AttemptID:  item.task.AttemptId,
+		FinishedAt: pgtype.Timestamp{Time: now, Valid: true},
+		ExitCode:   lintExitCode(item.err),
+	}
+	if item.err != nil {
+		attempt.Error = pgtype.Text{String: item.err.Error(), Valid: true}
+	}
+	if errors.Is(item.err, LintLockLostErr) {
+		// task belongs to another worker now - we must not touch it, but our attempt is over
+		attempt.LintStatus = db.NullLintStatus{LintStatus: db.LintStatusCancelled, Valid: true}
+		_, err := w.Storage.FinishLintAttempt(ctx, attempt)
+		return errors.Join(item.err, err)
+	}
+	status, comment, nextAttemptAt := w.lintOutcome(item, now)
+	attempt.LintStatus = db.NullLintStatus{LintStatus: status, Valid: true}
+	if errors.Is(item.err, LintSkippedErr) {
+		item.err = nil // skip is the expected outcome for the repo which linter doesn't support
+	}
+	logs, err := compressLintLogs(item, now)
+	if err != nil {
+		return errors.Join(item.err, err)
+	}
+
+	// highlights, final status and attempt are committed atomically: retry of the failed update stage never leaves duplicated rows
+	return errors.Join(item.err, w.Storage.InTx(ctx, func(queries storage.Queries) error {
+		if status == db.LintStatusSucceeded || status == db.LintStatusTooNoisy {
+			err := saveLintHighlights(ctx, queries, item, owner, now, status == db.LintStatusTooNoisy)
+			if err != nil {
+				return err
+			}
+		}
+		sampled := item.usage.Samples > 0
+		err := setLintTask(ctx, queries, db.SetLintTaskParams{
+			LintID:            item.task.Id,
+			LintStatus:        status,
+			LintStatusComment: comment,
+			LintDuration:      pgtype.Interval{Microseconds: item.duration.Microseconds(), Valid: true},
+			LintedAt:          pgtype.Timestamp{Time: now, Valid: true},
+			LockedBy:          owner,
+			NextAttemptAt:     nextAttemptAt,
+			CpuSeconds:        pgtype.Float8{Float64: item.usage.CpuSeconds, Valid: sampled},
+			MaxRssBytes:       pgtype.Int8{Int64: item.usage.MaxRssBytes, Valid: sampled},
+			IoBytes:           pgtype.Int8{Int64: item.usage.IoBytes, Valid: sampled},
+			MaxPids:           pgtype.Int8{Int64: item.usage.MaxPids, Valid: sampled},
+		})
+		if err != nil {
+			return err
+		}
+		if logs != nil {
+			err = queries.SetLintLogs(ctx, *logs)
+			if err != nil {
+				return fmt.Errorf("failed to save logs of the task %v: %w", item.task.Id, err)
+			}
+		}
+		_, err = queries.FinishLintAttempt(ctx, attempt)
+		if err != nil {
+			return fmt.Errorf("failed to finish attempt of the task %v: %w", item.task.Id, err)
+		}
 		return nil
+	}))
+}
+
+// compressLintLogs prepares logs of the attempt for storage - nil is returned if container wasn't started or was silent,
+// so logs of the previous attempt are kept in that case
+func compressLintLogs(item lintResult, now time.Time) (*db.SetLintLogsParams, error) {
+	if item.logs.StdoutBytes == 0 && item.logs.StderrBytes == 0 {
+		return nil, nil
+	}
+	stdout, err := utils.Gzip(item.logs.Stdout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compress stdout of the task %v: %w", item.task.Id, err)
+	}
+	stderr, err := utils.Gzip(item.logs.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compress stderr of the task %v: %w", item.task.Id, err)
+	}
+	return &db.SetLintLogsParams{
+		LintID:      item.task.Id,
+		Attempt:     int32(item.task.Attempt),
+		Stdout:      stdout,
+		Stderr:      stderr,
+		StdoutBytes: item.logs.StdoutBytes,
+		StderrBytes: item.logs.StderrBytes,
+		CreatedAt:   pgtype.Timestamp{Time: now, Valid: true},
+	}, nil
+}
+
+// saveLintHighlights replaces highlights of the task and propagates moderation and tracking from the previous tasks.
+// Truncated highlights can't prove that something was fixed - so fixes are tracked only for the complete result
+func saveLintHighlights(ctx context.Context, queries storage.Queries, item lintResult, owner pgtype.Text, now time.Time, truncated bool) error {
+	// renewal also locks the task row until commit - so nobody can take the task in the middle of the update
+	renewed, err := queries.HeartbeatLintTask(ctx, db.HeartbeatLintTaskParams{
+		LintID:   item.task.Id,
+		LockedBy: owner,
+		LockedAt: pgtype.Timestamp{Time: now, Valid: true},
 	})
-	update := timeout.Process("update", lint, w.UpdateTimeout, func(ctx context.Context, item lintResult, next func(struct{})) error {
-		now := time.Now()
-		if errors.Is(item.err, LintSkippedErr) {
-			return w.Storage.SetLintTask(ctx, db.SetLintTaskParams{
-				LintID:       item.task.Id,
-				LintStatus:   db.LintStatusSkipped,
-				LintDuration: pgtype.Interval{Microseconds: item.duration.Microseconds(), Valid: true},
-				LintedAt:     pgtype.Timestamp{Time: now, Valid: true},
-			})
-		} else if errors.Is(item.err, LintTempErr) {
-			return errors.Join(item.err, w.Storage.SetLintTask(ctx, db.SetLintTaskParams{
-				LintID:       item.task.Id,
-				LintStatus:   db.LintStatusPending,
-				LintDuration: pgtype.Interval{Microseconds: item.duration.Microseconds(), Valid: true},
-				LintedAt:     pgtype.Timestamp{Time: now, Valid: true},
-			}))
-		} else if item.err != nil {
-			return errors.Join(item.err, w.Storage.SetLintTask(ctx, db.SetLintTaskParams{
-				LintID:            item.task.Id,
-				LintStatus:        db.LintStatusFailed,
-				LintStatusComment: pgtype.Text{String: item.err.Error(), Valid: true},
-				LintDuration:      pgtype.Interval{Microseconds: item.duration.Microseconds(), Valid: true},
-				LintedAt:          pgtype.Timestamp{Time: now, Valid: true},
-			}))
-		}
+	if err != nil {
+		return fmt.Errorf("failed to renew lock of the task %v: %w", item.task.Id, err)
+	} else if renewed == 0 {
+		return fmt.Errorf("%w: task=%v, owner=%v", LintLockLostErr, item.task.Id, owner.String)
+	}
+	err = queries.DeleteLintHighlights(ctx, item.task.Id)
+	if err != nil {
+		return fmt.Errorf("failed to delete stale lint highlights: %w", err)
+	}
+
+	params := make([]db.AddLintHighlightParams, 0, len(item.highlights))
+	for _, highlight := range item.highlights {
+		params = append(params, db.AddLintHighlightParams{
+			LintID:           item.task.Id,
+			Path:             highlight.Path,
+			StartLine:        int32(highlight.StartLine),
+			EndLine:          int32(highlight.EndLine),
+			StartColumn:      int32(highlight.StartColumn),
+			EndColumn:        int32(highlight.EndColumn),
+			Severity:         db.HighlightSeverity(utils.Ternary(highlight.Severity != "", highlight.Severity, dto.SeverityWarning)),
+			RuleID:           highlight.RuleId,
+			Explanation:      highlight.Explanation,
+			SnippetStartLine: int32(highlight.Snippet.StartLine),
+			SnippetEndLine:   int32(highlight.Snippet.EndLine),
+			SnippetCode:      highlight.Snippet.Code,
+			Fingerprint:      highlight.Fingerprint,
+		})
+	}
+	err = queries.AddLintHighlights(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to update lint highlights: %w", err)
+	}
+	inherited, err := queries.InheritLintHighlightsModeration(ctx, item.task.Id)
+	if err != nil {
+		return fmt.Errorf("failed to inherit moderation of lint highlights: %w", err)
+	}
+	if inherited > 0 {
+		logging.Logger.Infof("lint task %v inherited moderation for %v highlights", item.task.Id, inherited)
+	}
+	if _, err = queries.TrackLintHighlights(ctx, item.task.Id); err != nil {
+		return fmt.Errorf("failed to track lint highlights: %w", err)
+	}
+	if truncated {
+		return nil
+	}
+	fixed, err := queries.FixLintHighlights(ctx, item.task.Id)
+	if err != nil {
+		return fmt.Errorf("failed to mark fixed lint highlights: %w", err)
+	}
+	if fixed > 0 {
+		logging.Logger.Infof("lint task %v found %v highlights fixed upstream", item.task.Id, fixed)
+	}
+	return nil
+}
 
-		params := make([]db.AddLintHighlightParams, 0, len(item.highlights))
-		for _, highlight := range item.highlights {
-			params = append(params, db.AddLintHighlightParams{
-				LintID:           item.task.Id,
-				Path:             highlight.Path,
-				StartLine:        int32(highlight.StartLine),
-				EndLine:          int32(highlight.EndLine),
-				Explanation:      highlight.Explanation,
-				SnippetStartLine: int32(highlight.Snippet.StartLine),
-				SnippetEndLine:   int32(highlight.Snippet.EndLine),
-				SnippetCode:      highlight.Snippet.Code,
-			})
+// heartbeat renews lock of the task every third of the lock duration until ctx is done
+// If lock was taken by another worker - lint is cancelled with LintLockLostErr cause
+func (w Worker) heartbeat(ctx context.Context, lintId string, owner pgtype.Text, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(w.LockDuration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-		var batchErrs []error
-		results := w.Storage.AddLintHighlight(ctx, params)
-		results.Exec(func(i int, err error) { batchErrs = append(batchErrs, err) })
-		if batchErr := errors.Join(batchErrs...); batchErr != nil {
-			return fmt.Errorf("failed to update lint highlights: %w", batchErr)
+		renewed, err := w.Storage.HeartbeatLintTask(ctx, db.HeartbeatLintTaskParams{
+			LintID:   lintId,
+			LockedBy: owner,
+			LockedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+		})
+		if err != nil {
+			// transient storage error - lock will be renewed on the next tick
+			logging.Logger.Errorf("failed to renew lock of the task %v: %v", lintId, err)
+		} else if renewed == 0 {
+			logging.Logger.Errorf("lock of the task %v was lost by %v", lintId, owner.String)
+			cancel(fmt.Errorf("%w: task=%v, owner=%v", LintLockLostErr, lintId, owner.String))
+			return
 		}
+	}
+}
 
-		return w.Storage.SetLintTask(ctx, db.SetLintTaskParams{
-			LintID:       item.task.Id,
-			LintStatus:   db.LintStatusSucceed,
-			LintDuration: pgtype.Interval{Microseconds: item.duration.Microseconds(), Valid: true},
-			LintedAt:     pgtype.Timestamp{Time: now, Valid: true},
-		})
-	})
-	timeout.Close(update)
+// setLintTask writes the result of the task only if the lock is still owned by the slot
+func setLintTask(ctx context.Context, queries storage.Queries, params db.SetLintTaskParams) error {
+	updated, err := queries.SetLintTask(ctx, params)
+	if err != nil {
+		return err
+	} else if updated == 0 {
+		return fmt.Errorf("%w: task=%v, owner=%v", LintLockLostErr, params.LintID, params.LockedBy.String)
+	}
+	return nil
 }
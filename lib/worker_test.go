@@ -0,0 +1,25 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerSlots(t *testing.T) {
+	docker := NaiveDockerApi{MemoryBytes: 4 * 1024 * 1024 * 1024, CpuMilli: 2000}
+	require.Equal(t, 4, WorkerSlots(8, 16*1024*1024*1024, 64000, docker))
+	require.Equal(t, 3, WorkerSlots(8, 64*1024*1024*1024, 6000, docker))
+	require.Equal(t, 2, WorkerSlots(2, 64*1024*1024*1024, 64000, docker))
+	require.Equal(t, 1, WorkerSlots(8, 1024, 1000, docker))
+	require.Equal(t, 8, WorkerSlots(8, 0, 0, NaiveDockerApi{}))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	require.Equal(t, time.Minute, RetryBackoff(time.Minute, 0))
+	require.Equal(t, time.Minute, RetryBackoff(time.Minute, 1))
+	require.Equal(t, 2*time.Minute, RetryBackoff(time.Minute, 2))
+	require.Equal(t, 8*time.Minute, RetryBackoff(time.Minute, 4))
+	require.Equal(t, RetryBackoff(time.Second, 17), RetryBackoff(time.Second, 1000))
+}
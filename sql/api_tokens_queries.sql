@@ -0,0 +1,28 @@
+-- name: CreateApiToken :one
+INSERT INTO api_tokens (user_login, token_name, token_hash, token_scope, created_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING token_id;
+
+-- name: GetActiveApiToken :one
+SELECT token_id, user_login, token_scope
+FROM api_tokens
+WHERE token_hash = $1
+  AND revoked_at IS NULL;
+
+-- name: TouchApiToken :exec
+UPDATE api_tokens
+SET last_used_at = $2
+WHERE token_id = $1;
+
+-- name: ListApiTokens :many
+SELECT token_id, token_name, token_scope, created_at, last_used_at, revoked_at
+FROM api_tokens
+WHERE user_login = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeApiToken :execrows
+UPDATE api_tokens
+SET revoked_at = $3
+WHERE token_id = $1
+  AND user_login = $2
+  AND revoked_at IS NULL;
@@ -1,60 +1,112 @@
 -- name: ListBugHuntLinters :many
 WITH highlights AS (SELECT h.linter_id,
                            h.repo_id,
-                           h.path,
-                           h.start_line,
-                           h.end_line,
+                           h.highlight_key,
                            max(h.moderation_status) as moderation_status
                     FROM (SELECT t.lint_id,
                                  t.linter_id,
                                  t.linter_docker_sha_hash,
                                  t.repo_id,
                                  t.repo_git_commit_hash,
-                                 h.path,
-                                 h.start_line,
-                                 h.end_line,
+                                 COALESCE(NULLIF(h.fingerprint, ''), h.path || ':' || h.start_line || ':' || h.end_line) as highlight_key,
                                  h.moderation_status
                           FROM lint_highlights as h
                                    JOIN lint_tasks as t ON h.lint_id = t.lint_id) h
                     GROUP BY h.linter_id,
                              h.repo_id,
-                             h.path,
-                             h.start_line,
-                             h.end_line),
+                             h.highlight_key),
      linter_stats_total AS (SELECT linter_id,
-                                   COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                   COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                             FROM highlights
                             GROUP BY linter_id),
      linter_stats_pending AS (SELECT linter_id,
-                                     COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                     COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                               FROM highlights
                               WHERE moderation_status = 'pending'
                               GROUP BY linter_id),
      linter_stats_accepted AS (SELECT linter_id,
-                                      COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                      COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                                FROM highlights
                                WHERE moderation_status = 'accepted'
                                GROUP BY linter_id),
      linter_stats_rejected AS (SELECT linter_id,
-                                      COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                      COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                                FROM highlights
                                WHERE moderation_status = 'rejected'
-                               GROUP BY linter_id)
+                               GROUP BY linter_id),
+     linter_stats_fixed AS (SELECT h.linter_id,
+                                   COUNT(DISTINCT (h.repo_id, h.highlight_key)) as cnt
+                            FROM highlights as h
+                                     JOIN highlight_tracks as tr ON h.repo_id = tr.repo_id
+                                AND h.linter_id = tr.linter_id
+                                AND h.highlight_key = tr.fingerprint
+                            WHERE h.moderation_status = 'accepted'
+                              AND tr.fixed_at IS NOT NULL
+                            GROUP BY h.linter_id)
 SELECT linters.linter_id,
        linters.linter_git_url,
        linters.linter_git_branch,
        linters.linter_last_docker_image,
        linters.linter_last_docker_sha_hash,
+       linters.created_at,
+       linters.updated_at,
        COALESCE(total.cnt, 0)    as total_highlight,
        COALESCE(pending.cnt, 0)  as pending_highlight,
        COALESCE(rejected.cnt, 0) as rejected_highlight,
-       COALESCE(accepted.cnt, 0) as accepted_highlight
+       COALESCE(accepted.cnt, 0) as accepted_highlight,
+       COALESCE(fixed.cnt, 0)    as fixed_highlight
 FROM linters as linters
          LEFT JOIN linter_stats_total as total ON linters.linter_id = total.linter_id
          LEFT JOIN linter_stats_pending as pending ON linters.linter_id = pending.linter_id
          LEFT JOIN linter_stats_rejected as rejected ON linters.linter_id = rejected.linter_id
          LEFT JOIN linter_stats_accepted as accepted ON linters.linter_id = accepted.linter_id
-ORDER BY accepted_highlight DESC, pending_highlight DESC, rejected_highlight, updated_at DESC;
+         LEFT JOIN linter_stats_fixed as fixed ON linters.linter_id = fixed.linter_id
+WHERE linters.linter_status IN ('active', 'paused')
+  AND (@after_linter_id::text = '' OR
+       (linters.created_at, linters.linter_id) > (@after_created_at::timestamp, @after_linter_id::text))
+ORDER BY linters.created_at, linters.linter_id
+LIMIT @page_limit;
+
+-- name: ListBugHuntLinterRules :many
+WITH highlights AS (SELECT t.linter_id,
+                           h.rule_id,
+                           t.repo_id,
+                           COALESCE(NULLIF(h.fingerprint, ''), h.path || ':' || h.start_line || ':' || h.end_line) as highlight_key,
+                           max(h.moderation_status) as moderation_status
+                    FROM lint_highlights as h
+                             JOIN lint_tasks as t ON h.lint_id = t.lint_id
+                    GROUP BY t.linter_id,
+                             h.rule_id,
+                             t.repo_id,
+                             highlight_key)
+SELECT h.linter_id,
+       h.rule_id,
+       COUNT(*)                                                                             as total_highlight,
+       COUNT(*) FILTER (WHERE h.moderation_status = 'pending')                               as pending_highlight,
+       COUNT(*) FILTER (WHERE h.moderation_status = 'rejected')                              as rejected_highlight,
+       COUNT(*) FILTER (WHERE h.moderation_status = 'accepted')                              as accepted_highlight,
+       COUNT(*) FILTER (WHERE h.moderation_status = 'accepted' AND tr.fixed_at IS NOT NULL) as fixed_highlight
+FROM highlights as h
+         LEFT JOIN highlight_tracks as tr ON h.repo_id = tr.repo_id
+    AND h.linter_id = tr.linter_id
+    AND h.highlight_key = tr.fingerprint
+WHERE h.rule_id <> ''
+GROUP BY h.linter_id, h.rule_id
+ORDER BY h.linter_id, accepted_highlight DESC, pending_highlight DESC, rejected_highlight, h.rule_id;
+
+-- name: ListBugHuntLinterUsage :many
+SELECT linter_id,
+       COUNT(*)                                as total_tasks,
+       COALESCE(SUM(cpu_seconds), 0)::FLOAT8   as total_cpu_seconds,
+       COALESCE(AVG(cpu_seconds), 0)::FLOAT8   as avg_cpu_seconds,
+       COALESCE(MAX(max_rss_bytes), 0)::BIGINT as max_rss_bytes,
+       COALESCE(AVG(max_rss_bytes), 0)::BIGINT as avg_rss_bytes,
+       COALESCE(AVG(io_bytes), 0)::BIGINT      as avg_io_bytes,
+       COALESCE(MAX(max_pids), 0)::BIGINT      as max_pids
+FROM lint_tasks
+WHERE cpu_seconds IS NOT NULL
+GROUP BY linter_id
+ORDER BY linter_id;
 
 -- name: ListBugHuntRepos :many
 WITH 
@@ -63,9 +115,7 @@ WITH
                                 t.linter_docker_sha_hash,
                                 t.repo_id,
                                 t.repo_git_commit_hash,
-                                h.path,
-                                h.start_line,
-                                h.end_line,
+                                COALESCE(NULLIF(h.fingerprint, ''), h.path || ':' || h.start_line || ':' || h.end_line) as highlight_key,
                                 h.moderation_status
                           FROM lint_highlights as h
                           JOIN lint_tasks as t ON h.lint_id = t.lint_id
@@ -73,49 +123,61 @@ WITH
                           JOIN repos as r ON t.repo_id = r.repo_id),
     highlights AS (SELECT h.linter_id,
                            h.repo_id,
-                           h.path,
-                           h.start_line,
-                           h.end_line,
+                           h.highlight_key,
                            max(h.moderation_status) as moderation_status
                     FROM alive_highlights h
                     GROUP BY h.linter_id,
                              h.repo_id,
-                             h.path,
-                             h.start_line,
-                             h.end_line),
+                             h.highlight_key),
      repo_stats_total AS (SELECT repo_id,
-                                 COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                 COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                           FROM highlights
                           GROUP BY repo_id),
      repo_stats_pending AS (SELECT repo_id,
-                                   COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                   COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                             FROM highlights
                             WHERE moderation_status = 'pending'
                             GROUP BY repo_id),
      repo_stats_accepted AS (SELECT repo_id,
-                                    COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                    COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                              FROM highlights
                              WHERE moderation_status = 'accepted'
                              GROUP BY repo_id),
      repo_stats_rejected AS (SELECT repo_id,
-                                    COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                    COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                              FROM highlights
                              WHERE moderation_status = 'rejected'
-                             GROUP BY repo_id)
+                             GROUP BY repo_id),
+     repo_stats_fixed AS (SELECT h.repo_id,
+                                 COUNT(DISTINCT (h.repo_id, h.highlight_key)) as cnt
+                          FROM highlights as h
+                                   JOIN highlight_tracks as tr ON h.repo_id = tr.repo_id
+                              AND h.linter_id = tr.linter_id
+                              AND h.highlight_key = tr.fingerprint
+                          WHERE h.moderation_status = 'accepted'
+                            AND tr.fixed_at IS NOT NULL
+                          GROUP BY h.repo_id)
 SELECT repos.repo_id,
        repos.repo_git_url,
        repos.repo_git_branch,
        repos.repo_last_git_commit_hash,
+       repos.created_at,
+       repos.updated_at,
        COALESCE(total.cnt, 0)    as total_highlight,
        COALESCE(pending.cnt, 0)  as pending_highlight,
        COALESCE(rejected.cnt, 0) as rejected_highlight,
-       COALESCE(accepted.cnt, 0) as accepted_highlight
+       COALESCE(accepted.cnt, 0) as accepted_highlight,
+       COALESCE(fixed.cnt, 0)    as fixed_highlight
 FROM repos as repos
          LEFT JOIN repo_stats_total as total ON repos.repo_id = total.repo_id
          LEFT JOIN repo_stats_pending as pending ON repos.repo_id = pending.repo_id
          LEFT JOIN repo_stats_rejected as rejected ON repos.repo_id = rejected.repo_id
          LEFT JOIN repo_stats_accepted as accepted ON repos.repo_id = accepted.repo_id
-ORDER BY accepted_highlight DESC, pending_highlight DESC, rejected_highlight, updated_at DESC;
+         LEFT JOIN repo_stats_fixed as fixed ON repos.repo_id = fixed.repo_id
+WHERE (@after_repo_id::text = '' OR
+       (repos.created_at, repos.repo_id) > (@after_created_at::timestamp, @after_repo_id::text))
+ORDER BY repos.created_at, repos.repo_id
+LIMIT @page_limit;
 
 -- name: ListBugHuntLintTasks :many
 SELECT repos.repo_id,
@@ -125,18 +187,30 @@ SELECT repos.repo_id,
        linters.linter_id,
        linters.linter_git_url,
        linters.linter_git_branch,
+       linters.linter_owner,
        lint_tasks.linter_docker_image,
        lint_tasks.linter_docker_sha_hash,
        lint_tasks.lint_id,
        lint_tasks.lint_status,
        lint_tasks.lint_status_comment,
-       lint_tasks.lint_duration
+       lint_tasks.lint_duration,
+       lint_tasks.lint_attempts,
+       lint_tasks.next_attempt_at,
+       lint_tasks.cpu_seconds,
+       lint_tasks.max_rss_bytes,
+       lint_tasks.io_bytes,
+       lint_tasks.max_pids,
+       lint_tasks.created_at,
+       EXISTS (SELECT 1 FROM lint_logs WHERE lint_logs.lint_id = lint_tasks.lint_id) AS has_logs
 FROM lint_tasks as lint_tasks
          JOIN linters as linters ON linters.linter_id = lint_tasks.linter_id
          JOIN repos as repos ON repos.repo_id = lint_tasks.repo_id
-ORDER BY lint_tasks.lint_status,
-         lint_tasks.created_at DESC
-LIMIT $2 OFFSET $1;
+WHERE (lint_tasks.linter_id = @linter_id OR @linter_id = '')
+  AND (@before_lint_id::text = '' OR
+       (lint_tasks.created_at, lint_tasks.lint_id) < (@before_created_at::timestamp, @before_lint_id::text))
+ORDER BY lint_tasks.created_at DESC,
+         lint_tasks.lint_id DESC
+LIMIT @page_limit;
 
 -- name: ListBugHuntHighlights :many
 WITH highlights AS (SELECT h.repo_id,
@@ -147,24 +221,34 @@ WITH highlights AS (SELECT h.repo_id,
                            h.linter_id,
                            linters.linter_git_url,
                            linters.linter_git_branch,
+                           linters.linter_owner,
                            h.linter_docker_image,
                            h.linter_docker_sha_hash,
 
                            h.lint_status,
                            h.lint_status_comment,
                            h.lint_duration,
+                           h.created_at,
 
                            h.lint_id,
                            h.path,
                            h.start_line,
                            h.end_line,
+                           h.start_column,
+                           h.end_column,
+                           h.severity,
+                           h.rule_id,
                            h.explanation,
                            h.snippet_start_line,
                            h.snippet_end_line,
                            h.snippet_code,
+                           h.fingerprint,
                            h.moderation_status,
                            h.moderation_comment,
-                           h.moderated_at
+                           h.moderated_at,
+                           tracks.first_seen_commit_hash,
+                           tracks.last_seen_commit_hash,
+                           tracks.fixed_commit_hash
                     FROM (SELECT lint_tasks.repo_id,
                                  lint_tasks.repo_git_commit_hash,
                                  lint_tasks.linter_id,
@@ -173,15 +257,21 @@ WITH highlights AS (SELECT h.repo_id,
                                  lint_tasks.lint_status,
                                  lint_tasks.lint_status_comment,
                                  lint_tasks.lint_duration,
+                                 lint_tasks.created_at,
 
                                  lint_highlights.lint_id,
                                  lint_highlights.path,
                                  lint_highlights.start_line,
                                  lint_highlights.end_line,
+                                 lint_highlights.start_column,
+                                 lint_highlights.end_column,
+                                 lint_highlights.severity,
+                                 lint_highlights.rule_id,
                                  lint_highlights.explanation,
                                  lint_highlights.snippet_start_line,
                                  lint_highlights.snippet_end_line,
                                  lint_highlights.snippet_code,
+                                 lint_highlights.fingerprint,
                                  lint_highlights.moderation_status,
                                  lint_highlights.moderation_comment,
                                  lint_highlights.moderated_at
@@ -189,23 +279,42 @@ WITH highlights AS (SELECT h.repo_id,
                                    JOIN lint_tasks as lint_tasks ON lint_highlights.lint_id = lint_tasks.lint_id) as h
                              JOIN linters as linters ON h.linter_id = linters.linter_id
                              JOIN repos as repos ON h.repo_id = repos.repo_id
+                             LEFT JOIN highlight_tracks as tracks ON h.repo_id = tracks.repo_id
+                        AND h.linter_id = tracks.linter_id
+                        AND h.fingerprint = tracks.fingerprint
                     WHERE (@lint_id = '' OR h.lint_id = @lint_id)
                       AND (@linter_id = '' OR h.linter_id = @linter_id)
-                      AND (@repo_id = '' OR h.repo_id = @repo_id))
+                      AND (@repo_id = '' OR h.repo_id = @repo_id)
+                      AND (@rule_id = '' OR h.rule_id = @rule_id)
+                      AND (@severity = '' OR h.severity::text = @severity))
 SELECT *
 FROM highlights as t
 WHERE moderation_status = (SELECT MAX(moderation_status)
                            FROM highlights as h
                            WHERE t.repo_id = h.repo_id
-                             AND t.path = h.path
-                             AND t.start_line = h.start_line
-                             AND t.end_line = h.end_line)
-ORDER BY (t.moderation_status, t.repo_id, t.path, t.start_line);
+                             AND t.linter_id = h.linter_id
+                             AND COALESCE(NULLIF(t.fingerprint, ''), t.path || ':' || t.start_line || ':' || t.end_line) =
+                                 COALESCE(NULLIF(h.fingerprint, ''), h.path || ':' || h.start_line || ':' || h.end_line))
+  AND (@moderation_status = '' OR t.moderation_status::text = @moderation_status)
+  AND (@all_pending::bool OR t.moderation_status <> 'pending' OR t.linter_owner = @pending_owner::text)
+  AND (@after_lint_id::text = '' OR
+       (t.created_at, t.lint_id, t.path, t.start_line, t.end_line, t.fingerprint) >
+       (@after_created_at::timestamp, @after_lint_id::text, @after_path::text, @after_start_line::int, @after_end_line::int,
+        @after_fingerprint::text))
+ORDER BY t.created_at, t.lint_id, t.path, t.start_line, t.end_line, t.fingerprint
+LIMIT @page_limit;
 
 -- name: ModerateBugHuntHighlight :exec
+WITH target AS (SELECT t.repo_id, t.linter_id, h.fingerprint
+                FROM lint_highlights as h
+                         JOIN lint_tasks as t ON h.lint_id = t.lint_id
+                WHERE h.lint_id = $1
+                  AND h.fingerprint = $2)
 UPDATE lint_highlights
-SET moderation_status = $5
-WHERE lint_id = $1
-  AND path = $2
-  AND start_line = $3
-  AND end_line = $4;
+SET moderation_status  = $3,
+    moderation_comment = $4,
+    moderated_at       = $5,
+    moderated_by       = $6
+WHERE (lint_id, fingerprint) IN (SELECT t.lint_id, target.fingerprint
+                                 FROM target
+                                          JOIN lint_tasks as t ON t.repo_id = target.repo_id AND t.linter_id = target.linter_id);
@@ -0,0 +1,49 @@
+-- name: TrackLintHighlights :execrows
+INSERT INTO highlight_tracks (repo_id, linter_id, fingerprint, first_seen_commit_hash, first_seen_at, last_seen_commit_hash, last_seen_at)
+SELECT DISTINCT t.repo_id, t.linter_id, h.fingerprint, t.repo_git_commit_hash, t.created_at, t.repo_git_commit_hash, t.created_at
+FROM lint_highlights as h
+         JOIN lint_tasks as t ON h.lint_id = t.lint_id
+WHERE h.lint_id = $1
+  AND h.fingerprint <> ''
+ON CONFLICT (repo_id, linter_id, fingerprint)
+    DO UPDATE SET first_seen_commit_hash = CASE
+                                               WHEN excluded.first_seen_at < highlight_tracks.first_seen_at
+                                                   THEN excluded.first_seen_commit_hash
+                                               ELSE highlight_tracks.first_seen_commit_hash END,
+                  first_seen_at          = LEAST(excluded.first_seen_at, highlight_tracks.first_seen_at),
+                  last_seen_commit_hash  = CASE
+                                               WHEN excluded.last_seen_at >= highlight_tracks.last_seen_at
+                                                   THEN excluded.last_seen_commit_hash
+                                               ELSE highlight_tracks.last_seen_commit_hash END,
+                  last_seen_at           = GREATEST(excluded.last_seen_at, highlight_tracks.last_seen_at),
+                  fixed_commit_hash      = CASE
+                                               WHEN excluded.last_seen_at >= highlight_tracks.fixed_at
+                                                   THEN NULL
+                                               ELSE highlight_tracks.fixed_commit_hash END,
+                  fixed_at               = CASE
+                                               WHEN excluded.last_seen_at >= highlight_tracks.fixed_at
+                                                   THEN NULL
+                                               ELSE highlight_tracks.fixed_at END;
+
+-- name: FixLintHighlights :execrows
+UPDATE highlight_tracks as tr
+SET fixed_commit_hash = t.repo_git_commit_hash,
+    fixed_at          = t.created_at
+FROM lint_tasks as t
+WHERE t.lint_id = $1
+  AND tr.repo_id = t.repo_id
+  AND tr.linter_id = t.linter_id
+  AND tr.last_seen_at < t.created_at
+  AND tr.last_seen_commit_hash <> t.repo_git_commit_hash
+  AND (tr.fixed_at IS NULL OR tr.fixed_at > t.created_at)
+  AND NOT EXISTS (SELECT 1
+                  FROM lint_highlights as h
+                  WHERE h.lint_id = $1
+                    AND h.fingerprint = tr.fingerprint)
+  AND EXISTS (SELECT 1
+              FROM lint_highlights as h
+                       JOIN lint_tasks as seen ON h.lint_id = seen.lint_id
+              WHERE h.fingerprint = tr.fingerprint
+                AND seen.repo_id = t.repo_id
+                AND seen.linter_id = t.linter_id
+                AND seen.linter_docker_sha_hash = t.linter_docker_sha_hash);
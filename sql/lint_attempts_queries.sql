@@ -0,0 +1,35 @@
+-- name: AddLintAttempt :one
+INSERT INTO lint_attempts (lint_id, attempt, worker_id, started_at)
+VALUES ($1, $2, $3, $4)
+RETURNING attempt_id;
+
+-- name: FinishLintAttempt :execrows
+UPDATE lint_attempts
+SET finished_at = $2,
+    lint_status = $3,
+    exit_code   = $4,
+    error       = $5
+WHERE attempt_id = $1
+  AND finished_at IS NULL;
+
+-- name: AbandonLintAttempts :execrows
+UPDATE lint_attempts
+SET finished_at = $2,
+    lint_status = 'cancelled',
+    error       = $3
+WHERE lint_id = $1
+  AND finished_at IS NULL;
+
+-- name: ListLintAttempts :many
+SELECT a.attempt_id,
+       a.lint_id,
+       a.attempt,
+       a.worker_id,
+       a.started_at,
+       a.finished_at,
+       a.lint_status,
+       a.exit_code,
+       a.error
+FROM lint_attempts as a
+WHERE a.lint_id = ANY (@lint_ids::text[])
+ORDER BY a.lint_id, a.attempt_id;
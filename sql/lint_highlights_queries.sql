@@ -1,3 +1,28 @@
 -- name: AddLintHighlight :batchexec
-INSERT INTO lint_highlights (lint_id, path, start_line, end_line, explanation, snippet_start_line, snippet_end_line, snippet_code)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
\ No newline at end of file
+INSERT INTO lint_highlights (lint_id, path, start_line, end_line, start_column, end_column, severity, rule_id, explanation, snippet_start_line, snippet_end_line, snippet_code, fingerprint)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
+
+-- name: DeleteLintHighlights :exec
+DELETE FROM lint_highlights WHERE lint_id = $1;
+
+-- name: InheritLintHighlightsModeration :execrows
+UPDATE lint_highlights as h
+SET moderation_status  = prev.moderation_status,
+    moderation_comment = prev.moderation_comment,
+    moderated_at       = prev.moderated_at,
+    moderated_by       = prev.moderated_by
+FROM (SELECT DISTINCT ON (ph.fingerprint) ph.fingerprint,
+                                          ph.moderation_status,
+                                          ph.moderation_comment,
+                                          ph.moderated_at,
+                                          ph.moderated_by
+      FROM lint_highlights as ph
+               JOIN lint_tasks as pt ON ph.lint_id = pt.lint_id
+               JOIN lint_tasks as t ON pt.repo_id = t.repo_id AND pt.linter_id = t.linter_id
+      WHERE t.lint_id = $1
+        AND ph.lint_id <> $1
+        AND ph.fingerprint <> ''
+        AND ph.moderation_status <> 'pending'
+      ORDER BY ph.fingerprint, ph.moderated_at DESC NULLS LAST) as prev
+WHERE h.lint_id = $1
+  AND h.fingerprint = prev.fingerprint;
@@ -0,0 +1,16 @@
+-- name: SetLintLogs :exec
+INSERT INTO lint_logs (lint_id, attempt, stdout, stderr, stdout_bytes, stderr_bytes, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (lint_id) DO UPDATE SET attempt      = excluded.attempt,
+                                    stdout       = excluded.stdout,
+                                    stderr       = excluded.stderr,
+                                    stdout_bytes = excluded.stdout_bytes,
+                                    stderr_bytes = excluded.stderr_bytes,
+                                    created_at   = excluded.created_at;
+
+-- name: GetLintLogs :one
+SELECT l.lint_id, l.attempt, l.stdout, l.stderr, l.stdout_bytes, l.stderr_bytes, l.created_at, linters.linter_owner
+FROM lint_logs as l
+         JOIN lint_tasks as t ON l.lint_id = t.lint_id
+         LEFT JOIN linters as linters ON t.linter_id = linters.linter_id
+WHERE l.lint_id = $1;
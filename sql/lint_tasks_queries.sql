@@ -3,14 +3,29 @@ INSERT INTO lint_tasks
 (lint_id, lint_status, linter_id, linter_docker_image, linter_docker_sha_hash, repo_id, repo_git_url, repo_git_commit_hash, created_at)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 
--- name: SetLintTask :exec
+-- name: SetLintTask :execrows
 UPDATE lint_tasks
 SET lint_status         = $2,
     lint_status_comment = $3,
     lint_duration       = $4,
     linted_at           = $5,
-    locked_at           = NULL
-WHERE lint_id = $1;
+    next_attempt_at     = $7,
+    cpu_seconds         = $8,
+    max_rss_bytes       = $9,
+    io_bytes            = $10,
+    max_pids            = $11,
+    locked_at           = NULL,
+    locked_by           = NULL
+WHERE lint_id = $1
+  AND locked_by = $6
+  AND lint_status = 'running';
+
+-- name: HeartbeatLintTask :execrows
+UPDATE lint_tasks
+SET locked_at = $3
+WHERE lint_id = $1
+  AND locked_by = $2
+  AND lint_status = 'running';
 
 -- name: TryTakeLintTask :one
 WITH available_tasks AS (SELECT lint_id,
@@ -22,12 +37,15 @@ WITH available_tasks AS (SELECT lint_id,
     repo_git_commit_hash,
     locked_at
     FROM lint_tasks t
-    WHERE lint_status = 'pending'
-    AND (t.locked_at IS NULL OR t.locked_at <= @lock_time_lower_bound)
+    WHERE (t.lint_status = 'pending' AND (t.next_attempt_at IS NULL OR t.next_attempt_at <= @locked_at))
+    OR (t.lint_status = 'running' AND t.locked_at <= @lock_time_lower_bound)
     ORDER BY created_at
     LIMIT 1 FOR UPDATE)
 UPDATE lint_tasks as t
-SET locked_at = @locked_at
+SET lint_status   = 'running',
+    lint_attempts = t.lint_attempts + 1,
+    locked_at     = @locked_at,
+    locked_by     = @locked_by
 FROM available_tasks
 WHERE t.lint_id = available_tasks.lint_id
     RETURNING
@@ -37,4 +55,33 @@ WHERE t.lint_id = available_tasks.lint_id
     t.linter_docker_sha_hash,
     t.repo_id,
     t.repo_git_url,
-    t.repo_git_commit_hash;
\ No newline at end of file
+    t.repo_git_commit_hash,
+    t.lint_attempts;
+
+-- name: CancelLinterLintTasks :execrows
+WITH cancelled_attempts AS (UPDATE lint_attempts as a
+    SET finished_at = @cancelled_at,
+        lint_status = 'cancelled',
+        error       = @comment
+    FROM lint_tasks as t
+    WHERE a.lint_id = t.lint_id
+      AND t.linter_id = @linter_id
+      AND t.lint_status = 'running'
+      AND a.finished_at IS NULL)
+UPDATE lint_tasks
+SET lint_status         = 'cancelled',
+    lint_status_comment = @comment,
+    linted_at           = @cancelled_at,
+    next_attempt_at     = NULL,
+    locked_at           = NULL,
+    locked_by           = NULL
+WHERE linter_id = @linter_id
+  AND lint_status IN ('pending', 'running');
+
+-- name: ResumeLinterLintTasks :execrows
+UPDATE lint_tasks
+SET lint_status         = 'pending',
+    lint_status_comment = NULL,
+    lint_attempts       = 0
+WHERE linter_id = $1
+  AND lint_status = 'cancelled';
@@ -2,8 +2,13 @@
 SELECT linter_id,
        linter_git_url,
        linter_git_branch,
+       linter_last_git_commit_hash,
        linter_last_docker_image,
-       linter_last_docker_sha_hash
+       linter_last_docker_sha_hash,
+       linter_status,
+       linter_owner,
+       linter_failed_git_commit_hash,
+       linter_build_error
 FROM linters
 WHERE linter_id = $1;
 
@@ -14,17 +19,60 @@ DELETE FROM linters WHERE linter_id = $1;
 SELECT linter_id,
        linter_git_url,
        linter_git_branch,
+       linter_last_git_commit_hash,
        linter_last_docker_image,
-       linter_last_docker_sha_hash
+       linter_last_docker_sha_hash,
+       linter_status,
+       linter_owner,
+       linter_failed_git_commit_hash,
+       linter_build_error
 FROM linters
 ORDER BY updated_at DESC;
 
 -- name: UpsertLinter :exec
-INSERT INTO linters (linter_id, linter_git_url, linter_git_branch, linter_last_docker_image, linter_last_docker_sha_hash, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $6)
+INSERT INTO linters (linter_id, linter_git_url, linter_git_branch, linter_last_git_commit_hash, linter_last_docker_image, linter_last_docker_sha_hash, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
 ON CONFLICT (linter_id)
-    DO UPDATE SET linter_git_url              = $2,
-    linter_git_branch           = $3,
-    linter_last_docker_image    = $4,
-    linter_last_docker_sha_hash = $5,
-    updated_at                  = $6;
\ No newline at end of file
+    DO UPDATE SET linter_git_url                = $2,
+    linter_git_branch             = $3,
+    linter_last_git_commit_hash   = $4,
+    linter_last_docker_image      = $5,
+    linter_last_docker_sha_hash   = $6,
+    linter_failed_git_commit_hash = NULL,
+    linter_build_error            = NULL,
+    updated_at                    = $7;
+
+-- name: SubmitLinter :exec
+INSERT INTO linters (linter_id, linter_git_url, linter_git_branch, linter_last_docker_image, linter_last_docker_sha_hash, linter_status, linter_owner, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, 'pending', $6, $7, $7);
+
+-- name: SetLinterStatus :exec
+UPDATE linters
+SET linter_status = $2,
+    updated_at    = $3
+WHERE linter_id = $1;
+
+-- name: SetLinterBuildFailure :exec
+UPDATE linters
+SET linter_failed_git_commit_hash = $2,
+    linter_build_error            = $3
+WHERE linter_id = $1;
+
+-- name: UpdateLinterSource :exec
+UPDATE linters
+SET linter_git_url                = $2,
+    linter_git_branch             = $3,
+    linter_last_git_commit_hash   = NULL,
+    linter_failed_git_commit_hash = NULL,
+    linter_build_error            = NULL,
+    linter_last_docker_image      = COALESCE($4, linter_last_docker_image),
+    linter_last_docker_sha_hash   = COALESCE($5, linter_last_docker_sha_hash),
+    linter_status                 = CASE
+                                        WHEN linter_git_url <> $2
+                                            OR linter_git_branch <> $3
+                                            OR linter_last_docker_image IS DISTINCT FROM COALESCE($4, linter_last_docker_image)
+                                            OR linter_last_docker_sha_hash IS DISTINCT FROM COALESCE($5, linter_last_docker_sha_hash)
+                                            THEN 'pending'::linter_status
+                                        ELSE linter_status END,
+    updated_at                    = $6
+WHERE linter_id = $1;
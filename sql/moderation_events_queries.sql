@@ -0,0 +1,54 @@
+-- name: AddModerationEvent :one
+INSERT INTO moderation_events (lint_id, path, start_line, end_line, fingerprint, previous_status, moderation_status,
+                               vote, moderator_login, comment, reverted_event_id, created_at)
+SELECT h.lint_id, h.path, h.start_line, h.end_line, h.fingerprint, h.moderation_status, $3, $4, $5, $6, $7, $8
+FROM lint_highlights as h
+WHERE h.lint_id = $1
+  AND h.fingerprint = $2
+RETURNING event_id;
+
+-- name: GetModerationEvent :one
+SELECT event_id,
+       lint_id,
+       path,
+       start_line,
+       end_line,
+       fingerprint,
+       previous_status,
+       moderation_status,
+       vote,
+       moderator_login,
+       comment,
+       reverted_event_id,
+       created_at
+FROM moderation_events
+WHERE event_id = $1;
+
+-- name: GetModerationStatus :one
+SELECT moderation_status
+FROM lint_highlights
+WHERE lint_id = $1
+  AND fingerprint = $2
+FOR UPDATE;
+
+-- name: ListModerationEvents :many
+SELECT e.event_id,
+       t.repo_id,
+       t.linter_id,
+       e.lint_id,
+       e.path,
+       e.start_line,
+       e.end_line,
+       e.fingerprint,
+       e.previous_status,
+       e.moderation_status,
+       e.vote,
+       e.moderator_login,
+       e.comment,
+       e.reverted_event_id,
+       e.created_at
+FROM moderation_events as e
+         JOIN lint_tasks as t ON e.lint_id = t.lint_id
+WHERE e.fingerprint = ANY (@fingerprints::text[])
+  AND (t.repo_id, t.linter_id) IN (SELECT repo_id, linter_id FROM lint_tasks WHERE lint_id = ANY (@lint_ids::text[]))
+ORDER BY e.created_at, e.event_id;
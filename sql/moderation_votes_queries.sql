@@ -0,0 +1,40 @@
+-- name: UpsertModerationVote :execrows
+INSERT INTO moderation_votes (repo_id, linter_id, highlight_key, moderator_login, vote, comment, voted_at)
+SELECT t.repo_id,
+       t.linter_id,
+       COALESCE(NULLIF(h.fingerprint, ''), h.path || ':' || h.start_line || ':' || h.end_line),
+       $3,
+       $4,
+       $5,
+       $6
+FROM lint_highlights as h
+         JOIN lint_tasks as t ON h.lint_id = t.lint_id
+WHERE h.lint_id = $1
+  AND h.fingerprint = $2
+ON CONFLICT (repo_id, linter_id, highlight_key, moderator_login)
+    DO UPDATE SET vote     = excluded.vote,
+                  comment  = excluded.comment,
+                  voted_at = excluded.voted_at;
+
+-- name: DeleteModerationVote :exec
+DELETE
+FROM moderation_votes as v
+    USING lint_highlights as h
+        JOIN lint_tasks as t ON h.lint_id = t.lint_id
+WHERE h.lint_id = $1
+  AND h.fingerprint = $2
+  AND v.moderator_login = $3
+  AND v.repo_id = t.repo_id
+  AND v.linter_id = t.linter_id
+  AND v.highlight_key = COALESCE(NULLIF(h.fingerprint, ''), h.path || ':' || h.start_line || ':' || h.end_line);
+
+-- name: CountModerationVotes :one
+SELECT COUNT(DISTINCT v.moderator_login) FILTER (WHERE v.vote = 'accepted') as accepted_votes,
+       COUNT(DISTINCT v.moderator_login) FILTER (WHERE v.vote = 'rejected') as rejected_votes
+FROM lint_highlights as h
+         JOIN lint_tasks as t ON h.lint_id = t.lint_id
+         JOIN moderation_votes as v ON v.repo_id = t.repo_id
+    AND v.linter_id = t.linter_id
+    AND v.highlight_key = COALESCE(NULLIF(h.fingerprint, ''), h.path || ':' || h.start_line || ':' || h.end_line)
+WHERE h.lint_id = $1
+  AND h.fingerprint = $2;
@@ -0,0 +1,12 @@
+-- name: RevokeSession :exec
+INSERT INTO revoked_sessions (session_id, user_login, revoked_at, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (session_id) DO NOTHING;
+
+-- name: IsSessionRevoked :one
+SELECT EXISTS(SELECT 1 FROM revoked_sessions WHERE session_id = $1)::bool;
+
+-- name: DeleteExpiredRevokedSessions :execrows
+DELETE
+FROM revoked_sessions
+WHERE expires_at < $1;
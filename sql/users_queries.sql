@@ -0,0 +1,39 @@
+-- name: AddUser :exec
+INSERT INTO users (user_login, created_at, last_login_at)
+VALUES ($1, $2, $2)
+ON CONFLICT (user_login) DO NOTHING;
+
+-- name: UpsertUser :exec
+INSERT INTO users (user_login, user_name, created_at, last_login_at)
+VALUES ($1, $2, $3, $3)
+ON CONFLICT (user_login)
+    DO UPDATE SET user_name     = $2,
+                  last_login_at = $3;
+
+-- name: ListUsers :many
+SELECT users.user_login,
+       users.user_name,
+       users.created_at,
+       users.last_login_at,
+       COALESCE(string_agg(user_roles.role::text, ',' ORDER BY user_roles.role), '')::text as roles
+FROM users as users
+         LEFT JOIN user_roles as user_roles ON users.user_login = user_roles.user_login
+GROUP BY users.user_login, users.user_name, users.created_at, users.last_login_at
+ORDER BY users.last_login_at DESC;
+
+-- name: ListUserRoles :many
+SELECT role
+FROM user_roles
+WHERE user_login = $1
+ORDER BY role;
+
+-- name: GrantUserRole :exec
+INSERT INTO user_roles (user_login, role, granted_by, granted_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_login, role) DO NOTHING;
+
+-- name: RevokeUserRole :exec
+DELETE
+FROM user_roles
+WHERE user_login = $1
+  AND role = $2;
@@ -17,8 +17,8 @@ var (
 )
 
 const addLintHighlight = `-- name: AddLintHighlight :batchexec
-INSERT INTO lint_highlights (lint_id, path, start_line, end_line, explanation, snippet_start_line, snippet_end_line, snippet_code)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO lint_highlights (lint_id, path, start_line, end_line, start_column, end_column, severity, rule_id, explanation, snippet_start_line, snippet_end_line, snippet_code, fingerprint)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 `
 
 type AddLintHighlightBatchResults struct {
@@ -32,10 +32,15 @@ type AddLintHighlightParams struct {
 	Path             string
 	StartLine        int32
 	EndLine          int32
+	StartColumn      int32
+	EndColumn        int32
+	Severity         HighlightSeverity
+	RuleID           string
 	Explanation      string
 	SnippetStartLine int32
 	SnippetEndLine   int32
 	SnippetCode      string
+	Fingerprint      string
 }
 
 func (q *Queries) AddLintHighlight(ctx context.Context, arg []AddLintHighlightParams) *AddLintHighlightBatchResults {
@@ -46,10 +51,15 @@ func (q *Queries) AddLintHighlight(ctx context.Context, arg []AddLintHighlightPa
 			a.Path,
 			a.StartLine,
 			a.EndLine,
+			a.StartColumn,
+			a.EndColumn,
+			a.Severity,
+			a.RuleID,
 			a.Explanation,
 			a.SnippetStartLine,
 			a.SnippetEndLine,
 			a.SnippetCode,
+			a.Fingerprint,
 		}
 		batch.Queue(addLintHighlight, vals...)
 	}
@@ -20,24 +20,34 @@ WITH highlights AS (SELECT h.repo_id,
                            h.linter_id,
                            linters.linter_git_url,
                            linters.linter_git_branch,
+                           linters.linter_owner,
                            h.linter_docker_image,
                            h.linter_docker_sha_hash,
 
                            h.lint_status,
                            h.lint_status_comment,
                            h.lint_duration,
+                           h.created_at,
 
                            h.lint_id,
                            h.path,
                            h.start_line,
                            h.end_line,
+                           h.start_column,
+                           h.end_column,
+                           h.severity,
+                           h.rule_id,
                            h.explanation,
                            h.snippet_start_line,
                            h.snippet_end_line,
                            h.snippet_code,
+                           h.fingerprint,
                            h.moderation_status,
                            h.moderation_comment,
-                           h.moderated_at
+                           h.moderated_at,
+                           tracks.first_seen_commit_hash,
+                           tracks.last_seen_commit_hash,
+                           tracks.fixed_commit_hash
                     FROM (SELECT lint_tasks.repo_id,
                                  lint_tasks.repo_git_commit_hash,
                                  lint_tasks.linter_id,
@@ -46,15 +56,21 @@ WITH highlights AS (SELECT h.repo_id,
                                  lint_tasks.lint_status,
                                  lint_tasks.lint_status_comment,
                                  lint_tasks.lint_duration,
+                                 lint_tasks.created_at,
 
                                  lint_highlights.lint_id,
                                  lint_highlights.path,
                                  lint_highlights.start_line,
                                  lint_highlights.end_line,
+                                 lint_highlights.start_column,
+                                 lint_highlights.end_column,
+                                 lint_highlights.severity,
+                                 lint_highlights.rule_id,
                                  lint_highlights.explanation,
                                  lint_highlights.snippet_start_line,
                                  lint_highlights.snippet_end_line,
                                  lint_highlights.snippet_code,
+                                 lint_highlights.fingerprint,
                                  lint_highlights.moderation_status,
                                  lint_highlights.moderation_comment,
                                  lint_highlights.moderated_at
@@ -62,24 +78,48 @@ WITH highlights AS (SELECT h.repo_id,
                                    JOIN lint_tasks as lint_tasks ON lint_highlights.lint_id = lint_tasks.lint_id) as h
                              JOIN linters as linters ON h.linter_id = linters.linter_id
                              JOIN repos as repos ON h.repo_id = repos.repo_id
+                             LEFT JOIN highlight_tracks as tracks ON h.repo_id = tracks.repo_id
+                        AND h.linter_id = tracks.linter_id
+                        AND h.fingerprint = tracks.fingerprint
                     WHERE ($1 = '' OR h.lint_id = $1)
                       AND ($2 = '' OR h.linter_id = $2)
-                      AND ($3 = '' OR h.repo_id = $3))
-SELECT repo_id, repo_git_url, repo_git_branch, repo_git_commit_hash, linter_id, linter_git_url, linter_git_branch, linter_docker_image, linter_docker_sha_hash, lint_status, lint_status_comment, lint_duration, lint_id, path, start_line, end_line, explanation, snippet_start_line, snippet_end_line, snippet_code, moderation_status, moderation_comment, moderated_at
+                      AND ($3 = '' OR h.repo_id = $3)
+                      AND ($4 = '' OR h.rule_id = $4)
+                      AND ($5 = '' OR h.severity::text = $5))
+SELECT repo_id, repo_git_url, repo_git_branch, repo_git_commit_hash, linter_id, linter_git_url, linter_git_branch, linter_owner, linter_docker_image, linter_docker_sha_hash, lint_status, lint_status_comment, lint_duration, created_at, lint_id, path, start_line, end_line, start_column, end_column, severity, rule_id, explanation, snippet_start_line, snippet_end_line, snippet_code, fingerprint, moderation_status, moderation_comment, moderated_at, first_seen_commit_hash, last_seen_commit_hash, fixed_commit_hash
 FROM highlights as t
 WHERE moderation_status = (SELECT MAX(moderation_status)
                            FROM highlights as h
                            WHERE t.repo_id = h.repo_id
-                             AND t.path = h.path
-                             AND t.start_line = h.start_line
-                             AND t.end_line = h.end_line)
-ORDER BY (t.moderation_status, t.repo_id, t.path, t.start_line)
+                             AND t.linter_id = h.linter_id
+                             AND COALESCE(NULLIF(t.fingerprint, ''), t.path || ':' || t.start_line || ':' || t.end_line) =
+                                 COALESCE(NULLIF(h.fingerprint, ''), h.path || ':' || h.start_line || ':' || h.end_line))
+  AND ($6 = '' OR t.moderation_status::text = $6)
+  AND ($7::bool OR t.moderation_status <> 'pending' OR t.linter_owner = $8::text)
+  AND ($9::text = '' OR
+       (t.created_at, t.lint_id, t.path, t.start_line, t.end_line, t.fingerprint) >
+       ($10::timestamp, $9::text, $11::text, $12::int, $13::int,
+        $14::text))
+ORDER BY t.created_at, t.lint_id, t.path, t.start_line, t.end_line, t.fingerprint
+LIMIT $15
 `
 
 type ListBugHuntHighlightsParams struct {
-	LintID   interface{}
-	LinterID interface{}
-	RepoID   interface{}
+	LintID           interface{}
+	LinterID         interface{}
+	RepoID           interface{}
+	RuleID           interface{}
+	Severity         interface{}
+	ModerationStatus interface{}
+	AllPending       bool
+	PendingOwner     string
+	AfterLintID      string
+	AfterCreatedAt   pgtype.Timestamp
+	AfterPath        string
+	AfterStartLine   int32
+	AfterEndLine     int32
+	AfterFingerprint string
+	PageLimit        int32
 }
 
 type ListBugHuntHighlightsRow struct {
@@ -90,26 +130,52 @@ type ListBugHuntHighlightsRow struct {
 	LinterID            string
 	LinterGitUrl        string
 	LinterGitBranch     string
+	LinterOwner         pgtype.Text
 	LinterDockerImage   string
 	LinterDockerShaHash string
 	LintStatus          LintStatus
 	LintStatusComment   pgtype.Text
 	LintDuration        pgtype.Interval
+	CreatedAt           pgtype.Timestamp
 	LintID              string
 	Path                string
 	StartLine           int32
 	EndLine             int32
+	StartColumn         int32
+	EndColumn           int32
+	Severity            HighlightSeverity
+	RuleID              string
 	Explanation         string
 	SnippetStartLine    int32
 	SnippetEndLine      int32
 	SnippetCode         string
+	Fingerprint         string
 	ModerationStatus    HighlightStatus
 	ModerationComment   pgtype.Text
 	ModeratedAt         pgtype.Timestamp
+	FirstSeenCommitHash pgtype.Text
+	LastSeenCommitHash  pgtype.Text
+	FixedCommitHash     pgtype.Text
 }
 
 func (q *Queries) ListBugHuntHighlights(ctx context.Context, arg ListBugHuntHighlightsParams) ([]ListBugHuntHighlightsRow, error) {
-	rows, err := q.db.Query(ctx, listBugHuntHighlights, arg.LintID, arg.LinterID, arg.RepoID)
+	rows, err := q.db.Query(ctx, listBugHuntHighlights,
+		arg.LintID,
+		arg.LinterID,
+		arg.RepoID,
+		arg.RuleID,
+		arg.Severity,
+		arg.ModerationStatus,
+		arg.AllPending,
+		arg.PendingOwner,
+		arg.AfterLintID,
+		arg.AfterCreatedAt,
+		arg.AfterPath,
+		arg.AfterStartLine,
+		arg.AfterEndLine,
+		arg.AfterFingerprint,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -125,22 +191,32 @@ func (q *Queries) ListBugHuntHighlights(ctx context.Context, arg ListBugHuntHigh
 			&i.LinterID,
 			&i.LinterGitUrl,
 			&i.LinterGitBranch,
+			&i.LinterOwner,
 			&i.LinterDockerImage,
 			&i.LinterDockerShaHash,
 			&i.LintStatus,
 			&i.LintStatusComment,
 			&i.LintDuration,
+			&i.CreatedAt,
 			&i.LintID,
 			&i.Path,
 			&i.StartLine,
 			&i.EndLine,
+			&i.StartColumn,
+			&i.EndColumn,
+			&i.Severity,
+			&i.RuleID,
 			&i.Explanation,
 			&i.SnippetStartLine,
 			&i.SnippetEndLine,
 			&i.SnippetCode,
+			&i.Fingerprint,
 			&i.ModerationStatus,
 			&i.ModerationComment,
 			&i.ModeratedAt,
+			&i.FirstSeenCommitHash,
+			&i.LastSeenCommitHash,
+			&i.FixedCommitHash,
 		); err != nil {
 			return nil, err
 		}
@@ -160,23 +236,37 @@ SELECT repos.repo_id,
        linters.linter_id,
        linters.linter_git_url,
        linters.linter_git_branch,
+       linters.linter_owner,
        lint_tasks.linter_docker_image,
        lint_tasks.linter_docker_sha_hash,
        lint_tasks.lint_id,
        lint_tasks.lint_status,
        lint_tasks.lint_status_comment,
-       lint_tasks.lint_duration
+       lint_tasks.lint_duration,
+       lint_tasks.lint_attempts,
+       lint_tasks.next_attempt_at,
+       lint_tasks.cpu_seconds,
+       lint_tasks.max_rss_bytes,
+       lint_tasks.io_bytes,
+       lint_tasks.max_pids,
+       lint_tasks.created_at,
+       EXISTS (SELECT 1 FROM lint_logs WHERE lint_logs.lint_id = lint_tasks.lint_id) AS has_logs
 FROM lint_tasks as lint_tasks
          JOIN linters as linters ON linters.linter_id = lint_tasks.linter_id
          JOIN repos as repos ON repos.repo_id = lint_tasks.repo_id
-ORDER BY lint_tasks.lint_status,
-         lint_tasks.created_at DESC
-LIMIT $2 OFFSET $1
+WHERE (lint_tasks.linter_id = $1 OR $1 = '')
+  AND ($2::text = '' OR
+       (lint_tasks.created_at, lint_tasks.lint_id) < ($3::timestamp, $2::text))
+ORDER BY lint_tasks.created_at DESC,
+         lint_tasks.lint_id DESC
+LIMIT $4
 `
 
 type ListBugHuntLintTasksParams struct {
-	Offset int32
-	Limit  int32
+	LinterID        string
+	BeforeLintID    string
+	BeforeCreatedAt pgtype.Timestamp
+	PageLimit       int32
 }
 
 type ListBugHuntLintTasksRow struct {
@@ -187,16 +277,30 @@ type ListBugHuntLintTasksRow struct {
 	LinterID            string
 	LinterGitUrl        string
 	LinterGitBranch     string
+	LinterOwner         pgtype.Text
 	LinterDockerImage   string
 	LinterDockerShaHash string
 	LintID              string
 	LintStatus          LintStatus
 	LintStatusComment   pgtype.Text
 	LintDuration        pgtype.Interval
+	LintAttempts        int32
+	NextAttemptAt       pgtype.Timestamp
+	CpuSeconds          pgtype.Float8
+	MaxRssBytes         pgtype.Int8
+	IoBytes             pgtype.Int8
+	MaxPids             pgtype.Int8
+	CreatedAt           pgtype.Timestamp
+	HasLogs             bool
 }
 
 func (q *Queries) ListBugHuntLintTasks(ctx context.Context, arg ListBugHuntLintTasksParams) ([]ListBugHuntLintTasksRow, error) {
-	rows, err := q.db.Query(ctx, listBugHuntLintTasks, arg.Offset, arg.Limit)
+	rows, err := q.db.Query(ctx, listBugHuntLintTasks,
+		arg.LinterID,
+		arg.BeforeLintID,
+		arg.BeforeCreatedAt,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -212,12 +316,142 @@ func (q *Queries) ListBugHuntLintTasks(ctx context.Context, arg ListBugHuntLintT
 			&i.LinterID,
 			&i.LinterGitUrl,
 			&i.LinterGitBranch,
+			&i.LinterOwner,
 			&i.LinterDockerImage,
 			&i.LinterDockerShaHash,
 			&i.LintID,
 			&i.LintStatus,
 			&i.LintStatusComment,
 			&i.LintDuration,
+			&i.LintAttempts,
+			&i.NextAttemptAt,
+			&i.CpuSeconds,
+			&i.MaxRssBytes,
+			&i.IoBytes,
+			&i.MaxPids,
+			&i.CreatedAt,
+			&i.HasLogs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBugHuntLinterRules = `-- name: ListBugHuntLinterRules :many
+WITH highlights AS (SELECT t.linter_id,
+                           h.rule_id,
+                           t.repo_id,
+                           COALESCE(NULLIF(h.fingerprint, ''), h.path || ':' || h.start_line || ':' || h.end_line) as highlight_key,
+                           max(h.moderation_status) as moderation_status
+                    FROM lint_highlights as h
+                             JOIN lint_tasks as t ON h.lint_id = t.lint_id
+                    GROUP BY t.linter_id,
+                             h.rule_id,
+                             t.repo_id,
+                             highlight_key)
+SELECT h.linter_id,
+       h.rule_id,
+       COUNT(*)                                                                             as total_highlight,
+       COUNT(*) FILTER (WHERE h.moderation_status = 'pending')                               as pending_highlight,
+       COUNT(*) FILTER (WHERE h.moderation_status = 'rejected')                              as rejected_highlight,
+       COUNT(*) FILTER (WHERE h.moderation_status = 'accepted')                              as accepted_highlight,
+       COUNT(*) FILTER (WHERE h.moderation_status = 'accepted' AND tr.fixed_at IS NOT NULL) as fixed_highlight
+FROM highlights as h
+         LEFT JOIN highlight_tracks as tr ON h.repo_id = tr.repo_id
+    AND h.linter_id = tr.linter_id
+    AND h.highlight_key = tr.fingerprint
+WHERE h.rule_id <> ''
+GROUP BY h.linter_id, h.rule_id
+ORDER BY h.linter_id, accepted_highlight DESC, pending_highlight DESC, rejected_highlight, h.rule_id
+`
+
+type ListBugHuntLinterRulesRow struct {
+	LinterID          string
+	RuleID            string
+	TotalHighlight    int64
+	PendingHighlight  int64
+	RejectedHighlight int64
+	AcceptedHighlight int64
+	FixedHighlight    int64
+}
+
+func (q *Queries) ListBugHuntLinterRules(ctx context.Context) ([]ListBugHuntLinterRulesRow, error) {
+	rows, err := q.db.Query(ctx, listBugHuntLinterRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBugHuntLinterRulesRow
+	for rows.Next() {
+		var i ListBugHuntLinterRulesRow
+		if err := rows.Scan(
+			&i.LinterID,
+			&i.RuleID,
+			&i.TotalHighlight,
+			&i.PendingHighlight,
+			&i.RejectedHighlight,
+			&i.AcceptedHighlight,
+			&i.FixedHighlight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBugHuntLinterUsage = `-- name: ListBugHuntLinterUsage :many
+SELECT linter_id,
+       COUNT(*)                                as total_tasks,
+       COALESCE(SUM(cpu_seconds), 0)::FLOAT8   as total_cpu_seconds,
+       COALESCE(AVG(cpu_seconds), 0)::FLOAT8   as avg_cpu_seconds,
+       COALESCE(MAX(max_rss_bytes), 0)::BIGINT as max_rss_bytes,
+       COALESCE(AVG(max_rss_bytes), 0)::BIGINT as avg_rss_bytes,
+       COALESCE(AVG(io_bytes), 0)::BIGINT      as avg_io_bytes,
+       COALESCE(MAX(max_pids), 0)::BIGINT      as max_pids
+FROM lint_tasks
+WHERE cpu_seconds IS NOT NULL
+GROUP BY linter_id
+ORDER BY linter_id
+`
+
+type ListBugHuntLinterUsageRow struct {
+	LinterID        string
+	TotalTasks      int64
+	TotalCpuSeconds float64
+	AvgCpuSeconds   float64
+	MaxRssBytes     int64
+	AvgRssBytes     int64
+	AvgIoBytes      int64
+	MaxPids         int64
+}
+
+func (q *Queries) ListBugHuntLinterUsage(ctx context.Context) ([]ListBugHuntLinterUsageRow, error) {
+	rows, err := q.db.Query(ctx, listBugHuntLinterUsage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBugHuntLinterUsageRow
+	for rows.Next() {
+		var i ListBugHuntLinterUsageRow
+		if err := rows.Scan(
+			&i.LinterID,
+			&i.TotalTasks,
+			&i.TotalCpuSeconds,
+			&i.AvgCpuSeconds,
+			&i.MaxRssBytes,
+			&i.AvgRssBytes,
+			&i.AvgIoBytes,
+			&i.MaxPids,
 		); err != nil {
 			return nil, err
 		}
@@ -232,76 +466,96 @@ func (q *Queries) ListBugHuntLintTasks(ctx context.Context, arg ListBugHuntLintT
 const listBugHuntLinters = `-- name: ListBugHuntLinters :many
 WITH highlights AS (SELECT h.linter_id,
                            h.repo_id,
-                           h.path,
-                           h.start_line,
-                           h.end_line,
+                           h.highlight_key,
                            max(h.moderation_status) as moderation_status
                     FROM (SELECT t.lint_id,
                                  t.linter_id,
                                  t.linter_docker_sha_hash,
                                  t.repo_id,
                                  t.repo_git_commit_hash,
-                                 h.path,
-                                 h.start_line,
-                                 h.end_line,
+                                 COALESCE(NULLIF(h.fingerprint, ''), h.path || ':' || h.start_line || ':' || h.end_line) as highlight_key,
                                  h.moderation_status
                           FROM lint_highlights as h
                                    JOIN lint_tasks as t ON h.lint_id = t.lint_id) h
                     GROUP BY h.linter_id,
                              h.repo_id,
-                             h.path,
-                             h.start_line,
-                             h.end_line),
+                             h.highlight_key),
      linter_stats_total AS (SELECT linter_id,
-                                   COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                   COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                             FROM highlights
                             GROUP BY linter_id),
      linter_stats_pending AS (SELECT linter_id,
-                                     COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                     COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                               FROM highlights
                               WHERE moderation_status = 'pending'
                               GROUP BY linter_id),
      linter_stats_accepted AS (SELECT linter_id,
-                                      COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                      COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                                FROM highlights
                                WHERE moderation_status = 'accepted'
                                GROUP BY linter_id),
      linter_stats_rejected AS (SELECT linter_id,
-                                      COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                      COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                                FROM highlights
                                WHERE moderation_status = 'rejected'
-                               GROUP BY linter_id)
+                               GROUP BY linter_id),
+     linter_stats_fixed AS (SELECT h.linter_id,
+                                   COUNT(DISTINCT (h.repo_id, h.highlight_key)) as cnt
+                            FROM highlights as h
+                                     JOIN highlight_tracks as tr ON h.repo_id = tr.repo_id
+                                AND h.linter_id = tr.linter_id
+                                AND h.highlight_key = tr.fingerprint
+                            WHERE h.moderation_status = 'accepted'
+                              AND tr.fixed_at IS NOT NULL
+                            GROUP BY h.linter_id)
 SELECT linters.linter_id,
        linters.linter_git_url,
        linters.linter_git_branch,
        linters.linter_last_docker_image,
        linters.linter_last_docker_sha_hash,
+       linters.created_at,
+       linters.updated_at,
        COALESCE(total.cnt, 0)    as total_highlight,
        COALESCE(pending.cnt, 0)  as pending_highlight,
        COALESCE(rejected.cnt, 0) as rejected_highlight,
-       COALESCE(accepted.cnt, 0) as accepted_highlight
+       COALESCE(accepted.cnt, 0) as accepted_highlight,
+       COALESCE(fixed.cnt, 0)    as fixed_highlight
 FROM linters as linters
          LEFT JOIN linter_stats_total as total ON linters.linter_id = total.linter_id
          LEFT JOIN linter_stats_pending as pending ON linters.linter_id = pending.linter_id
          LEFT JOIN linter_stats_rejected as rejected ON linters.linter_id = rejected.linter_id
          LEFT JOIN linter_stats_accepted as accepted ON linters.linter_id = accepted.linter_id
-ORDER BY accepted_highlight DESC, pending_highlight DESC, rejected_highlight, updated_at DESC
+         LEFT JOIN linter_stats_fixed as fixed ON linters.linter_id = fixed.linter_id
+WHERE linters.linter_status IN ('active', 'paused')
+  AND ($1::text = '' OR
+       (linters.created_at, linters.linter_id) > ($2::timestamp, $1::text))
+ORDER BY linters.created_at, linters.linter_id
+LIMIT $3
 `
 
+type ListBugHuntLintersParams struct {
+	AfterLinterID  string
+	AfterCreatedAt pgtype.Timestamp
+	PageLimit      int32
+}
+
 type ListBugHuntLintersRow struct {
 	LinterID                string
 	LinterGitUrl            string
 	LinterGitBranch         string
 	LinterLastDockerImage   pgtype.Text
 	LinterLastDockerShaHash pgtype.Text
+	CreatedAt               pgtype.Timestamp
+	UpdatedAt               pgtype.Timestamp
 	TotalHighlight          int64
 	PendingHighlight        int64
 	RejectedHighlight       int64
 	AcceptedHighlight       int64
+	FixedHighlight          int64
 }
 
-func (q *Queries) ListBugHuntLinters(ctx context.Context) ([]ListBugHuntLintersRow, error) {
-	rows, err := q.db.Query(ctx, listBugHuntLinters)
+func (q *Queries) ListBugHuntLinters(ctx context.Context, arg ListBugHuntLintersParams) ([]ListBugHuntLintersRow, error) {
+	rows, err := q.db.Query(ctx, listBugHuntLinters, arg.AfterLinterID, arg.AfterCreatedAt, arg.PageLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -315,10 +569,13 @@ func (q *Queries) ListBugHuntLinters(ctx context.Context) ([]ListBugHuntLintersR
 			&i.LinterGitBranch,
 			&i.LinterLastDockerImage,
 			&i.LinterLastDockerShaHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 			&i.TotalHighlight,
 			&i.PendingHighlight,
 			&i.RejectedHighlight,
 			&i.AcceptedHighlight,
+			&i.FixedHighlight,
 		); err != nil {
 			return nil, err
 		}
@@ -337,9 +594,7 @@ WITH
                                 t.linter_docker_sha_hash,
                                 t.repo_id,
                                 t.repo_git_commit_hash,
-                                h.path,
-                                h.start_line,
-                                h.end_line,
+                                COALESCE(NULLIF(h.fingerprint, ''), h.path || ':' || h.start_line || ':' || h.end_line) as highlight_key,
                                 h.moderation_status
                           FROM lint_highlights as h
                           JOIN lint_tasks as t ON h.lint_id = t.lint_id
@@ -347,64 +602,85 @@ WITH
                           JOIN repos as r ON t.repo_id = r.repo_id),
     highlights AS (SELECT h.linter_id,
                            h.repo_id,
-                           h.path,
-                           h.start_line,
-                           h.end_line,
+                           h.highlight_key,
                            max(h.moderation_status) as moderation_status
                     FROM alive_highlights h
                     GROUP BY h.linter_id,
                              h.repo_id,
-                             h.path,
-                             h.start_line,
-                             h.end_line),
+                             h.highlight_key),
      repo_stats_total AS (SELECT repo_id,
-                                 COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                 COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                           FROM highlights
                           GROUP BY repo_id),
      repo_stats_pending AS (SELECT repo_id,
-                                   COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                   COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                             FROM highlights
                             WHERE moderation_status = 'pending'
                             GROUP BY repo_id),
      repo_stats_accepted AS (SELECT repo_id,
-                                    COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                    COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                              FROM highlights
                              WHERE moderation_status = 'accepted'
                              GROUP BY repo_id),
      repo_stats_rejected AS (SELECT repo_id,
-                                    COUNT(DISTINCT (repo_id, path, start_line, end_line)) as cnt
+                                    COUNT(DISTINCT (repo_id, highlight_key)) as cnt
                              FROM highlights
                              WHERE moderation_status = 'rejected'
-                             GROUP BY repo_id)
+                             GROUP BY repo_id),
+     repo_stats_fixed AS (SELECT h.repo_id,
+                                 COUNT(DISTINCT (h.repo_id, h.highlight_key)) as cnt
+                          FROM highlights as h
+                                   JOIN highlight_tracks as tr ON h.repo_id = tr.repo_id
+                              AND h.linter_id = tr.linter_id
+                              AND h.highlight_key = tr.fingerprint
+                          WHERE h.moderation_status = 'accepted'
+                            AND tr.fixed_at IS NOT NULL
+                          GROUP BY h.repo_id)
 SELECT repos.repo_id,
        repos.repo_git_url,
        repos.repo_git_branch,
        repos.repo_last_git_commit_hash,
+       repos.created_at,
+       repos.updated_at,
        COALESCE(total.cnt, 0)    as total_highlight,
        COALESCE(pending.cnt, 0)  as pending_highlight,
        COALESCE(rejected.cnt, 0) as rejected_highlight,
-       COALESCE(accepted.cnt, 0) as accepted_highlight
+       COALESCE(accepted.cnt, 0) as accepted_highlight,
+       COALESCE(fixed.cnt, 0)    as fixed_highlight
 FROM repos as repos
          LEFT JOIN repo_stats_total as total ON repos.repo_id = total.repo_id
          LEFT JOIN repo_stats_pending as pending ON repos.repo_id = pending.repo_id
          LEFT JOIN repo_stats_rejected as rejected ON repos.repo_id = rejected.repo_id
          LEFT JOIN repo_stats_accepted as accepted ON repos.repo_id = accepted.repo_id
-ORDER BY accepted_highlight DESC, pending_highlight DESC, rejected_highlight, updated_at DESC
+         LEFT JOIN repo_stats_fixed as fixed ON repos.repo_id = fixed.repo_id
+WHERE ($1::text = '' OR
+       (repos.created_at, repos.repo_id) > ($2::timestamp, $1::text))
+ORDER BY repos.created_at, repos.repo_id
+LIMIT $3
 `
 
+type ListBugHuntReposParams struct {
+	AfterRepoID    string
+	AfterCreatedAt pgtype.Timestamp
+	PageLimit      int32
+}
+
 type ListBugHuntReposRow struct {
 	RepoID                string
 	RepoGitUrl            string
 	RepoGitBranch         string
 	RepoLastGitCommitHash pgtype.Text
+	CreatedAt             pgtype.Timestamp
+	UpdatedAt             pgtype.Timestamp
 	TotalHighlight        int64
 	PendingHighlight      int64
 	RejectedHighlight     int64
 	AcceptedHighlight     int64
+	FixedHighlight        int64
 }
 
-func (q *Queries) ListBugHuntRepos(ctx context.Context) ([]ListBugHuntReposRow, error) {
-	rows, err := q.db.Query(ctx, listBugHuntRepos)
+func (q *Queries) ListBugHuntRepos(ctx context.Context, arg ListBugHuntReposParams) ([]ListBugHuntReposRow, error) {
+	rows, err := q.db.Query(ctx, listBugHuntRepos, arg.AfterRepoID, arg.AfterCreatedAt, arg.PageLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -417,10 +693,13 @@ func (q *Queries) ListBugHuntRepos(ctx context.Context) ([]ListBugHuntReposRow,
 			&i.RepoGitUrl,
 			&i.RepoGitBranch,
 			&i.RepoLastGitCommitHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 			&i.TotalHighlight,
 			&i.PendingHighlight,
 			&i.RejectedHighlight,
 			&i.AcceptedHighlight,
+			&i.FixedHighlight,
 		); err != nil {
 			return nil, err
 		}
@@ -433,29 +712,38 @@ func (q *Queries) ListBugHuntRepos(ctx context.Context) ([]ListBugHuntReposRow,
 }
 
 const moderateBugHuntHighlight = `-- name: ModerateBugHuntHighlight :exec
+WITH target AS (SELECT t.repo_id, t.linter_id, h.fingerprint
+                FROM lint_highlights as h
+                         JOIN lint_tasks as t ON h.lint_id = t.lint_id
+                WHERE h.lint_id = $1
+                  AND h.fingerprint = $2)
 UPDATE lint_highlights
-SET moderation_status = $5
-WHERE lint_id = $1
-  AND path = $2
-  AND start_line = $3
-  AND end_line = $4
+SET moderation_status  = $3,
+    moderation_comment = $4,
+    moderated_at       = $5,
+    moderated_by       = $6
+WHERE (lint_id, fingerprint) IN (SELECT t.lint_id, target.fingerprint
+                                 FROM target
+                                          JOIN lint_tasks as t ON t.repo_id = target.repo_id AND t.linter_id = target.linter_id)
 `
 
 type ModerateBugHuntHighlightParams struct {
-	LintID           string
-	Path             string
-	StartLine        int32
-	EndLine          int32
-	ModerationStatus HighlightStatus
+	LintID            string
+	Fingerprint       string
+	ModerationStatus  HighlightStatus
+	ModerationComment pgtype.Text
+	ModeratedAt       pgtype.Timestamp
+	ModeratedBy       pgtype.Text
 }
 
 func (q *Queries) ModerateBugHuntHighlight(ctx context.Context, arg ModerateBugHuntHighlightParams) error {
 	_, err := q.db.Exec(ctx, moderateBugHuntHighlight,
 		arg.LintID,
-		arg.Path,
-		arg.StartLine,
-		arg.EndLine,
+		arg.Fingerprint,
 		arg.ModerationStatus,
+		arg.ModerationComment,
+		arg.ModeratedAt,
+		arg.ModeratedBy,
 	)
 	return err
 }
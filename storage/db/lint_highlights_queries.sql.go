@@ -5,4 +5,46 @@
 
 package db
 
-import ()
+import (
+	"context"
+)
+
+const deleteLintHighlights = `-- name: DeleteLintHighlights :exec
+DELETE FROM lint_highlights WHERE lint_id = $1
+`
+
+func (q *Queries) DeleteLintHighlights(ctx context.Context, lintID string) error {
+	_, err := q.db.Exec(ctx, deleteLintHighlights, lintID)
+	return err
+}
+
+const inheritLintHighlightsModeration = `-- name: InheritLintHighlightsModeration :execrows
+UPDATE lint_highlights as h
+SET moderation_status  = prev.moderation_status,
+    moderation_comment = prev.moderation_comment,
+    moderated_at       = prev.moderated_at,
+    moderated_by       = prev.moderated_by
+FROM (SELECT DISTINCT ON (ph.fingerprint) ph.fingerprint,
+                                          ph.moderation_status,
+                                          ph.moderation_comment,
+                                          ph.moderated_at,
+                                          ph.moderated_by
+      FROM lint_highlights as ph
+               JOIN lint_tasks as pt ON ph.lint_id = pt.lint_id
+               JOIN lint_tasks as t ON pt.repo_id = t.repo_id AND pt.linter_id = t.linter_id
+      WHERE t.lint_id = $1
+        AND ph.lint_id <> $1
+        AND ph.fingerprint <> ''
+        AND ph.moderation_status <> 'pending'
+      ORDER BY ph.fingerprint, ph.moderated_at DESC NULLS LAST) as prev
+WHERE h.lint_id = $1
+  AND h.fingerprint = prev.fingerprint
+`
+
+func (q *Queries) InheritLintHighlightsModeration(ctx context.Context, lintID string) (int64, error) {
+	result, err := q.db.Exec(ctx, inheritLintHighlightsModeration, lintID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
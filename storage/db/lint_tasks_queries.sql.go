@@ -44,14 +44,96 @@ func (q *Queries) AddLintTask(ctx context.Context, arg AddLintTaskParams) error
 	return err
 }
 
-const setLintTask = `-- name: SetLintTask :exec
+const cancelLinterLintTasks = `-- name: CancelLinterLintTasks :execrows
+WITH cancelled_attempts AS (UPDATE lint_attempts as a
+    SET finished_at = $1,
+        lint_status = 'cancelled',
+        error       = $2
+    FROM lint_tasks as t
+    WHERE a.lint_id = t.lint_id
+      AND t.linter_id = $3
+      AND t.lint_status = 'running'
+      AND a.finished_at IS NULL)
+UPDATE lint_tasks
+SET lint_status         = 'cancelled',
+    lint_status_comment = $2,
+    linted_at           = $1,
+    next_attempt_at     = NULL,
+    locked_at           = NULL,
+    locked_by           = NULL
+WHERE linter_id = $3
+  AND lint_status IN ('pending', 'running')
+`
+
+type CancelLinterLintTasksParams struct {
+	CancelledAt pgtype.Timestamp
+	Comment     pgtype.Text
+	LinterID    string
+}
+
+func (q *Queries) CancelLinterLintTasks(ctx context.Context, arg CancelLinterLintTasksParams) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelLinterLintTasks, arg.CancelledAt, arg.Comment, arg.LinterID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const heartbeatLintTask = `-- name: HeartbeatLintTask :execrows
+UPDATE lint_tasks
+SET locked_at = $3
+WHERE lint_id = $1
+  AND locked_by = $2
+  AND lint_status = 'running'
+`
+
+type HeartbeatLintTaskParams struct {
+	LintID   string
+	LockedBy pgtype.Text
+	LockedAt pgtype.Timestamp
+}
+
+func (q *Queries) HeartbeatLintTask(ctx context.Context, arg HeartbeatLintTaskParams) (int64, error) {
+	result, err := q.db.Exec(ctx, heartbeatLintTask, arg.LintID, arg.LockedBy, arg.LockedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const resumeLinterLintTasks = `-- name: ResumeLinterLintTasks :execrows
+UPDATE lint_tasks
+SET lint_status         = 'pending',
+    lint_status_comment = NULL,
+    lint_attempts       = 0
+WHERE linter_id = $1
+  AND lint_status = 'cancelled'
+`
+
+func (q *Queries) ResumeLinterLintTasks(ctx context.Context, linterID string) (int64, error) {
+	result, err := q.db.Exec(ctx, resumeLinterLintTasks, linterID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setLintTask = `-- name: SetLintTask :execrows
 UPDATE lint_tasks
 SET lint_status         = $2,
     lint_status_comment = $3,
     lint_duration       = $4,
     linted_at           = $5,
-    locked_at           = NULL
+    next_attempt_at     = $7,
+    cpu_seconds         = $8,
+    max_rss_bytes       = $9,
+    io_bytes            = $10,
+    max_pids            = $11,
+    locked_at           = NULL,
+    locked_by           = NULL
 WHERE lint_id = $1
+  AND locked_by = $6
+  AND lint_status = 'running'
 `
 
 type SetLintTaskParams struct {
@@ -60,17 +142,32 @@ type SetLintTaskParams struct {
 	LintStatusComment pgtype.Text
 	LintDuration      pgtype.Interval
 	LintedAt          pgtype.Timestamp
+	LockedBy          pgtype.Text
+	NextAttemptAt     pgtype.Timestamp
+	CpuSeconds        pgtype.Float8
+	MaxRssBytes       pgtype.Int8
+	IoBytes           pgtype.Int8
+	MaxPids           pgtype.Int8
 }
 
-func (q *Queries) SetLintTask(ctx context.Context, arg SetLintTaskParams) error {
-	_, err := q.db.Exec(ctx, setLintTask,
+func (q *Queries) SetLintTask(ctx context.Context, arg SetLintTaskParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setLintTask,
 		arg.LintID,
 		arg.LintStatus,
 		arg.LintStatusComment,
 		arg.LintDuration,
 		arg.LintedAt,
+		arg.LockedBy,
+		arg.NextAttemptAt,
+		arg.CpuSeconds,
+		arg.MaxRssBytes,
+		arg.IoBytes,
+		arg.MaxPids,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const tryTakeLintTask = `-- name: TryTakeLintTask :one
@@ -83,12 +180,15 @@ WITH available_tasks AS (SELECT lint_id,
     repo_git_commit_hash,
     locked_at
     FROM lint_tasks t
-    WHERE lint_status = 'pending'
-    AND (t.locked_at IS NULL OR t.locked_at <= $2)
+    WHERE (t.lint_status = 'pending' AND (t.next_attempt_at IS NULL OR t.next_attempt_at <= $1))
+    OR (t.lint_status = 'running' AND t.locked_at <= $3)
     ORDER BY created_at
     LIMIT 1 FOR UPDATE)
 UPDATE lint_tasks as t
-SET locked_at = $1
+SET lint_status   = 'running',
+    lint_attempts = t.lint_attempts + 1,
+    locked_at     = $1,
+    locked_by     = $2
 FROM available_tasks
 WHERE t.lint_id = available_tasks.lint_id
     RETURNING
@@ -98,11 +198,13 @@ WHERE t.lint_id = available_tasks.lint_id
     t.linter_docker_sha_hash,
     t.repo_id,
     t.repo_git_url,
-    t.repo_git_commit_hash
+    t.repo_git_commit_hash,
+    t.lint_attempts
 `
 
 type TryTakeLintTaskParams struct {
 	LockedAt           pgtype.Timestamp
+	LockedBy           pgtype.Text
 	LockTimeLowerBound pgtype.Timestamp
 }
 
@@ -114,10 +216,11 @@ type TryTakeLintTaskRow struct {
 	RepoID              string
 	RepoGitUrl          string
 	RepoGitCommitHash   string
+	LintAttempts        int32
 }
 
 func (q *Queries) TryTakeLintTask(ctx context.Context, arg TryTakeLintTaskParams) (TryTakeLintTaskRow, error) {
-	row := q.db.QueryRow(ctx, tryTakeLintTask, arg.LockedAt, arg.LockTimeLowerBound)
+	row := q.db.QueryRow(ctx, tryTakeLintTask, arg.LockedAt, arg.LockedBy, arg.LockTimeLowerBound)
 	var i TryTakeLintTaskRow
 	err := row.Scan(
 		&i.LintID,
@@ -127,6 +230,7 @@ func (q *Queries) TryTakeLintTask(ctx context.Context, arg TryTakeLintTaskParams
 		&i.RepoID,
 		&i.RepoGitUrl,
 		&i.RepoGitCommitHash,
+		&i.LintAttempts,
 	)
 	return i, err
 }
@@ -24,18 +24,28 @@ const getLinter = `-- name: GetLinter :one
 SELECT linter_id,
        linter_git_url,
        linter_git_branch,
+       linter_last_git_commit_hash,
        linter_last_docker_image,
-       linter_last_docker_sha_hash
+       linter_last_docker_sha_hash,
+       linter_status,
+       linter_owner,
+       linter_failed_git_commit_hash,
+       linter_build_error
 FROM linters
 WHERE linter_id = $1
 `
 
 type GetLinterRow struct {
-	LinterID                string
-	LinterGitUrl            string
-	LinterGitBranch         string
-	LinterLastDockerImage   pgtype.Text
-	LinterLastDockerShaHash pgtype.Text
+	LinterID                  string
+	LinterGitUrl              string
+	LinterGitBranch           string
+	LinterLastGitCommitHash   pgtype.Text
+	LinterLastDockerImage     pgtype.Text
+	LinterLastDockerShaHash   pgtype.Text
+	LinterStatus              LinterStatus
+	LinterOwner               pgtype.Text
+	LinterFailedGitCommitHash pgtype.Text
+	LinterBuildError          pgtype.Text
 }
 
 func (q *Queries) GetLinter(ctx context.Context, linterID string) (GetLinterRow, error) {
@@ -45,8 +55,13 @@ func (q *Queries) GetLinter(ctx context.Context, linterID string) (GetLinterRow,
 		&i.LinterID,
 		&i.LinterGitUrl,
 		&i.LinterGitBranch,
+		&i.LinterLastGitCommitHash,
 		&i.LinterLastDockerImage,
 		&i.LinterLastDockerShaHash,
+		&i.LinterStatus,
+		&i.LinterOwner,
+		&i.LinterFailedGitCommitHash,
+		&i.LinterBuildError,
 	)
 	return i, err
 }
@@ -55,18 +70,28 @@ const listLinters = `-- name: ListLinters :many
 SELECT linter_id,
        linter_git_url,
        linter_git_branch,
+       linter_last_git_commit_hash,
        linter_last_docker_image,
-       linter_last_docker_sha_hash
+       linter_last_docker_sha_hash,
+       linter_status,
+       linter_owner,
+       linter_failed_git_commit_hash,
+       linter_build_error
 FROM linters
 ORDER BY updated_at DESC
 `
 
 type ListLintersRow struct {
-	LinterID                string
-	LinterGitUrl            string
-	LinterGitBranch         string
-	LinterLastDockerImage   pgtype.Text
-	LinterLastDockerShaHash pgtype.Text
+	LinterID                  string
+	LinterGitUrl              string
+	LinterGitBranch           string
+	LinterLastGitCommitHash   pgtype.Text
+	LinterLastDockerImage     pgtype.Text
+	LinterLastDockerShaHash   pgtype.Text
+	LinterStatus              LinterStatus
+	LinterOwner               pgtype.Text
+	LinterFailedGitCommitHash pgtype.Text
+	LinterBuildError          pgtype.Text
 }
 
 func (q *Queries) ListLinters(ctx context.Context) ([]ListLintersRow, error) {
@@ -82,8 +107,13 @@ func (q *Queries) ListLinters(ctx context.Context) ([]ListLintersRow, error) {
 			&i.LinterID,
 			&i.LinterGitUrl,
 			&i.LinterGitBranch,
+			&i.LinterLastGitCommitHash,
 			&i.LinterLastDockerImage,
 			&i.LinterLastDockerShaHash,
+			&i.LinterStatus,
+			&i.LinterOwner,
+			&i.LinterFailedGitCommitHash,
+			&i.LinterBuildError,
 		); err != nil {
 			return nil, err
 		}
@@ -95,21 +125,130 @@ func (q *Queries) ListLinters(ctx context.Context) ([]ListLintersRow, error) {
 	return items, nil
 }
 
+const setLinterBuildFailure = `-- name: SetLinterBuildFailure :exec
+UPDATE linters
+SET linter_failed_git_commit_hash = $2,
+    linter_build_error            = $3
+WHERE linter_id = $1
+`
+
+type SetLinterBuildFailureParams struct {
+	LinterID                  string
+	LinterFailedGitCommitHash pgtype.Text
+	LinterBuildError          pgtype.Text
+}
+
+func (q *Queries) SetLinterBuildFailure(ctx context.Context, arg SetLinterBuildFailureParams) error {
+	_, err := q.db.Exec(ctx, setLinterBuildFailure, arg.LinterID, arg.LinterFailedGitCommitHash, arg.LinterBuildError)
+	return err
+}
+
+const setLinterStatus = `-- name: SetLinterStatus :exec
+UPDATE linters
+SET linter_status = $2,
+    updated_at    = $3
+WHERE linter_id = $1
+`
+
+type SetLinterStatusParams struct {
+	LinterID     string
+	LinterStatus LinterStatus
+	UpdatedAt    pgtype.Timestamp
+}
+
+func (q *Queries) SetLinterStatus(ctx context.Context, arg SetLinterStatusParams) error {
+	_, err := q.db.Exec(ctx, setLinterStatus, arg.LinterID, arg.LinterStatus, arg.UpdatedAt)
+	return err
+}
+
+const submitLinter = `-- name: SubmitLinter :exec
+INSERT INTO linters (linter_id, linter_git_url, linter_git_branch, linter_last_docker_image, linter_last_docker_sha_hash, linter_status, linter_owner, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, 'pending', $6, $7, $7)
+`
+
+type SubmitLinterParams struct {
+	LinterID                string
+	LinterGitUrl            string
+	LinterGitBranch         string
+	LinterLastDockerImage   pgtype.Text
+	LinterLastDockerShaHash pgtype.Text
+	LinterOwner             pgtype.Text
+	CreatedAt               pgtype.Timestamp
+}
+
+func (q *Queries) SubmitLinter(ctx context.Context, arg SubmitLinterParams) error {
+	_, err := q.db.Exec(ctx, submitLinter,
+		arg.LinterID,
+		arg.LinterGitUrl,
+		arg.LinterGitBranch,
+		arg.LinterLastDockerImage,
+		arg.LinterLastDockerShaHash,
+		arg.LinterOwner,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const updateLinterSource = `-- name: UpdateLinterSource :exec
+UPDATE linters
+SET linter_git_url                = $2,
+    linter_git_branch             = $3,
+    linter_last_git_commit_hash   = NULL,
+    linter_failed_git_commit_hash = NULL,
+    linter_build_error            = NULL,
+    linter_last_docker_image      = COALESCE($4, linter_last_docker_image),
+    linter_last_docker_sha_hash   = COALESCE($5, linter_last_docker_sha_hash),
+    linter_status                 = CASE
+                                        WHEN linter_git_url <> $2
+                                            OR linter_git_branch <> $3
+                                            OR linter_last_docker_image IS DISTINCT FROM COALESCE($4, linter_last_docker_image)
+                                            OR linter_last_docker_sha_hash IS DISTINCT FROM COALESCE($5, linter_last_docker_sha_hash)
+                                            THEN 'pending'::linter_status
+                                        ELSE linter_status END,
+    updated_at                    = $6
+WHERE linter_id = $1
+`
+
+type UpdateLinterSourceParams struct {
+	LinterID                string
+	LinterGitUrl            string
+	LinterGitBranch         string
+	LinterLastDockerImage   pgtype.Text
+	LinterLastDockerShaHash pgtype.Text
+	UpdatedAt               pgtype.Timestamp
+}
+
+func (q *Queries) UpdateLinterSource(ctx context.Context, arg UpdateLinterSourceParams) error {
+	_, err := q.db.Exec(ctx, updateLinterSource,
+		arg.LinterID,
+		arg.LinterGitUrl,
+		arg.LinterGitBranch,
+		arg.LinterLastDockerImage,
+		arg.LinterLastDockerShaHash,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
 const upsertLinter = `-- name: UpsertLinter :exec
-INSERT INTO linters (linter_id, linter_git_url, linter_git_branch, linter_last_docker_image, linter_last_docker_sha_hash, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $6)
+INSERT INTO linters (linter_id, linter_git_url, linter_git_branch, linter_last_git_commit_hash, linter_last_docker_image, linter_last_docker_sha_hash, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
 ON CONFLICT (linter_id)
-    DO UPDATE SET linter_git_url              = $2,
-    linter_git_branch           = $3,
-    linter_last_docker_image    = $4,
-    linter_last_docker_sha_hash = $5,
-    updated_at                  = $6
+    DO UPDATE SET linter_git_url                = $2,
+    linter_git_branch             = $3,
+    linter_last_git_commit_hash   = $4,
+    linter_last_docker_image      = $5,
+    linter_last_docker_sha_hash   = $6,
+    linter_failed_git_commit_hash = NULL,
+    linter_build_error            = NULL,
+    updated_at                    = $7
 `
 
 type UpsertLinterParams struct {
 	LinterID                string
 	LinterGitUrl            string
 	LinterGitBranch         string
+	LinterLastGitCommitHash pgtype.Text
 	LinterLastDockerImage   pgtype.Text
 	LinterLastDockerShaHash pgtype.Text
 	CreatedAt               pgtype.Timestamp
@@ -120,6 +259,7 @@ func (q *Queries) UpsertLinter(ctx context.Context, arg UpsertLinterParams) erro
 		arg.LinterID,
 		arg.LinterGitUrl,
 		arg.LinterGitBranch,
+		arg.LinterLastGitCommitHash,
 		arg.LinterLastDockerImage,
 		arg.LinterLastDockerShaHash,
 		arg.CreatedAt,
@@ -11,10 +11,98 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AccessRole string
+
+const (
+	AccessRoleViewer      AccessRole = "viewer"
+	AccessRoleLinterOwner AccessRole = "linter_owner"
+	AccessRoleModerator   AccessRole = "moderator"
+	AccessRoleAdmin       AccessRole = "admin"
+)
+
+func (e *AccessRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AccessRole(s)
+	case string:
+		*e = AccessRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AccessRole: %T", src)
+	}
+	return nil
+}
+
+type NullAccessRole struct {
+	AccessRole AccessRole
+	Valid      bool // Valid is true if AccessRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAccessRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.AccessRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AccessRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAccessRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AccessRole), nil
+}
+
+type HighlightSeverity string
+
+const (
+	HighlightSeverityNotice  HighlightSeverity = "notice"
+	HighlightSeverityWarning HighlightSeverity = "warning"
+	HighlightSeverityError   HighlightSeverity = "error"
+)
+
+func (e *HighlightSeverity) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = HighlightSeverity(s)
+	case string:
+		*e = HighlightSeverity(s)
+	default:
+		return fmt.Errorf("unsupported scan type for HighlightSeverity: %T", src)
+	}
+	return nil
+}
+
+type NullHighlightSeverity struct {
+	HighlightSeverity HighlightSeverity
+	Valid             bool // Valid is true if HighlightSeverity is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullHighlightSeverity) Scan(value interface{}) error {
+	if value == nil {
+		ns.HighlightSeverity, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.HighlightSeverity.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullHighlightSeverity) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.HighlightSeverity), nil
+}
+
 type HighlightStatus string
 
 const (
 	HighlightStatusPending  HighlightStatus = "pending"
+	HighlightStatusDisputed HighlightStatus = "disputed"
 	HighlightStatusAccepted HighlightStatus = "accepted"
 	HighlightStatusRejected HighlightStatus = "rejected"
 )
@@ -57,11 +145,16 @@ func (ns NullHighlightStatus) Value() (driver.Value, error) {
 type LintStatus string
 
 const (
-	LintStatusPending LintStatus = "pending"
-	LintStatusLocked  LintStatus = "locked"
-	LintStatusSucceed LintStatus = "succeed"
-	LintStatusFailed  LintStatus = "failed"
-	LintStatusSkipped LintStatus = "skipped"
+	LintStatusPending   LintStatus = "pending"
+	LintStatusRunning   LintStatus = "running"
+	LintStatusSucceeded LintStatus = "succeeded"
+	LintStatusFailed    LintStatus = "failed"
+	LintStatusSkipped   LintStatus = "skipped"
+	LintStatusTimedOut  LintStatus = "timed_out"
+	LintStatusCancelled LintStatus = "cancelled"
+	LintStatusOomKilled LintStatus = "oom_killed"
+	LintStatusCrashed   LintStatus = "crashed"
+	LintStatusTooNoisy  LintStatus = "too_noisy"
 )
 
 func (e *LintStatus) Scan(src interface{}) error {
@@ -99,6 +192,128 @@ func (ns NullLintStatus) Value() (driver.Value, error) {
 	return string(ns.LintStatus), nil
 }
 
+type LinterStatus string
+
+const (
+	LinterStatusPending  LinterStatus = "pending"
+	LinterStatusActive   LinterStatus = "active"
+	LinterStatusPaused   LinterStatus = "paused"
+	LinterStatusRejected LinterStatus = "rejected"
+)
+
+func (e *LinterStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = LinterStatus(s)
+	case string:
+		*e = LinterStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for LinterStatus: %T", src)
+	}
+	return nil
+}
+
+type NullLinterStatus struct {
+	LinterStatus LinterStatus
+	Valid        bool // Valid is true if LinterStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullLinterStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.LinterStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.LinterStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullLinterStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.LinterStatus), nil
+}
+
+type TokenScope string
+
+const (
+	TokenScopeReadOnly     TokenScope = "read_only"
+	TokenScopeModerate     TokenScope = "moderate"
+	TokenScopeSubmitLinter TokenScope = "submit_linter"
+)
+
+func (e *TokenScope) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TokenScope(s)
+	case string:
+		*e = TokenScope(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TokenScope: %T", src)
+	}
+	return nil
+}
+
+type NullTokenScope struct {
+	TokenScope TokenScope
+	Valid      bool // Valid is true if TokenScope is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTokenScope) Scan(value interface{}) error {
+	if value == nil {
+		ns.TokenScope, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TokenScope.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTokenScope) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TokenScope), nil
+}
+
+type ApiToken struct {
+	TokenID    int64
+	UserLogin  string
+	TokenName  string
+	TokenHash  string
+	TokenScope TokenScope
+	CreatedAt  pgtype.Timestamp
+	LastUsedAt pgtype.Timestamp
+	RevokedAt  pgtype.Timestamp
+}
+
+type HighlightTrack struct {
+	RepoID              string
+	LinterID            string
+	Fingerprint         string
+	FirstSeenCommitHash string
+	FirstSeenAt         pgtype.Timestamp
+	LastSeenCommitHash  string
+	LastSeenAt          pgtype.Timestamp
+	FixedCommitHash     pgtype.Text
+	FixedAt             pgtype.Timestamp
+}
+
+type LintAttempt struct {
+	AttemptID  int64
+	LintID     string
+	Attempt    int32
+	WorkerID   string
+	StartedAt  pgtype.Timestamp
+	FinishedAt pgtype.Timestamp
+	LintStatus NullLintStatus
+	ExitCode   pgtype.Int4
+	Error      pgtype.Text
+}
+
 type LintHighlight struct {
 	LintID            string
 	Path              string
@@ -111,6 +326,22 @@ type LintHighlight struct {
 	ModerationStatus  HighlightStatus
 	ModerationComment pgtype.Text
 	ModeratedAt       pgtype.Timestamp
+	StartColumn       int32
+	EndColumn         int32
+	Severity          HighlightSeverity
+	RuleID            string
+	Fingerprint       string
+	ModeratedBy       pgtype.Text
+}
+
+type LintLog struct {
+	LintID      string
+	Attempt     int32
+	Stdout      []byte
+	Stderr      []byte
+	StdoutBytes int64
+	StderrBytes int64
+	CreatedAt   pgtype.Timestamp
 }
 
 type LintTask struct {
@@ -127,16 +358,54 @@ type LintTask struct {
 	CreatedAt           pgtype.Timestamp
 	LockedAt            pgtype.Timestamp
 	LintedAt            pgtype.Timestamp
+	LockedBy            pgtype.Text
+	LintAttempts        int32
+	NextAttemptAt       pgtype.Timestamp
+	CpuSeconds          pgtype.Float8
+	MaxRssBytes         pgtype.Int8
+	IoBytes             pgtype.Int8
+	MaxPids             pgtype.Int8
 }
 
 type Linter struct {
-	LinterID                string
-	LinterGitUrl            string
-	LinterGitBranch         string
-	LinterLastDockerImage   pgtype.Text
-	LinterLastDockerShaHash pgtype.Text
-	CreatedAt               pgtype.Timestamp
-	UpdatedAt               pgtype.Timestamp
+	LinterID                  string
+	LinterGitUrl              string
+	LinterGitBranch           string
+	LinterLastDockerImage     pgtype.Text
+	LinterLastDockerShaHash   pgtype.Text
+	CreatedAt                 pgtype.Timestamp
+	UpdatedAt                 pgtype.Timestamp
+	LinterLastGitCommitHash   pgtype.Text
+	LinterStatus              LinterStatus
+	LinterOwner               pgtype.Text
+	LinterFailedGitCommitHash pgtype.Text
+	LinterBuildError          pgtype.Text
+}
+
+type ModerationEvent struct {
+	EventID          int64
+	LintID           string
+	Path             string
+	StartLine        int32
+	EndLine          int32
+	Fingerprint      string
+	PreviousStatus   HighlightStatus
+	ModerationStatus HighlightStatus
+	Vote             NullHighlightStatus
+	ModeratorLogin   string
+	Comment          string
+	RevertedEventID  pgtype.Int8
+	CreatedAt        pgtype.Timestamp
+}
+
+type ModerationVote struct {
+	RepoID         string
+	LinterID       string
+	HighlightKey   string
+	ModeratorLogin string
+	Vote           HighlightStatus
+	Comment        string
+	VotedAt        pgtype.Timestamp
 }
 
 type Repo struct {
@@ -147,3 +416,24 @@ type Repo struct {
 	CreatedAt             pgtype.Timestamp
 	UpdatedAt             pgtype.Timestamp
 }
+
+type RevokedSession struct {
+	SessionID string
+	UserLogin string
+	RevokedAt pgtype.Timestamp
+	ExpiresAt pgtype.Timestamp
+}
+
+type User struct {
+	UserLogin   string
+	CreatedAt   pgtype.Timestamp
+	LastLoginAt pgtype.Timestamp
+	UserName    string
+}
+
+type UserRole struct {
+	UserLogin string
+	Role      AccessRole
+	GrantedBy string
+	GrantedAt pgtype.Timestamp
+}
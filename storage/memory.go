@@ -0,0 +1,1485 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/sivukhin/gobughunt/storage/db"
+)
+
+// Memory is the in-memory Storage with the same semantics as Postgres queries - it's used in tests which can't run database.
+// Transactions are serialized: InTx holds the lock and works with the copy of the tables which replaces them only on success
+type Memory struct {
+	lock   *sync.Mutex
+	tables *memoryTables
+	inTx   bool
+}
+
+type memoryTables struct {
+	repos            []db.Repo
+	linters          []db.Linter
+	lintTasks        []db.LintTask
+	lintAttempts     []db.LintAttempt
+	lintLogs         []db.LintLog
+	lintHighlights   []db.LintHighlight
+	highlightTracks  []db.HighlightTrack
+	moderationEvents []db.ModerationEvent
+	moderationVotes  []db.ModerationVote
+	users            []db.User
+	userRoles        []db.UserRole
+	apiTokens        []db.ApiToken
+	revokedSessions  []db.RevokedSession
+}
+
+func (t *memoryTables) clone() *memoryTables {
+	return &memoryTables{
+		repos:            slices.Clone(t.repos),
+		linters:          slices.Clone(t.linters),
+		lintTasks:        slices.Clone(t.lintTasks),
+		lintAttempts:     slices.Clone(t.lintAttempts),
+		lintLogs:         slices.Clone(t.lintLogs),
+		lintHighlights:   slices.Clone(t.lintHighlights),
+		highlightTracks:  slices.Clone(t.highlightTracks),
+		moderationEvents: slices.Clone(t.moderationEvents),
+		moderationVotes:  slices.Clone(t.moderationVotes),
+		users:            slices.Clone(t.users),
+		userRoles:        slices.Clone(t.userRoles),
+		apiTokens:        slices.Clone(t.apiTokens),
+		revokedSessions:  slices.Clone(t.revokedSessions),
+	}
+}
+
+var (
+	_ Storage = (*Memory)(nil)
+	_ Storage = Postgres{}
+)
+
+func NewMemory() *Memory {
+	return &Memory{lock: &sync.Mutex{}, tables: &memoryTables{}}
+}
+
+func (m *Memory) acquire() func() {
+	if m.inTx {
+		return func() {}
+	}
+	m.lock.Lock()
+	return m.lock.Unlock
+}
+
+func (m *Memory) InTx(ctx context.Context, run func(queries Queries) error) error {
+	defer m.acquire()()
+	tx := &Memory{lock: m.lock, tables: m.tables.clone(), inTx: true}
+	err := run(tx)
+	if err != nil {
+		return err
+	}
+	m.tables = tx.tables
+	return nil
+}
+
+// enum values are ordered as in their declaration - as Postgres does in ORDER BY and MAX
+var (
+	highlightStatusOrder = []db.HighlightStatus{db.HighlightStatusPending, db.HighlightStatusDisputed, db.HighlightStatusAccepted, db.HighlightStatusRejected}
+	accessRoleOrder      = []db.AccessRole{db.AccessRoleViewer, db.AccessRoleLinterOwner, db.AccessRoleModerator, db.AccessRoleAdmin}
+)
+
+func uniqueViolation(constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23505",
+		Message:        fmt.Sprintf("duplicate key value violates unique constraint \"%v\"", constraint),
+		ConstraintName: constraint,
+	}
+}
+
+// textArg mimics comparison of the untyped sqlc parameter with the text
+func textArg(value interface{}) string {
+	text, _ := value.(string)
+	return text
+}
+
+func sameText(a, b pgtype.Text) bool { return a.Valid && b.Valid && a.String == b.String }
+
+func coalesceText(value, fallback pgtype.Text) pgtype.Text {
+	if value.Valid {
+		return value
+	}
+	return fallback
+}
+
+// compareChain returns the first non-zero comparison result - ORDER BY of several columns
+func compareChain(results ...int) int {
+	for _, result := range results {
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+func highlightKey(h db.LintHighlight) string {
+	if h.Fingerprint != "" {
+		return h.Fingerprint
+	}
+	return fmt.Sprintf("%v:%v:%v", h.Path, h.StartLine, h.EndLine)
+}
+
+func isHighlight(h db.LintHighlight, lintId, fingerprint string) bool {
+	return h.LintID == lintId && h.Fingerprint == fingerprint
+}
+
+func (t *memoryTables) lintTask(lintId string) (db.LintTask, bool) {
+	for _, task := range t.lintTasks {
+		if task.LintID == lintId {
+			return task, true
+		}
+	}
+	return db.LintTask{}, false
+}
+
+func (t *memoryTables) linter(linterId string) (db.Linter, bool) {
+	for _, linter := range t.linters {
+		if linter.LinterID == linterId {
+			return linter, true
+		}
+	}
+	return db.Linter{}, false
+}
+
+func (t *memoryTables) repo(repoId string) (db.Repo, bool) {
+	for _, repo := range t.repos {
+		if repo.RepoID == repoId {
+			return repo, true
+		}
+	}
+	return db.Repo{}, false
+}
+
+func (t *memoryTables) highlightTrack(repoId, linterId, fingerprint string) (db.HighlightTrack, bool) {
+	for _, track := range t.highlightTracks {
+		if track.RepoID == repoId && track.LinterID == linterId && track.Fingerprint == fingerprint {
+			return track, true
+		}
+	}
+	return db.HighlightTrack{}, false
+}
+
+func (m *Memory) ListRepos(ctx context.Context) ([]db.ListReposRow, error) {
+	defer m.acquire()()
+	repos := slices.Clone(m.tables.repos)
+	slices.SortStableFunc(repos, func(a, b db.Repo) int { return b.UpdatedAt.Time.Compare(a.UpdatedAt.Time) })
+	var items []db.ListReposRow
+	for _, repo := range repos {
+		items = append(items, db.ListReposRow{
+			RepoID:                repo.RepoID,
+			RepoGitUrl:            repo.RepoGitUrl,
+			RepoGitBranch:         repo.RepoGitBranch,
+			RepoLastGitCommitHash: repo.RepoLastGitCommitHash,
+		})
+	}
+	return items, nil
+}
+
+func (m *Memory) UpsertRepo(ctx context.Context, arg db.UpsertRepoParams) error {
+	defer m.acquire()()
+	for i, repo := range m.tables.repos {
+		if repo.RepoID == arg.RepoID {
+			m.tables.repos[i].RepoGitUrl = arg.RepoGitUrl
+			m.tables.repos[i].RepoGitBranch = arg.RepoGitBranch
+			m.tables.repos[i].RepoLastGitCommitHash = arg.RepoLastGitCommitHash
+			m.tables.repos[i].UpdatedAt = arg.CreatedAt
+			return nil
+		}
+	}
+	m.tables.repos = append(m.tables.repos, db.Repo{
+		RepoID:                arg.RepoID,
+		RepoGitUrl:            arg.RepoGitUrl,
+		RepoGitBranch:         arg.RepoGitBranch,
+		RepoLastGitCommitHash: arg.RepoLastGitCommitHash,
+		CreatedAt:             arg.CreatedAt,
+		UpdatedAt:             arg.CreatedAt,
+	})
+	return nil
+}
+
+func (m *Memory) GetLinter(ctx context.Context, linterID string) (db.GetLinterRow, error) {
+	defer m.acquire()()
+	linter, ok := m.tables.linter(linterID)
+	if !ok {
+		return db.GetLinterRow{}, pgx.ErrNoRows
+	}
+	return db.GetLinterRow(listLintersRow(linter)), nil
+}
+
+func listLintersRow(linter db.Linter) db.ListLintersRow {
+	return db.ListLintersRow{
+		LinterID:                  linter.LinterID,
+		LinterGitUrl:              linter.LinterGitUrl,
+		LinterGitBranch:           linter.LinterGitBranch,
+		LinterLastGitCommitHash:   linter.LinterLastGitCommitHash,
+		LinterLastDockerImage:     linter.LinterLastDockerImage,
+		LinterLastDockerShaHash:   linter.LinterLastDockerShaHash,
+		LinterStatus:              linter.LinterStatus,
+		LinterOwner:               linter.LinterOwner,
+		LinterFailedGitCommitHash: linter.LinterFailedGitCommitHash,
+		LinterBuildError:          linter.LinterBuildError,
+	}
+}
+
+func (m *Memory) ListLinters(ctx context.Context) ([]db.ListLintersRow, error) {
+	defer m.acquire()()
+	linters := slices.Clone(m.tables.linters)
+	slices.SortStableFunc(linters, func(a, b db.Linter) int { return b.UpdatedAt.Time.Compare(a.UpdatedAt.Time) })
+	var items []db.ListLintersRow
+	for _, linter := range linters {
+		items = append(items, listLintersRow(linter))
+	}
+	return items, nil
+}
+
+func (m *Memory) UpsertLinter(ctx context.Context, arg db.UpsertLinterParams) error {
+	defer m.acquire()()
+	for i, linter := range m.tables.linters {
+		if linter.LinterID == arg.LinterID {
+			m.tables.linters[i].LinterGitUrl = arg.LinterGitUrl
+			m.tables.linters[i].LinterGitBranch = arg.LinterGitBranch
+			m.tables.linters[i].LinterLastGitCommitHash = arg.LinterLastGitCommitHash
+			m.tables.linters[i].LinterLastDockerImage = arg.LinterLastDockerImage
+			m.tables.linters[i].LinterLastDockerShaHash = arg.LinterLastDockerShaHash
+			m.tables.linters[i].LinterFailedGitCommitHash = pgtype.Text{}
+			m.tables.linters[i].LinterBuildError = pgtype.Text{}
+			m.tables.linters[i].UpdatedAt = arg.CreatedAt
+			return nil
+		}
+	}
+	m.tables.linters = append(m.tables.linters, db.Linter{
+		LinterID:                arg.LinterID,
+		LinterGitUrl:            arg.LinterGitUrl,
+		LinterGitBranch:         arg.LinterGitBranch,
+		LinterLastGitCommitHash: arg.LinterLastGitCommitHash,
+		LinterLastDockerImage:   arg.LinterLastDockerImage,
+		LinterLastDockerShaHash: arg.LinterLastDockerShaHash,
+		LinterStatus:            db.LinterStatusActive,
+		CreatedAt:               arg.CreatedAt,
+		UpdatedAt:               arg.CreatedAt,
+	})
+	return nil
+}
+
+func (m *Memory) SubmitLinter(ctx context.Context, arg db.SubmitLinterParams) error {
+	defer m.acquire()()
+	if _, ok := m.tables.linter(arg.LinterID); ok {
+		return uniqueViolation("linters_linter_id_key")
+	}
+	m.tables.linters = append(m.tables.linters, db.Linter{
+		LinterID:                arg.LinterID,
+		LinterGitUrl:            arg.LinterGitUrl,
+		LinterGitBranch:         arg.LinterGitBranch,
+		LinterLastDockerImage:   arg.LinterLastDockerImage,
+		LinterLastDockerShaHash: arg.LinterLastDockerShaHash,
+		LinterStatus:            db.LinterStatusPending,
+		LinterOwner:             arg.LinterOwner,
+		CreatedAt:               arg.CreatedAt,
+		UpdatedAt:               arg.CreatedAt,
+	})
+	return nil
+}
+
+func (m *Memory) SetLinterStatus(ctx context.Context, arg db.SetLinterStatusParams) error {
+	defer m.acquire()()
+	for i, linter := range m.tables.linters {
+		if linter.LinterID == arg.LinterID {
+			m.tables.linters[i].LinterStatus = arg.LinterStatus
+			m.tables.linters[i].UpdatedAt = arg.UpdatedAt
+		}
+	}
+	return nil
+}
+
+func (m *Memory) SetLinterBuildFailure(ctx context.Context, arg db.SetLinterBuildFailureParams) error {
+	defer m.acquire()()
+	for i, linter := range m.tables.linters {
+		if linter.LinterID == arg.LinterID {
+			m.tables.linters[i].LinterFailedGitCommitHash = arg.LinterFailedGitCommitHash
+			m.tables.linters[i].LinterBuildError = arg.LinterBuildError
+		}
+	}
+	return nil
+}
+
+func (m *Memory) UpdateLinterSource(ctx context.Context, arg db.UpdateLinterSourceParams) error {
+	defer m.acquire()()
+	for i, linter := range m.tables.linters {
+		if linter.LinterID == arg.LinterID {
+			updated := &m.tables.linters[i]
+			updated.LinterGitUrl = arg.LinterGitUrl
+			updated.LinterGitBranch = arg.LinterGitBranch
+			updated.LinterLastGitCommitHash = pgtype.Text{}
+			updated.LinterFailedGitCommitHash = pgtype.Text{}
+			updated.LinterBuildError = pgtype.Text{}
+			updated.LinterLastDockerImage = coalesceText(arg.LinterLastDockerImage, linter.LinterLastDockerImage)
+			updated.LinterLastDockerShaHash = coalesceText(arg.LinterLastDockerShaHash, linter.LinterLastDockerShaHash)
+			updated.UpdatedAt = arg.UpdatedAt
+			if updated.LinterGitUrl != linter.LinterGitUrl || updated.LinterGitBranch != linter.LinterGitBranch ||
+				updated.LinterLastDockerImage != linter.LinterLastDockerImage || updated.LinterLastDockerShaHash != linter.LinterLastDockerShaHash {
+				updated.LinterStatus = db.LinterStatusPending
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Memory) AddLintTask(ctx context.Context, arg db.AddLintTaskParams) error {
+	defer m.acquire()()
+	for _, task := range m.tables.lintTasks {
+		if task.LintID == arg.LintID {
+			return uniqueViolation("lint_tasks_lint_id_key")
+		}
+		if task.LinterDockerImage == arg.LinterDockerImage &&
+			task.LinterDockerShaHash == arg.LinterDockerShaHash &&
+			task.RepoGitUrl == arg.RepoGitUrl &&
+			task.RepoGitCommitHash == arg.RepoGitCommitHash {
+			return uniqueViolation("hash_unique")
+		}
+	}
+	m.tables.lintTasks = append(m.tables.lintTasks, db.LintTask{
+		LintID:              arg.LintID,
+		LinterID:            arg.LinterID,
+		LinterDockerImage:   arg.LinterDockerImage,
+		LinterDockerShaHash: arg.LinterDockerShaHash,
+		RepoID:              arg.RepoID,
+		RepoGitUrl:          arg.RepoGitUrl,
+		RepoGitCommitHash:   arg.RepoGitCommitHash,
+		LintStatus:          arg.LintStatus,
+		CreatedAt:           arg.CreatedAt,
+	})
+	return nil
+}
+
+func (m *Memory) TryTakeLintTask(ctx context.Context, arg db.TryTakeLintTaskParams) (db.TryTakeLintTaskRow, error) {
+	defer m.acquire()()
+	taken := -1
+	for i, task := range m.tables.lintTasks {
+		retry := task.LintStatus == db.LintStatusPending && (!task.NextAttemptAt.Valid || !task.NextAttemptAt.Time.After(arg.LockedAt.Time))
+		expired := task.LintStatus == db.LintStatusRunning && !task.LockedAt.Time.After(arg.LockTimeLowerBound.Time)
+		if !retry && !expired {
+			continue
+		}
+		if taken == -1 || task.CreatedAt.Time.Before(m.tables.lintTasks[taken].CreatedAt.Time) {
+			taken = i
+		}
+	}
+	if taken == -1 {
+		return db.TryTakeLintTaskRow{}, pgx.ErrNoRows
+	}
+	task := &m.tables.lintTasks[taken]
+	task.LintStatus = db.LintStatusRunning
+	task.LintAttempts++
+	task.LockedAt = arg.LockedAt
+	task.LockedBy = arg.LockedBy
+	return db.TryTakeLintTaskRow{
+		LintID:              task.LintID,
+		LinterID:            task.LinterID,
+		LinterDockerImage:   task.LinterDockerImage,
+		LinterDockerShaHash: task.LinterDockerShaHash,
+		RepoID:              task.RepoID,
+		RepoGitUrl:          task.RepoGitUrl,
+		RepoGitCommitHash:   task.RepoGitCommitHash,
+		LintAttempts:        task.LintAttempts,
+	}, nil
+}
+
+func (m *Memory) HeartbeatLintTask(ctx context.Context, arg db.HeartbeatLintTaskParams) (int64, error) {
+	defer m.acquire()()
+	var updated int64
+	for i, task := range m.tables.lintTasks {
+		if task.LintID == arg.LintID && sameText(task.LockedBy, arg.LockedBy) && task.LintStatus == db.LintStatusRunning {
+			m.tables.lintTasks[i].LockedAt = arg.LockedAt
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (m *Memory) SetLintTask(ctx context.Context, arg db.SetLintTaskParams) (int64, error) {
+	defer m.acquire()()
+	var updated int64
+	for i, task := range m.tables.lintTasks {
+		if task.LintID == arg.LintID && sameText(task.LockedBy, arg.LockedBy) && task.LintStatus == db.LintStatusRunning {
+			task.LintStatus = arg.LintStatus
+			task.LintStatusComment = arg.LintStatusComment
+			task.LintDuration = arg.LintDuration
+			task.LintedAt = arg.LintedAt
+			task.NextAttemptAt = arg.NextAttemptAt
+			task.CpuSeconds = arg.CpuSeconds
+			task.MaxRssBytes = arg.MaxRssBytes
+			task.IoBytes = arg.IoBytes
+			task.MaxPids = arg.MaxPids
+			task.LockedAt = pgtype.Timestamp{}
+			task.LockedBy = pgtype.Text{}
+			m.tables.lintTasks[i] = task
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (m *Memory) CancelLinterLintTasks(ctx context.Context, arg db.CancelLinterLintTasksParams) (int64, error) {
+	defer m.acquire()()
+	var updated int64
+	for i, task := range m.tables.lintTasks {
+		if task.LinterID != arg.LinterID || (task.LintStatus != db.LintStatusPending && task.LintStatus != db.LintStatusRunning) {
+			continue
+		}
+		if task.LintStatus == db.LintStatusRunning {
+			for j, attempt := range m.tables.lintAttempts {
+				if attempt.LintID == task.LintID && !attempt.FinishedAt.Valid {
+					attempt.FinishedAt = arg.CancelledAt
+					attempt.LintStatus = db.NullLintStatus{LintStatus: db.LintStatusCancelled, Valid: true}
+					attempt.Error = arg.Comment
+					m.tables.lintAttempts[j] = attempt
+				}
+			}
+		}
+		task.LintStatus = db.LintStatusCancelled
+		task.LintStatusComment = arg.Comment
+		task.LintedAt = arg.CancelledAt
+		task.NextAttemptAt = pgtype.Timestamp{}
+		task.LockedAt = pgtype.Timestamp{}
+		task.LockedBy = pgtype.Text{}
+		m.tables.lintTasks[i] = task
+		updated++
+	}
+	return updated, nil
+}
+
+func (m *Memory) ResumeLinterLintTasks(ctx context.Context, linterID string) (int64, error) {
+	defer m.acquire()()
+	var updated int64
+	for i, task := range m.tables.lintTasks {
+		if task.LinterID == linterID && task.LintStatus == db.LintStatusCancelled {
+			task.LintStatus = db.LintStatusPending
+			task.LintStatusComment = pgtype.Text{}
+			task.LintAttempts = 0
+			m.tables.lintTasks[i] = task
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (m *Memory) AddLintAttempt(ctx context.Context, arg db.AddLintAttemptParams) (int64, error) {
+	defer m.acquire()()
+	attemptId := int64(len(m.tables.lintAttempts) + 1) // attempts are never deleted
+	m.tables.lintAttempts = append(m.tables.lintAttempts, db.LintAttempt{
+		AttemptID: attemptId,
+		LintID:    arg.LintID,
+		Attempt:   arg.Attempt,
+		WorkerID:  arg.WorkerID,
+		StartedAt: arg.StartedAt,
+	})
+	return attemptId, nil
+}
+
+func (m *Memory) FinishLintAttempt(ctx context.Context, arg db.FinishLintAttemptParams) (int64, error) {
+	defer m.acquire()()
+	var updated int64
+	for i, attempt := range m.tables.lintAttempts {
+		if attempt.AttemptID == arg.AttemptID && !attempt.FinishedAt.Valid {
+			attempt.FinishedAt = arg.FinishedAt
+			attempt.LintStatus = arg.LintStatus
+			attempt.ExitCode = arg.ExitCode
+			attempt.Error = arg.Error
+			m.tables.lintAttempts[i] = attempt
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (m *Memory) AbandonLintAttempts(ctx context.Context, arg db.AbandonLintAttemptsParams) (int64, error) {
+	defer m.acquire()()
+	var updated int64
+	for i, attempt := range m.tables.lintAttempts {
+		if attempt.LintID == arg.LintID && !attempt.FinishedAt.Valid {
+			attempt.FinishedAt = arg.FinishedAt
+			attempt.LintStatus = db.NullLintStatus{LintStatus: db.LintStatusCancelled, Valid: true}
+			attempt.Error = arg.Error
+			m.tables.lintAttempts[i] = attempt
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (m *Memory) ListLintAttempts(ctx context.Context, lintIds []string) ([]db.LintAttempt, error) {
+	defer m.acquire()()
+	var items []db.LintAttempt
+	for _, attempt := range m.tables.lintAttempts {
+		if slices.Contains(lintIds, attempt.LintID) {
+			items = append(items, attempt)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b db.LintAttempt) int {
+		return compareChain(cmp.Compare(a.LintID, b.LintID), cmp.Compare(a.AttemptID, b.AttemptID))
+	})
+	return items, nil
+}
+
+func (m *Memory) SetLintLogs(ctx context.Context, arg db.SetLintLogsParams) error {
+	defer m.acquire()()
+	logs := db.LintLog(arg)
+	for i, existing := range m.tables.lintLogs {
+		if existing.LintID == arg.LintID {
+			m.tables.lintLogs[i] = logs
+			return nil
+		}
+	}
+	m.tables.lintLogs = append(m.tables.lintLogs, logs)
+	return nil
+}
+
+func (m *Memory) GetLintLogs(ctx context.Context, lintID string) (db.GetLintLogsRow, error) {
+	defer m.acquire()()
+	task, ok := m.tables.lintTask(lintID)
+	if !ok {
+		return db.GetLintLogsRow{}, pgx.ErrNoRows
+	}
+	for _, logs := range m.tables.lintLogs {
+		if logs.LintID == lintID {
+			linter, _ := m.tables.linter(task.LinterID)
+			return db.GetLintLogsRow{
+				LintID:      logs.LintID,
+				Attempt:     logs.Attempt,
+				Stdout:      logs.Stdout,
+				Stderr:      logs.Stderr,
+				StdoutBytes: logs.StdoutBytes,
+				StderrBytes: logs.StderrBytes,
+				CreatedAt:   logs.CreatedAt,
+				LinterOwner: linter.LinterOwner,
+			}, nil
+		}
+	}
+	return db.GetLintLogsRow{}, pgx.ErrNoRows
+}
+
+func (m *Memory) AddLintHighlights(ctx context.Context, arg []db.AddLintHighlightParams) error {
+	defer m.acquire()()
+	for _, highlight := range arg {
+		if slices.ContainsFunc(m.tables.lintHighlights, func(h db.LintHighlight) bool { return isHighlight(h, highlight.LintID, highlight.Fingerprint) }) {
+			return uniqueViolation("lint_highlights_lint_id_fingerprint")
+		}
+		m.tables.lintHighlights = append(m.tables.lintHighlights, db.LintHighlight{
+			LintID:           highlight.LintID,
+			Path:             highlight.Path,
+			StartLine:        highlight.StartLine,
+			EndLine:          highlight.EndLine,
+			StartColumn:      highlight.StartColumn,
+			EndColumn:        highlight.EndColumn,
+			Severity:         highlight.Severity,
+			RuleID:           highlight.RuleID,
+			Explanation:      highlight.Explanation,
+			SnippetStartLine: highlight.SnippetStartLine,
+			SnippetEndLine:   highlight.SnippetEndLine,
+			SnippetCode:      highlight.SnippetCode,
+			Fingerprint:      highlight.Fingerprint,
+			ModerationStatus: db.HighlightStatusPending,
+		})
+	}
+	return nil
+}
+
+func (m *Memory) DeleteLintHighlights(ctx context.Context, lintID string) error {
+	defer m.acquire()()
+	m.tables.lintHighlights = slices.DeleteFunc(m.tables.lintHighlights, func(h db.LintHighlight) bool { return h.LintID == lintID })
+	return nil
+}
+
+func (m *Memory) InheritLintHighlightsModeration(ctx context.Context, lintID string) (int64, error) {
+	defer m.acquire()()
+	task, ok := m.tables.lintTask(lintID)
+	if !ok {
+		return 0, nil
+	}
+	// the latest moderated highlight with the same fingerprint among other tasks of the same repo and linter
+	previous := make(map[string]db.LintHighlight)
+	for _, h := range m.tables.lintHighlights {
+		if h.LintID == lintID || h.Fingerprint == "" || h.ModerationStatus == db.HighlightStatusPending {
+			continue
+		}
+		prevTask, ok := m.tables.lintTask(h.LintID)
+		if !ok || prevTask.RepoID != task.RepoID || prevTask.LinterID != task.LinterID {
+			continue
+		}
+		current, ok := previous[h.Fingerprint]
+		if !ok || !current.ModeratedAt.Valid || h.ModeratedAt.Valid && h.ModeratedAt.Time.After(current.ModeratedAt.Time) {
+			previous[h.Fingerprint] = h
+		}
+	}
+	var updated int64
+	for i, h := range m.tables.lintHighlights {
+		prev, ok := previous[h.Fingerprint]
+		if h.LintID != lintID || !ok {
+			continue
+		}
+		h.ModerationStatus = prev.ModerationStatus
+		h.ModerationComment = prev.ModerationComment
+		h.ModeratedAt = prev.ModeratedAt
+		h.ModeratedBy = prev.ModeratedBy
+		m.tables.lintHighlights[i] = h
+		updated++
+	}
+	return updated, nil
+}
+
+func (m *Memory) TrackLintHighlights(ctx context.Context, lintID string) (int64, error) {
+	defer m.acquire()()
+	task, ok := m.tables.lintTask(lintID)
+	if !ok {
+		return 0, nil
+	}
+	var tracked int64
+	seen := make(map[string]struct{})
+	for _, h := range m.tables.lintHighlights {
+		if h.LintID != lintID || h.Fingerprint == "" {
+			continue
+		}
+		if _, ok := seen[h.Fingerprint]; ok {
+			continue
+		}
+		seen[h.Fingerprint] = struct{}{}
+		tracked++
+		i := slices.IndexFunc(m.tables.highlightTracks, func(tr db.HighlightTrack) bool {
+			return tr.RepoID == task.RepoID && tr.LinterID == task.LinterID && tr.Fingerprint == h.Fingerprint
+		})
+		if i == -1 {
+			m.tables.highlightTracks = append(m.tables.highlightTracks, db.HighlightTrack{
+				RepoID:              task.RepoID,
+				LinterID:            task.LinterID,
+				Fingerprint:         h.Fingerprint,
+				FirstSeenCommitHash: task.RepoGitCommitHash,
+				FirstSeenAt:         task.CreatedAt,
+				LastSeenCommitHash:  task.RepoGitCommitHash,
+				LastSeenAt:          task.CreatedAt,
+			})
+			continue
+		}
+		track := &m.tables.highlightTracks[i]
+		if task.CreatedAt.Time.Before(track.FirstSeenAt.Time) {
+			track.FirstSeenCommitHash, track.FirstSeenAt = task.RepoGitCommitHash, task.CreatedAt
+		}
+		if !task.CreatedAt.Time.Before(track.LastSeenAt.Time) {
+			track.LastSeenCommitHash, track.LastSeenAt = task.RepoGitCommitHash, task.CreatedAt
+		}
+		if track.FixedAt.Valid && !task.CreatedAt.Time.Before(track.FixedAt.Time) {
+			track.FixedCommitHash, track.FixedAt = pgtype.Text{}, pgtype.Timestamp{}
+		}
+	}
+	return tracked, nil
+}
+
+func (m *Memory) FixLintHighlights(ctx context.Context, lintID string) (int64, error) {
+	defer m.acquire()()
+	task, ok := m.tables.lintTask(lintID)
+	if !ok {
+		return 0, nil
+	}
+	var fixed int64
+	for i, track := range m.tables.highlightTracks {
+		if track.RepoID != task.RepoID || track.LinterID != task.LinterID {
+			continue
+		}
+		if !track.LastSeenAt.Time.Before(task.CreatedAt.Time) || track.LastSeenCommitHash == task.RepoGitCommitHash {
+			continue
+		}
+		if track.FixedAt.Valid && !track.FixedAt.Time.After(task.CreatedAt.Time) {
+			continue
+		}
+		present := slices.ContainsFunc(m.tables.lintHighlights, func(h db.LintHighlight) bool {
+			return h.LintID == lintID && h.Fingerprint == track.Fingerprint
+		})
+		if present {
+			continue
+		}
+		// only highlights reported by the same linter image are fixed: new image can just stop reporting some rules
+		seenBySameImage := slices.ContainsFunc(m.tables.lintHighlights, func(h db.LintHighlight) bool {
+			seen, ok := m.tables.lintTask(h.LintID)
+			return ok && h.Fingerprint == track.Fingerprint &&
+				seen.RepoID == task.RepoID && seen.LinterID == task.LinterID && seen.LinterDockerShaHash == task.LinterDockerShaHash
+		})
+		if !seenBySameImage {
+			continue
+		}
+		m.tables.highlightTracks[i].FixedCommitHash = pgtype.Text{String: task.RepoGitCommitHash, Valid: true}
+		m.tables.highlightTracks[i].FixedAt = task.CreatedAt
+		fixed++
+	}
+	return fixed, nil
+}
+
+// bugHuntHighlight is the highlight deduplicated across tasks of the same repo and linter - it has the strongest status among duplicates
+type bugHuntHighlight struct {
+	repoId, linterId, ruleId, key string
+	status                        db.HighlightStatus
+}
+
+type bugHuntStats struct {
+	total, pending, rejected, accepted, fixed map[string]struct{}
+}
+
+func newBugHuntStats() *bugHuntStats {
+	return &bugHuntStats{
+		total:    make(map[string]struct{}),
+		pending:  make(map[string]struct{}),
+		rejected: make(map[string]struct{}),
+		accepted: make(map[string]struct{}),
+		fixed:    make(map[string]struct{}),
+	}
+}
+
+func (s *bugHuntStats) add(t *memoryTables, h bugHuntHighlight) {
+	key := h.repoId + "\x00" + h.key
+	s.total[key] = struct{}{}
+	switch h.status {
+	case db.HighlightStatusPending:
+		s.pending[key] = struct{}{}
+	case db.HighlightStatusRejected:
+		s.rejected[key] = struct{}{}
+	case db.HighlightStatusAccepted:
+		s.accepted[key] = struct{}{}
+		if track, ok := t.highlightTrack(h.repoId, h.linterId, h.key); ok && track.FixedAt.Valid {
+			s.fixed[key] = struct{}{}
+		}
+	}
+}
+
+// bugHuntHighlights groups highlights by the given key, alive filters tasks whose highlights are taken into account
+func (t *memoryTables) bugHuntHighlights(byRule bool, alive func(task db.LintTask) bool) []bugHuntHighlight {
+	groups := make(map[bugHuntHighlight]db.HighlightStatus)
+	var order []bugHuntHighlight
+	for _, h := range t.lintHighlights {
+		task, ok := t.lintTask(h.LintID)
+		if !ok || !alive(task) {
+			continue
+		}
+		group := bugHuntHighlight{repoId: task.RepoID, linterId: task.LinterID, key: highlightKey(h)}
+		if byRule {
+			group.ruleId = h.RuleID
+		}
+		status, ok := groups[group]
+		if !ok {
+			order = append(order, group)
+		}
+		if !ok || slices.Index(highlightStatusOrder, h.ModerationStatus) > slices.Index(highlightStatusOrder, status) {
+			groups[group] = h.ModerationStatus
+		}
+	}
+	highlights := make([]bugHuntHighlight, 0, len(order))
+	for _, group := range order {
+		group.status = groups[group]
+		highlights = append(highlights, group)
+	}
+	return highlights
+}
+
+// compareKey orders rows by creation time and id - the keyset of the paginated lists
+func compareKey(aCreatedAt pgtype.Timestamp, aId string, bCreatedAt pgtype.Timestamp, bId string) int {
+	return compareChain(aCreatedAt.Time.Compare(bCreatedAt.Time), cmp.Compare(aId, bId))
+}
+
+func (m *Memory) ListBugHuntRepos(ctx context.Context, arg db.ListBugHuntReposParams) ([]db.ListBugHuntReposRow, error) {
+	defer m.acquire()()
+	stats := make(map[string]*bugHuntStats)
+	for _, repo := range m.tables.repos {
+		stats[repo.RepoID] = newBugHuntStats()
+	}
+	highlights := m.tables.bugHuntHighlights(false, func(task db.LintTask) bool {
+		_, linterOk := m.tables.linter(task.LinterID)
+		_, repoOk := m.tables.repo(task.RepoID)
+		return linterOk && repoOk
+	})
+	for _, h := range highlights {
+		stats[h.repoId].add(m.tables, h)
+	}
+	repos := slices.Clone(m.tables.repos)
+	slices.SortFunc(repos, func(a, b db.Repo) int { return compareKey(a.CreatedAt, a.RepoID, b.CreatedAt, b.RepoID) })
+	var items []db.ListBugHuntReposRow
+	for _, repo := range repos {
+		if arg.AfterRepoID != "" && compareKey(repo.CreatedAt, repo.RepoID, arg.AfterCreatedAt, arg.AfterRepoID) <= 0 {
+			continue
+		}
+		if len(items) == int(arg.PageLimit) {
+			break
+		}
+		s := stats[repo.RepoID]
+		items = append(items, db.ListBugHuntReposRow{
+			RepoID:                repo.RepoID,
+			RepoGitUrl:            repo.RepoGitUrl,
+			RepoGitBranch:         repo.RepoGitBranch,
+			RepoLastGitCommitHash: repo.RepoLastGitCommitHash,
+			CreatedAt:             repo.CreatedAt,
+			UpdatedAt:             repo.UpdatedAt,
+			TotalHighlight:        int64(len(s.total)),
+			PendingHighlight:      int64(len(s.pending)),
+			RejectedHighlight:     int64(len(s.rejected)),
+			AcceptedHighlight:     int64(len(s.accepted)),
+			FixedHighlight:        int64(len(s.fixed)),
+		})
+	}
+	return items, nil
+}
+
+func (m *Memory) ListBugHuntLinters(ctx context.Context, arg db.ListBugHuntLintersParams) ([]db.ListBugHuntLintersRow, error) {
+	defer m.acquire()()
+	stats := make(map[string]*bugHuntStats)
+	var linters []db.Linter
+	for _, linter := range m.tables.linters {
+		if linter.LinterStatus == db.LinterStatusActive || linter.LinterStatus == db.LinterStatusPaused {
+			linters = append(linters, linter)
+			stats[linter.LinterID] = newBugHuntStats()
+		}
+	}
+	for _, h := range m.tables.bugHuntHighlights(false, func(db.LintTask) bool { return true }) {
+		if s, ok := stats[h.linterId]; ok {
+			s.add(m.tables, h)
+		}
+	}
+	slices.SortFunc(linters, func(a, b db.Linter) int { return compareKey(a.CreatedAt, a.LinterID, b.CreatedAt, b.LinterID) })
+	var items []db.ListBugHuntLintersRow
+	for _, linter := range linters {
+		if arg.AfterLinterID != "" && compareKey(linter.CreatedAt, linter.LinterID, arg.AfterCreatedAt, arg.AfterLinterID) <= 0 {
+			continue
+		}
+		if len(items) == int(arg.PageLimit) {
+			break
+		}
+		s := stats[linter.LinterID]
+		items = append(items, db.ListBugHuntLintersRow{
+			LinterID:                linter.LinterID,
+			LinterGitUrl:            linter.LinterGitUrl,
+			LinterGitBranch:         linter.LinterGitBranch,
+			LinterLastDockerImage:   linter.LinterLastDockerImage,
+			LinterLastDockerShaHash: linter.LinterLastDockerShaHash,
+			CreatedAt:               linter.CreatedAt,
+			UpdatedAt:               linter.UpdatedAt,
+			TotalHighlight:          int64(len(s.total)),
+			PendingHighlight:        int64(len(s.pending)),
+			RejectedHighlight:       int64(len(s.rejected)),
+			AcceptedHighlight:       int64(len(s.accepted)),
+			FixedHighlight:          int64(len(s.fixed)),
+		})
+	}
+	return items, nil
+}
+
+func (m *Memory) ListBugHuntLinterRules(ctx context.Context) ([]db.ListBugHuntLinterRulesRow, error) {
+	defer m.acquire()()
+	type rule struct{ linterId, ruleId string }
+	stats := make(map[rule]*db.ListBugHuntLinterRulesRow)
+	var rules []rule
+	for _, h := range m.tables.bugHuntHighlights(true, func(db.LintTask) bool { return true }) {
+		if h.ruleId == "" {
+			continue
+		}
+		key := rule{linterId: h.linterId, ruleId: h.ruleId}
+		row, ok := stats[key]
+		if !ok {
+			row = &db.ListBugHuntLinterRulesRow{LinterID: h.linterId, RuleID: h.ruleId}
+			stats[key] = row
+			rules = append(rules, key)
+		}
+		row.TotalHighlight++
+		switch h.status {
+		case db.HighlightStatusPending:
+			row.PendingHighlight++
+		case db.HighlightStatusRejected:
+			row.RejectedHighlight++
+		case db.HighlightStatusAccepted:
+			row.AcceptedHighlight++
+			if track, ok := m.tables.highlightTrack(h.repoId, h.linterId, h.key); ok && track.FixedAt.Valid {
+				row.FixedHighlight++
+			}
+		}
+	}
+	var items []db.ListBugHuntLinterRulesRow
+	for _, key := range rules {
+		items = append(items, *stats[key])
+	}
+	slices.SortStableFunc(items, func(a, b db.ListBugHuntLinterRulesRow) int {
+		return compareChain(
+			cmp.Compare(a.LinterID, b.LinterID),
+			cmp.Compare(b.AcceptedHighlight, a.AcceptedHighlight),
+			cmp.Compare(b.PendingHighlight, a.PendingHighlight),
+			cmp.Compare(a.RejectedHighlight, b.RejectedHighlight),
+			cmp.Compare(a.RuleID, b.RuleID),
+		)
+	})
+	return items, nil
+}
+
+func (m *Memory) ListBugHuntLinterUsage(ctx context.Context) ([]db.ListBugHuntLinterUsageRow, error) {
+	defer m.acquire()()
+	type usage struct {
+		row               db.ListBugHuntLinterUsageRow
+		rssTasks, ioTasks int64
+		totalRss, totalIo int64
+	}
+	byLinter := make(map[string]*usage)
+	for _, task := range m.tables.lintTasks {
+		if !task.CpuSeconds.Valid {
+			continue
+		}
+		u, ok := byLinter[task.LinterID]
+		if !ok {
+			u = &usage{row: db.ListBugHuntLinterUsageRow{LinterID: task.LinterID}}
+			byLinter[task.LinterID] = u
+		}
+		u.row.TotalTasks++
+		u.row.TotalCpuSeconds += task.CpuSeconds.Float64
+		if task.MaxRssBytes.Valid {
+			u.row.MaxRssBytes = max(u.row.MaxRssBytes, task.MaxRssBytes.Int64)
+			u.totalRss += task.MaxRssBytes.Int64
+			u.rssTasks++
+		}
+		if task.IoBytes.Valid {
+			u.totalIo += task.IoBytes.Int64
+			u.ioTasks++
+		}
+		if task.MaxPids.Valid {
+			u.row.MaxPids = max(u.row.MaxPids, task.MaxPids.Int64)
+		}
+	}
+	// AVG of BIGINT is NUMERIC in Postgres which is rounded half away from zero when cast back to BIGINT
+	average := func(total, count int64) int64 {
+		if count == 0 {
+			return 0
+		}
+		return int64(math.Round(float64(total) / float64(count)))
+	}
+	var items []db.ListBugHuntLinterUsageRow
+	for _, u := range byLinter {
+		u.row.AvgCpuSeconds = u.row.TotalCpuSeconds / float64(u.row.TotalTasks)
+		u.row.AvgRssBytes = average(u.totalRss, u.rssTasks)
+		u.row.AvgIoBytes = average(u.totalIo, u.ioTasks)
+		items = append(items, u.row)
+	}
+	slices.SortFunc(items, func(a, b db.ListBugHuntLinterUsageRow) int { return cmp.Compare(a.LinterID, b.LinterID) })
+	return items, nil
+}
+
+func (m *Memory) ListBugHuntLintTasks(ctx context.Context, arg db.ListBugHuntLintTasksParams) ([]db.ListBugHuntLintTasksRow, error) {
+	defer m.acquire()()
+	var tasks []db.LintTask
+	for _, task := range m.tables.lintTasks {
+		_, linterOk := m.tables.linter(task.LinterID)
+		_, repoOk := m.tables.repo(task.RepoID)
+		if !linterOk || !repoOk || arg.LinterID != "" && task.LinterID != arg.LinterID {
+			continue
+		}
+		if arg.BeforeLintID != "" && compareKey(task.CreatedAt, task.LintID, arg.BeforeCreatedAt, arg.BeforeLintID) >= 0 {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	slices.SortFunc(tasks, func(a, b db.LintTask) int { return compareKey(b.CreatedAt, b.LintID, a.CreatedAt, a.LintID) })
+	tasks = tasks[:min(int(arg.PageLimit), len(tasks))]
+	var items []db.ListBugHuntLintTasksRow
+	for _, task := range tasks {
+		linter, _ := m.tables.linter(task.LinterID)
+		repo, _ := m.tables.repo(task.RepoID)
+		items = append(items, db.ListBugHuntLintTasksRow{
+			RepoID:              repo.RepoID,
+			RepoGitUrl:          repo.RepoGitUrl,
+			RepoGitBranch:       repo.RepoGitBranch,
+			RepoGitCommitHash:   task.RepoGitCommitHash,
+			LinterID:            linter.LinterID,
+			LinterGitUrl:        linter.LinterGitUrl,
+			LinterGitBranch:     linter.LinterGitBranch,
+			LinterOwner:         linter.LinterOwner,
+			LinterDockerImage:   task.LinterDockerImage,
+			LinterDockerShaHash: task.LinterDockerShaHash,
+			LintID:              task.LintID,
+			LintStatus:          task.LintStatus,
+			LintStatusComment:   task.LintStatusComment,
+			LintDuration:        task.LintDuration,
+			LintAttempts:        task.LintAttempts,
+			NextAttemptAt:       task.NextAttemptAt,
+			CpuSeconds:          task.CpuSeconds,
+			MaxRssBytes:         task.MaxRssBytes,
+			IoBytes:             task.IoBytes,
+			MaxPids:             task.MaxPids,
+			CreatedAt:           task.CreatedAt,
+			HasLogs:             slices.ContainsFunc(m.tables.lintLogs, func(logs db.LintLog) bool { return logs.LintID == task.LintID }),
+		})
+	}
+	return items, nil
+}
+
+func (m *Memory) ListBugHuntHighlights(ctx context.Context, arg db.ListBugHuntHighlightsParams) ([]db.ListBugHuntHighlightsRow, error) {
+	defer m.acquire()()
+	var (
+		rows []db.ListBugHuntHighlightsRow
+		keys []string
+	)
+	for _, h := range m.tables.lintHighlights {
+		task, taskOk := m.tables.lintTask(h.LintID)
+		linter, linterOk := m.tables.linter(task.LinterID)
+		repo, repoOk := m.tables.repo(task.RepoID)
+		if !taskOk || !linterOk || !repoOk {
+			continue
+		}
+		if lintId := textArg(arg.LintID); lintId != "" && h.LintID != lintId ||
+			textArg(arg.LinterID) != "" && task.LinterID != textArg(arg.LinterID) ||
+			textArg(arg.RepoID) != "" && task.RepoID != textArg(arg.RepoID) ||
+			textArg(arg.RuleID) != "" && h.RuleID != textArg(arg.RuleID) ||
+			textArg(arg.Severity) != "" && string(h.Severity) != textArg(arg.Severity) {
+			continue
+		}
+		row := db.ListBugHuntHighlightsRow{
+			RepoID:              task.RepoID,
+			RepoGitUrl:          repo.RepoGitUrl,
+			RepoGitBranch:       repo.RepoGitBranch,
+			RepoGitCommitHash:   task.RepoGitCommitHash,
+			LinterID:            task.LinterID,
+			LinterGitUrl:        linter.LinterGitUrl,
+			LinterGitBranch:     linter.LinterGitBranch,
+			LinterOwner:         linter.LinterOwner,
+			LinterDockerImage:   task.LinterDockerImage,
+			LinterDockerShaHash: task.LinterDockerShaHash,
+			LintStatus:          task.LintStatus,
+			LintStatusComment:   task.LintStatusComment,
+			LintDuration:        task.LintDuration,
+			CreatedAt:           task.CreatedAt,
+			LintID:              h.LintID,
+			Path:                h.Path,
+			StartLine:           h.StartLine,
+			EndLine:             h.EndLine,
+			StartColumn:         h.StartColumn,
+			EndColumn:           h.EndColumn,
+			Severity:            h.Severity,
+			RuleID:              h.RuleID,
+			Explanation:         h.Explanation,
+			SnippetStartLine:    h.SnippetStartLine,
+			SnippetEndLine:      h.SnippetEndLine,
+			SnippetCode:         h.SnippetCode,
+			Fingerprint:         h.Fingerprint,
+			ModerationStatus:    h.ModerationStatus,
+			ModerationComment:   h.ModerationComment,
+			ModeratedAt:         h.ModeratedAt,
+		}
+		if track, ok := m.tables.highlightTrack(task.RepoID, task.LinterID, h.Fingerprint); ok {
+			row.FirstSeenCommitHash = pgtype.Text{String: track.FirstSeenCommitHash, Valid: true}
+			row.LastSeenCommitHash = pgtype.Text{String: track.LastSeenCommitHash, Valid: true}
+			row.FixedCommitHash = track.FixedCommitHash
+		}
+		rows = append(rows, row)
+		keys = append(keys, task.RepoID+"\x00"+task.LinterID+"\x00"+highlightKey(h))
+	}
+	strongest := make(map[string]int)
+	for i, row := range rows {
+		strongest[keys[i]] = max(strongest[keys[i]], slices.Index(highlightStatusOrder, row.ModerationStatus))
+	}
+	var items []db.ListBugHuntHighlightsRow
+	for i, row := range rows {
+		if slices.Index(highlightStatusOrder, row.ModerationStatus) != strongest[keys[i]] {
+			continue
+		}
+		// status filter is applied to the deduplicated highlight: stale status of the other commit must not leak
+		if textArg(arg.ModerationStatus) != "" && string(row.ModerationStatus) != textArg(arg.ModerationStatus) {
+			continue
+		}
+		owned := row.LinterOwner.Valid && row.LinterOwner.String == arg.PendingOwner
+		if !arg.AllPending && row.ModerationStatus == db.HighlightStatusPending && !owned {
+			continue
+		}
+		if arg.AfterLintID != "" && compareHighlightKey(row, db.ListBugHuntHighlightsRow{
+			CreatedAt:   arg.AfterCreatedAt,
+			LintID:      arg.AfterLintID,
+			Path:        arg.AfterPath,
+			StartLine:   arg.AfterStartLine,
+			EndLine:     arg.AfterEndLine,
+			Fingerprint: arg.AfterFingerprint,
+		}) <= 0 {
+			continue
+		}
+		items = append(items, row)
+	}
+	slices.SortFunc(items, compareHighlightKey)
+	return items[:min(int(arg.PageLimit), len(items))], nil
+}
+
+func compareHighlightKey(a, b db.ListBugHuntHighlightsRow) int {
+	return compareChain(
+		compareKey(a.CreatedAt, a.LintID, b.CreatedAt, b.LintID),
+		cmp.Compare(a.Path, b.Path),
+		cmp.Compare(a.StartLine, b.StartLine),
+		cmp.Compare(a.EndLine, b.EndLine),
+		cmp.Compare(a.Fingerprint, b.Fingerprint),
+	)
+}
+
+func (m *Memory) ModerateBugHuntHighlight(ctx context.Context, arg db.ModerateBugHuntHighlightParams) error {
+	defer m.acquire()()
+	type target struct{ repoId, linterId, fingerprint string }
+	targets := make(map[target]struct{})
+	for _, h := range m.tables.lintHighlights {
+		if !isHighlight(h, arg.LintID, arg.Fingerprint) {
+			continue
+		}
+		if task, ok := m.tables.lintTask(h.LintID); ok {
+			targets[target{repoId: task.RepoID, linterId: task.LinterID, fingerprint: h.Fingerprint}] = struct{}{}
+		}
+	}
+	for i, h := range m.tables.lintHighlights {
+		task, _ := m.tables.lintTask(h.LintID)
+		if _, ok := targets[target{repoId: task.RepoID, linterId: task.LinterID, fingerprint: h.Fingerprint}]; !ok {
+			continue
+		}
+		h.ModerationStatus = arg.ModerationStatus
+		h.ModerationComment = arg.ModerationComment
+		h.ModeratedAt = arg.ModeratedAt
+		h.ModeratedBy = arg.ModeratedBy
+		m.tables.lintHighlights[i] = h
+	}
+	return nil
+}
+
+func (m *Memory) GetModerationStatus(ctx context.Context, arg db.GetModerationStatusParams) (db.HighlightStatus, error) {
+	defer m.acquire()()
+	for _, h := range m.tables.lintHighlights {
+		if isHighlight(h, arg.LintID, arg.Fingerprint) {
+			return h.ModerationStatus, nil
+		}
+	}
+	return "", pgx.ErrNoRows
+}
+
+func (m *Memory) AddModerationEvent(ctx context.Context, arg db.AddModerationEventParams) (int64, error) {
+	defer m.acquire()()
+	for _, h := range m.tables.lintHighlights {
+		if !isHighlight(h, arg.LintID, arg.Fingerprint) {
+			continue
+		}
+		eventId := int64(len(m.tables.moderationEvents) + 1) // events are never deleted
+		m.tables.moderationEvents = append(m.tables.moderationEvents, db.ModerationEvent{
+			EventID:          eventId,
+			LintID:           h.LintID,
+			Path:             h.Path,
+			StartLine:        h.StartLine,
+			EndLine:          h.EndLine,
+			Fingerprint:      h.Fingerprint,
+			PreviousStatus:   h.ModerationStatus,
+			ModerationStatus: arg.ModerationStatus,
+			Vote:             arg.Vote,
+			ModeratorLogin:   arg.ModeratorLogin,
+			Comment:          arg.Comment,
+			RevertedEventID:  arg.RevertedEventID,
+			CreatedAt:        arg.CreatedAt,
+		})
+		return eventId, nil
+	}
+	return 0, pgx.ErrNoRows
+}
+
+func (m *Memory) GetModerationEvent(ctx context.Context, eventID int64) (db.ModerationEvent, error) {
+	defer m.acquire()()
+	for _, event := range m.tables.moderationEvents {
+		if event.EventID == eventID {
+			return event, nil
+		}
+	}
+	return db.ModerationEvent{}, pgx.ErrNoRows
+}
+
+func (m *Memory) ListModerationEvents(ctx context.Context, arg db.ListModerationEventsParams) ([]db.ListModerationEventsRow, error) {
+	defer m.acquire()()
+	type source struct{ repoId, linterId string }
+	sources := make(map[source]struct{})
+	for _, lintId := range arg.LintIds {
+		if task, ok := m.tables.lintTask(lintId); ok {
+			sources[source{task.RepoID, task.LinterID}] = struct{}{}
+		}
+	}
+	var items []db.ListModerationEventsRow
+	for _, e := range m.tables.moderationEvents {
+		if !slices.Contains(arg.Fingerprints, e.Fingerprint) {
+			continue
+		}
+		task, ok := m.tables.lintTask(e.LintID)
+		if _, found := sources[source{task.RepoID, task.LinterID}]; !ok || !found {
+			continue
+		}
+		items = append(items, db.ListModerationEventsRow{
+			EventID:          e.EventID,
+			RepoID:           task.RepoID,
+			LinterID:         task.LinterID,
+			LintID:           e.LintID,
+			Path:             e.Path,
+			StartLine:        e.StartLine,
+			EndLine:          e.EndLine,
+			Fingerprint:      e.Fingerprint,
+			PreviousStatus:   e.PreviousStatus,
+			ModerationStatus: e.ModerationStatus,
+			Vote:             e.Vote,
+			ModeratorLogin:   e.ModeratorLogin,
+			Comment:          e.Comment,
+			RevertedEventID:  e.RevertedEventID,
+			CreatedAt:        e.CreatedAt,
+		})
+	}
+	slices.SortStableFunc(items, func(a, b db.ListModerationEventsRow) int {
+		return compareChain(a.CreatedAt.Time.Compare(b.CreatedAt.Time), cmp.Compare(a.EventID, b.EventID))
+	})
+	return items, nil
+}
+
+// voteTargets returns (repo, linter, highlight key) of the highlight as moderation votes identify it
+func (t *memoryTables) voteTargets(lintId, fingerprint string) []db.ModerationVote {
+	var targets []db.ModerationVote
+	for _, h := range t.lintHighlights {
+		if !isHighlight(h, lintId, fingerprint) {
+			continue
+		}
+		if task, ok := t.lintTask(h.LintID); ok {
+			targets = append(targets, db.ModerationVote{RepoID: task.RepoID, LinterID: task.LinterID, HighlightKey: highlightKey(h)})
+		}
+	}
+	return targets
+}
+
+func sameVoteTarget(a, b db.ModerationVote) bool {
+	return a.RepoID == b.RepoID && a.LinterID == b.LinterID && a.HighlightKey == b.HighlightKey
+}
+
+func (m *Memory) UpsertModerationVote(ctx context.Context, arg db.UpsertModerationVoteParams) (int64, error) {
+	defer m.acquire()()
+	targets := m.tables.voteTargets(arg.LintID, arg.Fingerprint)
+	if len(targets) == 0 {
+		return 0, nil
+	}
+	vote := targets[0]
+	vote.ModeratorLogin, vote.Vote, vote.Comment, vote.VotedAt = arg.ModeratorLogin, arg.Vote, arg.Comment, arg.VotedAt
+	for i, existing := range m.tables.moderationVotes {
+		if sameVoteTarget(existing, vote) && existing.ModeratorLogin == vote.ModeratorLogin {
+			m.tables.moderationVotes[i] = vote
+			return 1, nil
+		}
+	}
+	m.tables.moderationVotes = append(m.tables.moderationVotes, vote)
+	return 1, nil
+}
+
+func (m *Memory) DeleteModerationVote(ctx context.Context, arg db.DeleteModerationVoteParams) error {
+	defer m.acquire()()
+	targets := m.tables.voteTargets(arg.LintID, arg.Fingerprint)
+	m.tables.moderationVotes = slices.DeleteFunc(m.tables.moderationVotes, func(vote db.ModerationVote) bool {
+		return vote.ModeratorLogin == arg.ModeratorLogin && slices.ContainsFunc(targets, func(target db.ModerationVote) bool {
+			return sameVoteTarget(vote, target)
+		})
+	})
+	return nil
+}
+
+func (m *Memory) CountModerationVotes(ctx context.Context, arg db.CountModerationVotesParams) (db.CountModerationVotesRow, error) {
+	defer m.acquire()()
+	targets := m.tables.voteTargets(arg.LintID, arg.Fingerprint)
+	accepted, rejected := make(map[string]struct{}), make(map[string]struct{})
+	for _, vote := range m.tables.moderationVotes {
+		if !slices.ContainsFunc(targets, func(target db.ModerationVote) bool { return sameVoteTarget(vote, target) }) {
+			continue
+		}
+		switch vote.Vote {
+		case db.HighlightStatusAccepted:
+			accepted[vote.ModeratorLogin] = struct{}{}
+		case db.HighlightStatusRejected:
+			rejected[vote.ModeratorLogin] = struct{}{}
+		}
+	}
+	return db.CountModerationVotesRow{AcceptedVotes: int64(len(accepted)), RejectedVotes: int64(len(rejected))}, nil
+}
+
+func (m *Memory) AddUser(ctx context.Context, arg db.AddUserParams) error {
+	defer m.acquire()()
+	for _, user := range m.tables.users {
+		if user.UserLogin == arg.UserLogin {
+			return nil
+		}
+	}
+	m.tables.users = append(m.tables.users, db.User{UserLogin: arg.UserLogin, CreatedAt: arg.CreatedAt, LastLoginAt: arg.CreatedAt})
+	return nil
+}
+
+func (m *Memory) UpsertUser(ctx context.Context, arg db.UpsertUserParams) error {
+	defer m.acquire()()
+	for i, user := range m.tables.users {
+		if user.UserLogin == arg.UserLogin {
+			m.tables.users[i].UserName = arg.UserName
+			m.tables.users[i].LastLoginAt = arg.CreatedAt
+			return nil
+		}
+	}
+	m.tables.users = append(m.tables.users, db.User{UserLogin: arg.UserLogin, UserName: arg.UserName, CreatedAt: arg.CreatedAt, LastLoginAt: arg.CreatedAt})
+	return nil
+}
+
+func (t *memoryTables) roles(userLogin string) []db.AccessRole {
+	var roles []db.AccessRole
+	for _, role := range t.userRoles {
+		if role.UserLogin == userLogin {
+			roles = append(roles, role.Role)
+		}
+	}
+	slices.SortFunc(roles, func(a, b db.AccessRole) int {
+		return cmp.Compare(slices.Index(accessRoleOrder, a), slices.Index(accessRoleOrder, b))
+	})
+	return roles
+}
+
+func (m *Memory) ListUsers(ctx context.Context) ([]db.ListUsersRow, error) {
+	defer m.acquire()()
+	users := slices.Clone(m.tables.users)
+	slices.SortStableFunc(users, func(a, b db.User) int { return b.LastLoginAt.Time.Compare(a.LastLoginAt.Time) })
+	var items []db.ListUsersRow
+	for _, user := range users {
+		var roles []string
+		for _, role := range m.tables.roles(user.UserLogin) {
+			roles = append(roles, string(role))
+		}
+		items = append(items, db.ListUsersRow{
+			UserLogin:   user.UserLogin,
+			UserName:    user.UserName,
+			CreatedAt:   user.CreatedAt,
+			LastLoginAt: user.LastLoginAt,
+			Roles:       strings.Join(roles, ","),
+		})
+	}
+	return items, nil
+}
+
+func (m *Memory) ListUserRoles(ctx context.Context, userLogin string) ([]db.AccessRole, error) {
+	defer m.acquire()()
+	return m.tables.roles(userLogin), nil
+}
+
+func (m *Memory) GrantUserRole(ctx context.Context, arg db.GrantUserRoleParams) error {
+	defer m.acquire()()
+	if slices.Contains(m.tables.roles(arg.UserLogin), arg.Role) {
+		return nil
+	}
+	m.tables.userRoles = append(m.tables.userRoles, db.UserRole{
+		UserLogin: arg.UserLogin,
+		Role:      arg.Role,
+		GrantedBy: arg.GrantedBy,
+		GrantedAt: arg.GrantedAt,
+	})
+	return nil
+}
+
+func (m *Memory) RevokeUserRole(ctx context.Context, arg db.RevokeUserRoleParams) error {
+	defer m.acquire()()
+	m.tables.userRoles = slices.DeleteFunc(m.tables.userRoles, func(role db.UserRole) bool {
+		return role.UserLogin == arg.UserLogin && role.Role == arg.Role
+	})
+	return nil
+}
+
+func (m *Memory) CreateApiToken(ctx context.Context, arg db.CreateApiTokenParams) (int64, error) {
+	defer m.acquire()()
+	if slices.ContainsFunc(m.tables.apiTokens, func(token db.ApiToken) bool { return token.TokenHash == arg.TokenHash }) {
+		return 0, uniqueViolation("api_tokens_token_hash_key")
+	}
+	tokenId := int64(len(m.tables.apiTokens) + 1) // tokens are never deleted
+	m.tables.apiTokens = append(m.tables.apiTokens, db.ApiToken{
+		TokenID:    tokenId,
+		UserLogin:  arg.UserLogin,
+		TokenName:  arg.TokenName,
+		TokenHash:  arg.TokenHash,
+		TokenScope: arg.TokenScope,
+		CreatedAt:  arg.CreatedAt,
+	})
+	return tokenId, nil
+}
+
+func (m *Memory) GetActiveApiToken(ctx context.Context, tokenHash string) (db.GetActiveApiTokenRow, error) {
+	defer m.acquire()()
+	for _, token := range m.tables.apiTokens {
+		if token.TokenHash == tokenHash && !token.RevokedAt.Valid {
+			return db.GetActiveApiTokenRow{TokenID: token.TokenID, UserLogin: token.UserLogin, TokenScope: token.TokenScope}, nil
+		}
+	}
+	return db.GetActiveApiTokenRow{}, pgx.ErrNoRows
+}
+
+func (m *Memory) ListApiTokens(ctx context.Context, userLogin string) ([]db.ListApiTokensRow, error) {
+	defer m.acquire()()
+	var items []db.ListApiTokensRow
+	for _, token := range m.tables.apiTokens {
+		if token.UserLogin != userLogin {
+			continue
+		}
+		items = append(items, db.ListApiTokensRow{
+			TokenID:    token.TokenID,
+			TokenName:  token.TokenName,
+			TokenScope: token.TokenScope,
+			CreatedAt:  token.CreatedAt,
+			LastUsedAt: token.LastUsedAt,
+			RevokedAt:  token.RevokedAt,
+		})
+	}
+	slices.SortStableFunc(items, func(a, b db.ListApiTokensRow) int { return b.CreatedAt.Time.Compare(a.CreatedAt.Time) })
+	return items, nil
+}
+
+func (m *Memory) TouchApiToken(ctx context.Context, arg db.TouchApiTokenParams) error {
+	defer m.acquire()()
+	for i, token := range m.tables.apiTokens {
+		if token.TokenID == arg.TokenID {
+			m.tables.apiTokens[i].LastUsedAt = arg.LastUsedAt
+		}
+	}
+	return nil
+}
+
+func (m *Memory) RevokeApiToken(ctx context.Context, arg db.RevokeApiTokenParams) (int64, error) {
+	defer m.acquire()()
+	var revoked int64
+	for i, token := range m.tables.apiTokens {
+		if token.TokenID == arg.TokenID && token.UserLogin == arg.UserLogin && !token.RevokedAt.Valid {
+			m.tables.apiTokens[i].RevokedAt = arg.RevokedAt
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (m *Memory) RevokeSession(ctx context.Context, arg db.RevokeSessionParams) error {
+	defer m.acquire()()
+	if slices.ContainsFunc(m.tables.revokedSessions, func(s db.RevokedSession) bool { return s.SessionID == arg.SessionID }) {
+		return nil
+	}
+	m.tables.revokedSessions = append(m.tables.revokedSessions, db.RevokedSession(arg))
+	return nil
+}
+
+func (m *Memory) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	defer m.acquire()()
+	return slices.ContainsFunc(m.tables.revokedSessions, func(s db.RevokedSession) bool { return s.SessionID == sessionID }), nil
+}
+
+func (m *Memory) DeleteExpiredRevokedSessions(ctx context.Context, expiresAt pgtype.Timestamp) (int64, error) {
+	defer m.acquire()()
+	before := len(m.tables.revokedSessions)
+	m.tables.revokedSessions = slices.DeleteFunc(m.tables.revokedSessions, func(s db.RevokedSession) bool {
+		return s.ExpiresAt.Time.Before(expiresAt.Time)
+	})
+	return int64(before - len(m.tables.revokedSessions)), nil
+}
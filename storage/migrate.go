@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sivukhin/gobughunt/lib/logging"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// migrationsLockId is the key of the advisory lock which serializes migrations of concurrently started binaries
+const migrationsLockId = 0x62756768756e74 // "bughunt"
+
+var migrationNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Script  string
+}
+
+// LoadMigrations reads migrations named as <version>_<name>.sql - versions must go one after another starting from 1
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations dir: %w", err)
+	}
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		match := migrationNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			return nil, fmt.Errorf("unexpected migration file name: %v", entry.Name())
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected migration version: %v", entry.Name())
+		}
+		script, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %v: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: match[2], Script: string(script)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration %v_%v is out of order: expected version %v", migration.Version, migration.Name, i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// Migrations returns migrations embedded into the binary
+func Migrations() []Migration {
+	migrations, err := LoadMigrations(embeddedMigrations, "migrations")
+	if err != nil {
+		panic(fmt.Errorf("embedded migrations are broken: %w", err))
+	}
+	return migrations
+}
+
+// CheckSchemaVersion refuses to work with the schema which is newer than the binary knows about
+func CheckSchemaVersion(current int, migrations []Migration) error {
+	if current > len(migrations) {
+		return fmt.Errorf("database schema version %v is newer than the latest known migration %v: binary is outdated", current, len(migrations))
+	}
+	return nil
+}
+
+// Migrate applies pending migrations - every migration is applied in its own transaction together with the version bump
+func Migrate(ctx context.Context, pool *pgxpool.Pool, migrations []Migration) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationsLockId)
+	if err != nil {
+		return fmt.Errorf("failed to take migrations lock: %w", err)
+	}
+	defer func() { _, _ = conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationsLockId) }()
+
+	current, err := adoptSchemaVersion(ctx, conn.Conn())
+	if err != nil {
+		return err
+	}
+	err = CheckSchemaVersion(current, migrations)
+	if err != nil {
+		return err
+	}
+	for _, migration := range migrations[current:] {
+		err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, migration.Script)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, now())", migration.Version, migration.Name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %v_%v: %w", migration.Version, migration.Name, err)
+		}
+		logging.Logger.Infof("migration applied: version=%v, name=%v", migration.Version, migration.Name)
+	}
+	return nil
+}
+
+// EnsureSchemaVersion checks that all migrations were applied to the database and that it's not newer than the binary
+func EnsureSchemaVersion(ctx context.Context, pool *pgxpool.Pool, migrations []Migration) error {
+	var current int
+	err := pool.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current)
+	if err != nil {
+		return fmt.Errorf("failed to get schema version: %w", err)
+	}
+	err = CheckSchemaVersion(current, migrations)
+	if err != nil {
+		return err
+	}
+	if current < len(migrations) {
+		return fmt.Errorf("database schema version %v is older than %v: run migrate subcommand", current, len(migrations))
+	}
+	return nil
+}
+
+// adoptSchemaVersion creates version table and returns current schema version.
+// Databases created from the plain schema files before migrations were introduced are adopted as the baseline version
+func adoptSchemaVersion(ctx context.Context, conn *pgx.Conn) (int, error) {
+	_, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations
+(
+    version    INT PRIMARY KEY,
+    name       TEXT      NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create schema version table: %w", err)
+	}
+	var current int
+	err = conn.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	if current > 0 {
+		return current, nil
+	}
+	var baseline bool
+	err = conn.QueryRow(ctx, "SELECT to_regclass('lint_tasks') IS NOT NULL").Scan(&baseline)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check baseline schema: %w", err)
+	}
+	if !baseline {
+		return 0, nil
+	}
+	_, err = conn.Exec(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (1, 'baseline', now())")
+	if err != nil {
+		return 0, fmt.Errorf("failed to adopt baseline schema: %w", err)
+	}
+	logging.Logger.Infof("existing schema adopted as baseline migration")
+	return 1, nil
+}
+
+// PrepareSchema is called at startup of every binary: it either applies pending migrations or just checks schema version
+func PrepareSchema(ctx context.Context, pool *pgxpool.Pool, skipMigrations bool) error {
+	if skipMigrations {
+		return EnsureSchemaVersion(ctx, pool, Migrations())
+	}
+	return Migrate(ctx, pool, Migrations())
+}
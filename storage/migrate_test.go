@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("ordered", func(t *testing.T) {
+		migrations, err := LoadMigrations(fstest.MapFS{
+			"m/0002_second.sql": {Data: []byte("SELECT 2")},
+			"m/0001_first.sql":  {Data: []byte("SELECT 1")},
+		}, "m")
+		require.Nil(t, err)
+		require.Equal(t, []Migration{
+			{Version: 1, Name: "first", Script: "SELECT 1"},
+			{Version: 2, Name: "second", Script: "SELECT 2"},
+		}, migrations)
+	})
+	t.Run("gap", func(t *testing.T) {
+		_, err := LoadMigrations(fstest.MapFS{
+			"m/0001_first.sql": {Data: []byte("SELECT 1")},
+			"m/0003_third.sql": {Data: []byte("SELECT 3")},
+		}, "m")
+		require.NotNil(t, err)
+	})
+	t.Run("duplicate", func(t *testing.T) {
+		_, err := LoadMigrations(fstest.MapFS{
+			"m/0001_first.sql": {Data: []byte("SELECT 1")},
+			"m/0001_other.sql": {Data: []byte("SELECT 1")},
+		}, "m")
+		require.NotNil(t, err)
+	})
+	t.Run("malformed name", func(t *testing.T) {
+		_, err := LoadMigrations(fstest.MapFS{"m/first.sql": {Data: []byte("SELECT 1")}}, "m")
+		require.NotNil(t, err)
+	})
+	t.Run("embedded", func(t *testing.T) {
+		migrations := Migrations()
+		require.Equal(t, "baseline", migrations[0].Name)
+	})
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	migrations := []Migration{{Version: 1, Name: "first"}, {Version: 2, Name: "second"}}
+	require.Nil(t, CheckSchemaVersion(0, migrations))
+	require.Nil(t, CheckSchemaVersion(2, migrations))
+	require.NotNil(t, CheckSchemaVersion(3, migrations))
+}
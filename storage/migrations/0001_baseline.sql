@@ -0,0 +1,58 @@
+CREATE TABLE IF NOT EXISTS repos
+(
+    repo_id                   TEXT UNIQUE NOT NULL,
+    repo_git_url              TEXT        NOT NULL,
+    repo_git_branch           TEXT        NOT NULL,
+    repo_last_git_commit_hash TEXT,
+    created_at                TIMESTAMP   NOT NULL,
+    updated_at                TIMESTAMP   NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS linters
+(
+    linter_id                   TEXT UNIQUE NOT NULL,
+    linter_git_url              TEXT        NOT NULL,
+    linter_git_branch           TEXT        NOT NULL,
+    linter_last_docker_image    TEXT,
+    linter_last_docker_sha_hash TEXT,
+    created_at                  TIMESTAMP   NOT NULL,
+    updated_at                  TIMESTAMP   NOT NULL
+);
+
+CREATE TYPE lint_status AS ENUM ('pending', 'locked', 'succeed', 'failed', 'skipped');
+CREATE TABLE IF NOT EXISTS lint_tasks
+(
+    lint_id                TEXT       NOT NULL UNIQUE,
+    linter_id              TEXT       NOT NULL,
+    linter_docker_image    TEXT       NOT NULL,
+    linter_docker_sha_hash TEXT       NOT NULL,
+    repo_id                TEXT       NOT NULL,
+    repo_git_url           TEXT       NOT NULL,
+    repo_git_commit_hash   TEXT       NOT NULL,
+
+    lint_status            lint_status NOT NULL DEFAULT 'pending',
+    lint_status_comment    TEXT,
+    lint_duration          INTERVAL,
+    created_at             TIMESTAMP  NOT NULL,
+    locked_at              TIMESTAMP,
+    linted_at              TIMESTAMP
+);
+CREATE UNIQUE INDEX hash_unique ON lint_tasks
+    (linter_docker_image, linter_docker_sha_hash, repo_git_url, repo_git_commit_hash);
+
+CREATE TYPE highlight_status AS ENUM ('pending', 'accepted', 'rejected');
+CREATE TABLE IF NOT EXISTS lint_highlights
+(
+    lint_id            TEXT            NOT NULL,
+    path               TEXT            NOT NULL,
+    start_line         INT             NOT NULL,
+    end_line           INT             NOT NULL,
+    explanation        TEXT            NOT NULL,
+    snippet_start_line INT             NOT NULL,
+    snippet_end_line   INT             NOT NULL,
+    snippet_code       TEXT            NOT NULL,
+
+    moderation_status  highlight_status NOT NULL DEFAULT 'pending',
+    moderation_comment TEXT,
+    moderated_at       TIMESTAMP
+);
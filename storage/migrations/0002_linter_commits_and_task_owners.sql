@@ -0,0 +1,2 @@
+ALTER TABLE linters ADD COLUMN IF NOT EXISTS linter_last_git_commit_hash TEXT;
+ALTER TABLE lint_tasks ADD COLUMN IF NOT EXISTS locked_by TEXT;
@@ -0,0 +1,5 @@
+CREATE TYPE highlight_severity AS ENUM ('notice', 'warning', 'error');
+ALTER TABLE lint_highlights ADD COLUMN IF NOT EXISTS start_column INT NOT NULL DEFAULT 0;
+ALTER TABLE lint_highlights ADD COLUMN IF NOT EXISTS end_column INT NOT NULL DEFAULT 0;
+ALTER TABLE lint_highlights ADD COLUMN IF NOT EXISTS severity highlight_severity NOT NULL DEFAULT 'warning';
+ALTER TABLE lint_highlights ADD COLUMN IF NOT EXISTS rule_id TEXT NOT NULL DEFAULT '';
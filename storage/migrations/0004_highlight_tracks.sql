@@ -0,0 +1,16 @@
+ALTER TABLE lint_highlights ADD COLUMN IF NOT EXISTS fingerprint TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS lint_highlights_fingerprint ON lint_highlights (fingerprint);
+
+CREATE TABLE IF NOT EXISTS highlight_tracks
+(
+    repo_id                TEXT      NOT NULL,
+    linter_id              TEXT      NOT NULL,
+    fingerprint            TEXT      NOT NULL,
+    first_seen_commit_hash TEXT      NOT NULL,
+    first_seen_at          TIMESTAMP NOT NULL,
+    last_seen_commit_hash  TEXT      NOT NULL,
+    last_seen_at           TIMESTAMP NOT NULL,
+    fixed_commit_hash      TEXT,
+    fixed_at               TIMESTAMP,
+    PRIMARY KEY (repo_id, linter_id, fingerprint)
+);
@@ -0,0 +1,36 @@
+ALTER TABLE lint_highlights ADD COLUMN IF NOT EXISTS moderated_by TEXT;
+-- statuses are ordered by their "strength" - queries pick the MAX status among the duplicated highlights
+ALTER TYPE highlight_status ADD VALUE IF NOT EXISTS 'disputed' BEFORE 'accepted';
+
+-- moderation_events is append-only audit trail of all moderation decisions
+CREATE TABLE IF NOT EXISTS moderation_events
+(
+    event_id          BIGSERIAL        PRIMARY KEY,
+    lint_id           TEXT             NOT NULL,
+    path              TEXT             NOT NULL,
+    start_line        INT              NOT NULL,
+    end_line          INT              NOT NULL,
+    fingerprint       TEXT             NOT NULL,
+    previous_status   highlight_status NOT NULL,
+    moderation_status highlight_status NOT NULL,
+    vote              highlight_status,
+    moderator_login   TEXT             NOT NULL,
+    comment           TEXT             NOT NULL,
+    reverted_event_id BIGINT,
+    created_at        TIMESTAMP        NOT NULL
+);
+CREATE OR REPLACE RULE moderation_events_no_update AS ON UPDATE TO moderation_events DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE moderation_events_no_delete AS ON DELETE TO moderation_events DO INSTEAD NOTHING;
+
+-- moderation_votes holds the latest vote of every moderator for the highlight (identified as in the dashboard stats)
+CREATE TABLE IF NOT EXISTS moderation_votes
+(
+    repo_id         TEXT             NOT NULL,
+    linter_id       TEXT             NOT NULL,
+    highlight_key   TEXT             NOT NULL,
+    moderator_login TEXT             NOT NULL,
+    vote            highlight_status NOT NULL,
+    comment         TEXT             NOT NULL,
+    voted_at        TIMESTAMP        NOT NULL,
+    PRIMARY KEY (repo_id, linter_id, highlight_key, moderator_login)
+);
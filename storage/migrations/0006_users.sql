@@ -0,0 +1,19 @@
+CREATE TYPE access_role AS ENUM ('viewer', 'linter_owner', 'moderator', 'admin');
+CREATE TABLE IF NOT EXISTS users
+(
+    user_login    TEXT UNIQUE NOT NULL,
+    created_at    TIMESTAMP   NOT NULL,
+    last_login_at TIMESTAMP   NOT NULL
+);
+CREATE TABLE IF NOT EXISTS user_roles
+(
+    user_login TEXT        NOT NULL,
+    role       access_role NOT NULL,
+    granted_by TEXT        NOT NULL,
+    granted_at TIMESTAMP   NOT NULL,
+    PRIMARY KEY (user_login, role)
+);
+
+CREATE TYPE linter_status AS ENUM ('pending', 'active', 'paused', 'rejected');
+ALTER TABLE linters ADD COLUMN IF NOT EXISTS linter_status linter_status NOT NULL DEFAULT 'active';
+ALTER TABLE linters ADD COLUMN IF NOT EXISTS linter_owner TEXT;
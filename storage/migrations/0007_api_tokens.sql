@@ -0,0 +1,13 @@
+CREATE TYPE token_scope AS ENUM ('read_only', 'moderate', 'submit_linter');
+CREATE TABLE IF NOT EXISTS api_tokens
+(
+    token_id     BIGSERIAL PRIMARY KEY,
+    user_login   TEXT        NOT NULL,
+    token_name   TEXT        NOT NULL,
+    token_hash   TEXT UNIQUE NOT NULL,
+    token_scope  token_scope NOT NULL,
+    created_at   TIMESTAMP   NOT NULL,
+    last_used_at TIMESTAMP,
+    revoked_at   TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS api_tokens_user_login_idx ON api_tokens (user_login);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS revoked_sessions
+(
+    session_id TEXT PRIMARY KEY,
+    user_login TEXT      NOT NULL,
+    revoked_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,21 @@
+ALTER TYPE lint_status RENAME VALUE 'locked' TO 'running';
+ALTER TYPE lint_status RENAME VALUE 'succeed' TO 'succeeded';
+ALTER TYPE lint_status ADD VALUE IF NOT EXISTS 'timed_out';
+ALTER TYPE lint_status ADD VALUE IF NOT EXISTS 'cancelled';
+
+ALTER TABLE lint_tasks ADD COLUMN IF NOT EXISTS lint_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE lint_tasks ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS lint_attempts
+(
+    attempt_id  BIGSERIAL PRIMARY KEY,
+    lint_id     TEXT      NOT NULL,
+    attempt     INT       NOT NULL,
+    worker_id   TEXT      NOT NULL,
+    started_at  TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+    lint_status lint_status,
+    exit_code   INT,
+    error       TEXT
+);
+CREATE INDEX IF NOT EXISTS lint_attempts_lint_id_idx ON lint_attempts (lint_id);
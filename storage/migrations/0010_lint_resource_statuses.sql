@@ -0,0 +1,2 @@
+ALTER TYPE lint_status ADD VALUE IF NOT EXISTS 'oom_killed';
+ALTER TYPE lint_status ADD VALUE IF NOT EXISTS 'crashed';
@@ -0,0 +1,4 @@
+ALTER TABLE lint_tasks ADD COLUMN IF NOT EXISTS cpu_seconds DOUBLE PRECISION;
+ALTER TABLE lint_tasks ADD COLUMN IF NOT EXISTS max_rss_bytes BIGINT;
+ALTER TABLE lint_tasks ADD COLUMN IF NOT EXISTS io_bytes BIGINT;
+ALTER TABLE lint_tasks ADD COLUMN IF NOT EXISTS max_pids BIGINT;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS lint_logs
+(
+    lint_id      TEXT PRIMARY KEY,
+    attempt      INT       NOT NULL,
+    stdout       BYTEA     NOT NULL, -- gzip compressed, truncated to the worker limit
+    stderr       BYTEA     NOT NULL, -- gzip compressed, truncated to the worker limit
+    stdout_bytes BIGINT    NOT NULL, -- size of the whole stdout stream before truncation
+    stderr_bytes BIGINT    NOT NULL, -- size of the whole stderr stream before truncation
+    created_at   TIMESTAMP NOT NULL
+);
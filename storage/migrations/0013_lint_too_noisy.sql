@@ -0,0 +1 @@
+ALTER TYPE lint_status ADD VALUE IF NOT EXISTS 'too_noisy';
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS user_name TEXT NOT NULL DEFAULT '';
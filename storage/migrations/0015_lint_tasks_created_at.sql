@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS lint_tasks_created_at_idx ON lint_tasks (created_at, lint_id);
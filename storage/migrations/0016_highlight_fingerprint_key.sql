@@ -0,0 +1,23 @@
+-- highlights reported before fingerprints were introduced are keyed by their location (as moderation votes already do),
+-- so every highlight can be identified by (lint_id, fingerprint) - even if several rules are reported on the same lines
+UPDATE lint_highlights as h
+SET fingerprint = legacy.fingerprint
+FROM (SELECT ctid,
+             path || ':' || start_line || ':' || end_line ||
+             CASE
+                 WHEN row_number() OVER location > 1 THEN '-' || row_number() OVER location
+                 ELSE ''
+                 END as fingerprint
+      FROM lint_highlights
+      WHERE fingerprint = ''
+      WINDOW location AS (PARTITION BY lint_id, path, start_line, end_line ORDER BY rule_id, start_column)) as legacy
+WHERE h.ctid = legacy.ctid;
+
+-- moderation_events is append-only: rule is lifted only to attach legacy events to the same key
+DROP RULE IF EXISTS moderation_events_no_update ON moderation_events;
+UPDATE moderation_events
+SET fingerprint = path || ':' || start_line || ':' || end_line
+WHERE fingerprint = '';
+CREATE OR REPLACE RULE moderation_events_no_update AS ON UPDATE TO moderation_events DO INSTEAD NOTHING;
+
+CREATE UNIQUE INDEX IF NOT EXISTS lint_highlights_lint_id_fingerprint ON lint_highlights (lint_id, fingerprint);
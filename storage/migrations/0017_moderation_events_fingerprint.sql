@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS moderation_events_fingerprint_idx ON moderation_events (fingerprint);
@@ -0,0 +1,3 @@
+-- commit of the linter whose image failed to build - manager doesn't rebuild it until new commit appears or linter source is updated
+ALTER TABLE linters ADD COLUMN IF NOT EXISTS linter_failed_git_commit_hash TEXT;
+ALTER TABLE linters ADD COLUMN IF NOT EXISTS linter_build_error TEXT;
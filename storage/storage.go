@@ -28,12 +28,111 @@ func NewPgStorage(ctx context.Context, connectionString string) (*pgxpool.Pool,
 	return pool, nil
 }
 
-func NewPgQueries(ctx context.Context, connectionString string) (*db.Queries, error) {
-	pool, err := NewPgStorage(ctx, connectionString)
+// Queries used by the manager, worker and api server - implemented by Postgres and by in-memory Memory storage for tests
+type Queries interface {
+	ListRepos(ctx context.Context) ([]db.ListReposRow, error)
+	UpsertRepo(ctx context.Context, arg db.UpsertRepoParams) error
+
+	GetLinter(ctx context.Context, linterID string) (db.GetLinterRow, error)
+	ListLinters(ctx context.Context) ([]db.ListLintersRow, error)
+	UpsertLinter(ctx context.Context, arg db.UpsertLinterParams) error
+	SubmitLinter(ctx context.Context, arg db.SubmitLinterParams) error
+	SetLinterStatus(ctx context.Context, arg db.SetLinterStatusParams) error
+	SetLinterBuildFailure(ctx context.Context, arg db.SetLinterBuildFailureParams) error
+	UpdateLinterSource(ctx context.Context, arg db.UpdateLinterSourceParams) error
+
+	AddLintTask(ctx context.Context, arg db.AddLintTaskParams) error
+	TryTakeLintTask(ctx context.Context, arg db.TryTakeLintTaskParams) (db.TryTakeLintTaskRow, error)
+	HeartbeatLintTask(ctx context.Context, arg db.HeartbeatLintTaskParams) (int64, error)
+	SetLintTask(ctx context.Context, arg db.SetLintTaskParams) (int64, error)
+	CancelLinterLintTasks(ctx context.Context, arg db.CancelLinterLintTasksParams) (int64, error)
+	ResumeLinterLintTasks(ctx context.Context, linterID string) (int64, error)
+
+	AddLintAttempt(ctx context.Context, arg db.AddLintAttemptParams) (int64, error)
+	FinishLintAttempt(ctx context.Context, arg db.FinishLintAttemptParams) (int64, error)
+	AbandonLintAttempts(ctx context.Context, arg db.AbandonLintAttemptsParams) (int64, error)
+	ListLintAttempts(ctx context.Context, lintIds []string) ([]db.LintAttempt, error)
+
+	SetLintLogs(ctx context.Context, arg db.SetLintLogsParams) error
+	GetLintLogs(ctx context.Context, lintID string) (db.GetLintLogsRow, error)
+
+	AddLintHighlights(ctx context.Context, arg []db.AddLintHighlightParams) error
+	DeleteLintHighlights(ctx context.Context, lintID string) error
+	InheritLintHighlightsModeration(ctx context.Context, lintID string) (int64, error)
+	TrackLintHighlights(ctx context.Context, lintID string) (int64, error)
+	FixLintHighlights(ctx context.Context, lintID string) (int64, error)
+
+	ListBugHuntRepos(ctx context.Context, arg db.ListBugHuntReposParams) ([]db.ListBugHuntReposRow, error)
+	ListBugHuntLinters(ctx context.Context, arg db.ListBugHuntLintersParams) ([]db.ListBugHuntLintersRow, error)
+	ListBugHuntLinterRules(ctx context.Context) ([]db.ListBugHuntLinterRulesRow, error)
+	ListBugHuntLinterUsage(ctx context.Context) ([]db.ListBugHuntLinterUsageRow, error)
+	ListBugHuntLintTasks(ctx context.Context, arg db.ListBugHuntLintTasksParams) ([]db.ListBugHuntLintTasksRow, error)
+	ListBugHuntHighlights(ctx context.Context, arg db.ListBugHuntHighlightsParams) ([]db.ListBugHuntHighlightsRow, error)
+	ModerateBugHuntHighlight(ctx context.Context, arg db.ModerateBugHuntHighlightParams) error
+
+	GetModerationStatus(ctx context.Context, arg db.GetModerationStatusParams) (db.HighlightStatus, error)
+	AddModerationEvent(ctx context.Context, arg db.AddModerationEventParams) (int64, error)
+	GetModerationEvent(ctx context.Context, eventID int64) (db.ModerationEvent, error)
+	ListModerationEvents(ctx context.Context, arg db.ListModerationEventsParams) ([]db.ListModerationEventsRow, error)
+	UpsertModerationVote(ctx context.Context, arg db.UpsertModerationVoteParams) (int64, error)
+	DeleteModerationVote(ctx context.Context, arg db.DeleteModerationVoteParams) error
+	CountModerationVotes(ctx context.Context, arg db.CountModerationVotesParams) (db.CountModerationVotesRow, error)
+
+	AddUser(ctx context.Context, arg db.AddUserParams) error
+	UpsertUser(ctx context.Context, arg db.UpsertUserParams) error
+	ListUsers(ctx context.Context) ([]db.ListUsersRow, error)
+	ListUserRoles(ctx context.Context, userLogin string) ([]db.AccessRole, error)
+	GrantUserRole(ctx context.Context, arg db.GrantUserRoleParams) error
+	RevokeUserRole(ctx context.Context, arg db.RevokeUserRoleParams) error
+
+	CreateApiToken(ctx context.Context, arg db.CreateApiTokenParams) (int64, error)
+	GetActiveApiToken(ctx context.Context, tokenHash string) (db.GetActiveApiTokenRow, error)
+	ListApiTokens(ctx context.Context, userLogin string) ([]db.ListApiTokensRow, error)
+	TouchApiToken(ctx context.Context, arg db.TouchApiTokenParams) error
+	RevokeApiToken(ctx context.Context, arg db.RevokeApiTokenParams) (int64, error)
+
+	RevokeSession(ctx context.Context, arg db.RevokeSessionParams) error
+	IsSessionRevoked(ctx context.Context, sessionID string) (bool, error)
+	DeleteExpiredRevokedSessions(ctx context.Context, expiresAt pgtype.Timestamp) (int64, error)
+}
+
+type Storage interface {
+	Queries
+	// InTx runs queries inside single transaction which is committed only if run succeeded
+	InTx(ctx context.Context, run func(queries Queries) error) error
+}
+
+// Postgres is the Storage backed by sqlc-generated queries
+type Postgres struct {
+	*db.Queries
+	pool *pgxpool.Pool
+}
+
+func NewPostgres(pool *pgxpool.Pool) Postgres {
+	return Postgres{Queries: db.New(pool), pool: pool}
+}
+
+func (p Postgres) AddLintHighlights(ctx context.Context, arg []db.AddLintHighlightParams) error {
+	var errs []error
+	p.AddLintHighlight(ctx, arg).Exec(func(i int, err error) { errs = append(errs, err) })
+	return errors.Join(errs...)
+}
+
+func (p Postgres) InTx(ctx context.Context, run func(queries Queries) error) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }() // no-op if transaction was committed
+	err = run(Postgres{Queries: p.WithTx(tx), pool: p.pool})
+	if err != nil {
+		return err
+	}
+	err = tx.Commit(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create pg storage for queries: %w", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
-	return db.New(pool), nil
+	return nil
 }
 
 func ViolatesUniqueConstraint(err error) bool {
@@ -51,6 +150,20 @@ func TryGetText(text pgtype.Text) *string {
 	return &text.String
 }
 
+func TryGetInt4(value pgtype.Int4) *int32 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int32
+}
+
+func TryGetInt8(value pgtype.Int8) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int64
+}
+
 func TryGetDurationSec(duration pgtype.Interval) *float64 {
 	if !duration.Valid {
 		return nil